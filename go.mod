@@ -15,11 +15,13 @@ require (
 	github.com/labstack/echo/v4 v4.12.0
 	github.com/lib/pq v1.10.9
 	github.com/logrusorgru/aurora v2.0.3+incompatible
+	github.com/miekg/dns v1.1.59
 	github.com/nguyenthenguyen/docx v0.0.0-20230621112118-9c8e795a11db
 	github.com/panjf2000/ants/v2 v2.9.1
 	github.com/pkg/errors v0.9.1
 	github.com/projectdiscovery/goflags v0.1.63
 	github.com/projectdiscovery/gologger v1.1.19
+	github.com/projectdiscovery/interactsh v1.2.0
 	github.com/projectdiscovery/nuclei/v3 v3.3.1
 	github.com/projectdiscovery/ratelimit v0.0.49
 	github.com/projectdiscovery/useragent v0.0.65
@@ -38,6 +40,7 @@ require (
 	github.com/wcharczuk/go-chart/v2 v2.1.1
 	github.com/xuri/excelize/v2 v2.8.1
 	go.etcd.io/bbolt v1.3.10
+	golang.org/x/net v0.27.0
 	golang.org/x/sync v0.8.0
 	golang.org/x/sys v0.23.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -198,7 +201,6 @@ require (
 	github.com/mholt/archiver/v3 v3.5.1 // indirect
 	github.com/microcosm-cc/bluemonday v1.0.26 // indirect
 	github.com/microsoft/go-mssqldb v1.6.0 // indirect
-	github.com/miekg/dns v1.1.59 // indirect
 	github.com/minio/selfupdate v0.6.1-0.20230907112617-f11e74f84ca7 // indirect
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
@@ -228,7 +230,6 @@ require (
 	github.com/projectdiscovery/gozero v0.0.2 // indirect
 	github.com/projectdiscovery/hmap v0.0.54 // indirect
 	github.com/projectdiscovery/httpx v1.6.7 // indirect
-	github.com/projectdiscovery/interactsh v1.2.0 // indirect
 	github.com/projectdiscovery/ldapserver v1.0.2-0.20240219154113-dcc758ebc0cb // indirect
 	github.com/projectdiscovery/machineid v0.0.0-20240226150047-2e2c51e35983 // indirect
 	github.com/projectdiscovery/mapcidr v1.1.34 // indirect
@@ -302,7 +303,6 @@ require (
 	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
 	golang.org/x/image v0.18.0 // indirect
 	golang.org/x/mod v0.18.0 // indirect
-	golang.org/x/net v0.27.0 // indirect
 	golang.org/x/oauth2 v0.18.0 // indirect
 	golang.org/x/term v0.22.0 // indirect
 	golang.org/x/text v0.17.0 // indirect
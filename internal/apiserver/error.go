@@ -20,16 +20,25 @@ type status struct {
 const (
 	CodePlanNotFound        = 1000
 	CodePlanResultsNotFound = 1001
+	CodePlanNotRunning      = 1002
+	CodePlanNotPaused       = 1003
+	CodePlanNoRunningStage  = 1004
 )
 
 var errMsg = map[int]string{
 	CodePlanNotFound:        "plan not found",
 	CodePlanResultsNotFound: "plan results not found",
+	CodePlanNotRunning:      "plan not running",
+	CodePlanNotPaused:       "plan not paused",
+	CodePlanNoRunningStage:  "plan has no running stage",
 }
 
 var (
 	ErrPlanNotFound        = NewNotFoundError(Status(CodePlanNotFound))
 	ErrPlanResultsNotFound = NewNotFoundError(Status(CodePlanResultsNotFound))
+	ErrPlanNotRunning      = NewConflictError(Status(CodePlanNotRunning))
+	ErrPlanNotPaused       = NewConflictError(Status(CodePlanNotPaused))
+	ErrPlanNoRunningStage  = NewConflictError(Status(CodePlanNoRunningStage))
 )
 
 func Status(code int, message ...string) *status {
@@ -23,6 +23,10 @@ func RegisterRoutes(e *echo.Echo, release ...bool) {
 	v1Group.POST("/plan", Handle(planService.Create))
 	v1Group.PUT("/plan/:plan_id", Handle(planService.Restart))
 	v1Group.DELETE("/plan/:plan_id", Handle(planService.Stop))
+	v1Group.POST("/plan/:plan_id/pause", Handle(planService.Pause))
+	v1Group.POST("/plan/:plan_id/resume", Handle(planService.Resume))
+	v1Group.POST("/plan/:plan_id/adjust", Handle(planService.Adjust))
+	v1Group.GET("/plan/:plan_id/events", planService.Events)
 	v1Group.GET("/plan/:plan_id/results", Handle(planService.GetResults))
 	v1Group.GET("/plan/running", Handle(planService.RunningPlans))
 	v1Group.GET("/plan/stopped", Handle(planService.StoppedPlans))
@@ -2,11 +2,19 @@ package apiserver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 
 	eagleeye "github.com/EscapeBearSecond/falcon/pkg/sdk"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/labstack/echo/v4"
 )
 
+// eventBuffer 计划事件订阅缓冲大小，已满时丢弃事件，客户端读取缓慢不影响扫描
+const eventBuffer = 256
+
 type PlanService struct{}
 
 // @Summary 创建计划
@@ -31,6 +39,7 @@ func (s *PlanService) Create(ctx context.Context, request *CreatePlanRequest) (*
 	}
 
 	results.PlanID = entry.EntryID
+	results.Seed = entry.Seed()
 
 	err = DB.StorePlan(entry.EntryID, request)
 	if err != nil {
@@ -56,6 +65,8 @@ func (s *PlanService) Create(ctx context.Context, request *CreatePlanRequest) (*
 // @Router /plan/{plan_id} [post]
 func (s *PlanService) Restart(ctx context.Context, request *RestartPlanRequest) (*RestartPlanReplay, error) {
 	newEntry := Eagleeye.Entry(request.PlanID)
+	// 未运行(如服务重启后)且存在断点时继续执行，否则重新执行
+	resume := newEntry == nil && Eagleeye.Resumable(request.PlanID)
 	if newEntry != nil {
 		newEntry.Stop()
 	}
@@ -71,11 +82,17 @@ func (s *PlanService) Restart(ctx context.Context, request *RestartPlanRequest)
 
 	s.setCallback(plan, results)
 
-	newEntry, err = Eagleeye.NewEntry((*types.Options)(plan))
+	var extraOpts []eagleeye.ExtraOption
+	if resume {
+		extraOpts = append(extraOpts, eagleeye.WithResume(request.PlanID))
+	}
+	newEntry, err = Eagleeye.NewEntry((*types.Options)(plan), extraOpts...)
 	if err != nil {
 		return nil, WithCaller(err)
 	}
 
+	results.Seed = newEntry.Seed()
+
 	err = DB.RestorePlan(request.PlanID, newEntry.EntryID, plan)
 	if err != nil {
 		return nil, WithCaller(err)
@@ -111,10 +128,132 @@ func (s *PlanService) Stop(ctx context.Context, request *StopPlanRequest) (*Stop
 	if err != nil {
 		return nil, WithCaller(err)
 	}
+	// 未运行的计划(如服务重启后)保留了断点目录，删除计划后不再继续执行
+	if newEntry == nil {
+		Eagleeye.RemoveFiles(request.PlanID)
+	}
 
 	return &StopPlanReplay{PlanID: request.PlanID}, nil
 }
 
+// @Summary 暂停计划
+// @Description 暂停计划
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param plan_id path string true "计划ID"
+// @Success 200 {object} PausePlanReplay
+// @Failure 409 {object} status
+// @Router /plan/{plan_id}/pause [post]
+func (s *PlanService) Pause(ctx context.Context, request *PausePlanRequest) (*PausePlanReplay, error) {
+	entry := Eagleeye.Entry(request.PlanID)
+	if entry == nil {
+		return nil, WithCaller(ErrPlanNotRunning)
+	}
+
+	if err := entry.Pause(); err != nil {
+		return nil, WithCaller(ErrPlanNotRunning.WithCause(err))
+	}
+
+	return &PausePlanReplay{PlanID: request.PlanID}, nil
+}
+
+// @Summary 继续计划
+// @Description 继续已暂停的计划
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param plan_id path string true "计划ID"
+// @Success 200 {object} ResumePlanReplay
+// @Failure 409 {object} status
+// @Router /plan/{plan_id}/resume [post]
+func (s *PlanService) Resume(ctx context.Context, request *ResumePlanRequest) (*ResumePlanReplay, error) {
+	entry := Eagleeye.Entry(request.PlanID)
+	if entry == nil {
+		return nil, WithCaller(ErrPlanNotPaused)
+	}
+
+	if err := entry.Resume(); err != nil {
+		return nil, WithCaller(ErrPlanNotPaused.WithCause(err))
+	}
+
+	return &ResumePlanReplay{PlanID: request.PlanID}, nil
+}
+
+// @Summary 调整计划
+// @Description 调整运行中计划指定阶段(任务按序号区分)的速率与并发数，各阶段速率单位不同，仅调整指定阶段
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Param plan_id path string true "计划ID"
+// @Param adjustment body AdjustPlanRequest true "阶段、速率与并发数"
+// @Success 200 {object} AdjustPlanReplay
+// @Failure 400 {object} status
+// @Failure 409 {object} status
+// @Router /plan/{plan_id}/adjust [post]
+func (s *PlanService) Adjust(ctx context.Context, request *AdjustPlanRequest) (*AdjustPlanReplay, error) {
+	entry := Eagleeye.Entry(request.PlanID)
+	if entry == nil {
+		return nil, WithCaller(ErrPlanNotRunning)
+	}
+
+	err := entry.Adjust(types.StageName(request.Stage), request.Job, request.RateLimit, request.Concurrency)
+	switch {
+	case errors.Is(err, types.ErrInvalidAdjustment):
+		return nil, WithCaller(NewBadRequestErrorM(err.Error(), err))
+	case errors.Is(err, types.ErrNoRunningStage):
+		return nil, WithCaller(ErrPlanNoRunningStage.WithCause(err))
+	case err != nil:
+		return nil, WithCaller(ErrPlanNotRunning.WithCause(err))
+	}
+
+	return &AdjustPlanReplay{PlanID: request.PlanID}, nil
+}
+
+// @Summary 订阅计划事件
+// @Description 以SSE(text/event-stream)推送运行中计划的执行事件(阶段开始与完成、进度、警告、执行失败及命中结果)，计划执行结束后关闭
+// @Tags plans
+// @Produce text/event-stream
+// @Param plan_id path string true "计划ID"
+// @Success 200 {object} types.Event
+// @Failure 409 {object} status
+// @Router /plan/{plan_id}/events [get]
+func (s *PlanService) Events(c echo.Context) error {
+	entry := Eagleeye.Entry(c.Param("plan_id"))
+	if entry == nil {
+		return WithCaller(ErrPlanNotRunning)
+	}
+
+	events, cancel := entry.Subscribe(eventBuffer)
+	defer cancel()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				Logger.Error("Marshal event failed", "plan_id", entry.EntryID, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
+
 // @Summary 获取计划结果
 // @Description 获取计划结果
 // @Tags plans
@@ -168,6 +307,10 @@ func (s *PlanService) runAsync(entry *eagleeye.EagleeyeEntry, results *GetPlanRe
 	if err != nil {
 		Logger.Error("Entry.Run failed", "plan_id", entry.EntryID, "error", err)
 		results.State = 1
+		// 运行中停止或重启的计划已删除，不再继续执行，删除保留的断点目录
+		if _, err := DB.GetPlan(entry.EntryID); err != nil {
+			Eagleeye.RemoveFiles(entry.EntryID)
+		}
 	}
 
 	err = DB.StoreResults(entry.EntryID, results)
@@ -180,6 +323,12 @@ func (s *PlanService) runAsync(entry *eagleeye.EagleeyeEntry, results *GetPlanRe
 }
 
 func (s *PlanService) setCallback(plan *CreatePlanRequest, results *GetPlanResultsReplay) {
+	if plan.DNSResolution.Use {
+		plan.DNSResolution.ResultCallback = func(ctx context.Context, dr *types.DNSResult) error {
+			results.DNSResolutionResult = dr
+			return nil
+		}
+	}
 	if plan.HostDiscovery.Use {
 		plan.HostDiscovery.ResultCallback = func(ctx context.Context, pr *types.PingResult) error {
 			results.HostDiscoveryResult = pr
@@ -192,6 +341,18 @@ func (s *PlanService) setCallback(plan *CreatePlanRequest, results *GetPlanResul
 			return nil
 		}
 	}
+	if plan.Certificate.Use {
+		plan.Certificate.ResultCallback = func(ctx context.Context, cr *types.CertResult) error {
+			results.CertificateResult = cr
+			return nil
+		}
+	}
+	if plan.WebFingerprint.Use {
+		plan.WebFingerprint.ResultCallback = func(ctx context.Context, wr *types.WebResult) error {
+			results.WebFingerprintResult = wr
+			return nil
+		}
+	}
 	for i := range plan.Jobs {
 		plan.Jobs[i].ResultCallback = func(ctx context.Context, jr *types.JobResult) error {
 			results.JobResults = append(results.JobResults, jr)
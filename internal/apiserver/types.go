@@ -28,16 +28,48 @@ type StopPlanReplay struct {
 	PlanID string `json:"plan_id"`
 }
 
+type PausePlanRequest struct {
+	PlanID string `param:"plan_id" valdiate:"required" message:"plan_id is required"`
+}
+
+type PausePlanReplay struct {
+	PlanID string `json:"plan_id"`
+}
+
+type ResumePlanRequest struct {
+	PlanID string `param:"plan_id" valdiate:"required" message:"plan_id is required"`
+}
+
+type ResumePlanReplay struct {
+	PlanID string `json:"plan_id"`
+}
+
+type AdjustPlanRequest struct {
+	PlanID      string `param:"plan_id" valdiate:"required" message:"plan_id is required"`
+	Stage       string `json:"stage"`       //阶段(HostDiscovery,PortScanning,Job等)
+	Job         int    `json:"job"`         //任务序号，阶段为Job时有效
+	RateLimit   int    `json:"rate_limit"`  //速率(每秒)，0表示不调整
+	Concurrency int    `json:"concurrency"` //并发数，0表示不调整
+}
+
+type AdjustPlanReplay struct {
+	PlanID string `json:"plan_id"`
+}
+
 type GetPlanResultsRequest struct {
 	PlanID string `param:"plan_id" valdiate:"required" message:"plan_id is required"`
 }
 
 type GetPlanResultsReplay struct {
-	PlanID              string             `json:"plan_id"`
-	State               byte               `json:"state"` //0:成功 1:失败
-	HostDiscoveryResult *types.PingResult  `json:"host_discovery_result"`
-	PlanScanningResult  *types.PortResult  `json:"plan_scanning_result"`
-	JobResults          []*types.JobResult `json:"job_results"`
+	PlanID               string             `json:"plan_id"`
+	State                byte               `json:"state"` //0:成功 1:失败
+	DNSResolutionResult  *types.DNSResult   `json:"dns_resolution_result"`
+	HostDiscoveryResult  *types.PingResult  `json:"host_discovery_result"`
+	PlanScanningResult   *types.PortResult  `json:"plan_scanning_result"`
+	CertificateResult    *types.CertResult  `json:"certificate_result"`
+	WebFingerprintResult *types.WebResult   `json:"web_fingerprint_result"`
+	JobResults           []*types.JobResult `json:"job_results"`
+	Seed                 int64              `json:"seed"` //扫描顺序随机种子
 }
 
 type RunningPlansReplay struct {
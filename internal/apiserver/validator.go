@@ -1,8 +1,10 @@
 package apiserver
 
 import (
+	"errors"
 	"time"
 
+	"github.com/EscapeBearSecond/falcon/internal/scanner"
 	"github.com/EscapeBearSecond/falcon/internal/util"
 	"github.com/gookit/validate"
 )
@@ -13,12 +15,9 @@ func init() {
 		return err == nil
 	})
 	validate.AddValidator("ports", func(v string) bool {
-		r := validate.Enum(v, []string{"http", "top100", "top1000"})
-		if !r {
-			_, err := util.ParsePortsList(v)
-			return err == nil
-		}
-		return r
+		// 命名集合可能来自请求的自定义端口集合文件(port_sets)，此处仅校验格式，由端口扫描器加载集合后校验名称
+		_, err := util.ParsePortSpec(v, scanner.BuiltinPortSets)
+		return err == nil || errors.Is(err, util.ErrUnknownPortName)
 	})
 }
 
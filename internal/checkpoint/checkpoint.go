@@ -0,0 +1,197 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+)
+
+// Filename 条目目录下的断点文件名
+const Filename = "checkpoint.json"
+
+// 扫描阶段名称(与任务输入来源一致的阶段输出目标)
+const (
+	StageDNSResolution  = "dns_resolution"
+	StageHostDiscovery  = types.JobInputHosts
+	StagePortScanning   = types.JobInputPorts
+	StageCertificate    = "certificate"
+	StageWebFingerprint = "web_fingerprint"
+)
+
+// Checkpoint 执行进度，进程中断后由新的引擎继续执行
+//
+// 记录已完成的扫描阶段及其输出、各任务按执行顺序已完成的 模板×目标 数量与已有结果，
+// 以及sdk已完成阶段的回调结果(继续执行时回放)
+type Checkpoint struct {
+	m sync.Mutex
+
+	Seed           int64                      `json:"seed"`            //扫描顺序随机种子
+	Targets        []string                   `json:"targets"`         //目标
+	ExcludeTargets []string                   `json:"exclude_targets"` //排除目标
+	Stages         map[string]*Stage          `json:"stages"`          //已完成的扫描阶段
+	Jobs           map[int]*Job               `json:"jobs"`            //任务进度(按任务配置顺序)
+	Results        map[string]json.RawMessage `json:"results"`         //已完成阶段的结果
+}
+
+// Stage 已完成扫描阶段的输出
+type Stage struct {
+	Targets   []string            `json:"targets,omitempty"`   //输出目标(域名解析阶段由records重建)
+	Records   map[string][]string `json:"records,omitempty"`   //域名解析记录
+	Hostnames target.Hostnames    `json:"hostnames,omitempty"` //IP对应的域名
+	Services  target.Services     `json:"services,omitempty"`  //端口对应的服务
+}
+
+// Job 任务进度
+type Job struct {
+	Done    bool                   `json:"done"`    //已完成
+	Cursor  uint64                 `json:"cursor"`  //按执行顺序已完成的 模板×目标 数量(流水线模式下为0)
+	Matches []*Match               `json:"matches"` //命中的目标
+	Items   []*types.JobResultItem `json:"items"`   //结果(配置回调时)
+}
+
+// Match 命中模板的目标
+type Match struct {
+	Target     string   `json:"target"`
+	TemplateID string   `json:"template_id"`
+	Tags       []string `json:"tags"`
+}
+
+// New 实例化空的断点
+func New() *Checkpoint {
+	return &Checkpoint{
+		Stages:  make(map[string]*Stage),
+		Jobs:    make(map[int]*Job),
+		Results: make(map[string]json.RawMessage),
+	}
+}
+
+// Load 读取断点文件
+func Load(file string) (*Checkpoint, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint failed: %w", err)
+	}
+
+	cp := New()
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint format: %w", err)
+	}
+	return cp, nil
+}
+
+// Resumed 是否包含之前执行的进度
+func (cp *Checkpoint) Resumed() bool {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+	return cp.Seed != 0
+}
+
+// Save 写入断点文件(先写入临时文件再替换，避免中断时文件不完整)
+func (cp *Checkpoint) Save(file string) error {
+	cp.m.Lock()
+	data, err := json.Marshal(cp)
+	cp.m.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint failed: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("create checkpoint directory failed: %w", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write checkpoint failed: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("write checkpoint failed: %w", err)
+	}
+	return nil
+}
+
+// Remove 删除断点文件(执行完成后)
+func Remove(file string) error {
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Init 记录种子及目标，已包含进度时校验目标是否一致
+func (cp *Checkpoint) Init(seed int64, targets, excludeTargets []string) error {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+
+	if cp.Seed != 0 {
+		if !slices.Equal(cp.Targets, targets) || !slices.Equal(cp.ExcludeTargets, excludeTargets) {
+			return types.ErrCheckpointMismatch
+		}
+		return nil
+	}
+
+	cp.Seed = seed
+	cp.Targets = targets
+	cp.ExcludeTargets = excludeTargets
+	return nil
+}
+
+// Stage 获取已完成的扫描阶段，未完成时返回nil
+func (cp *Checkpoint) Stage(name string) *Stage {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+	return cp.Stages[name]
+}
+
+// SetStage 记录已完成的扫描阶段
+func (cp *Checkpoint) SetStage(name string, stage *Stage) {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+	cp.Stages[name] = stage
+}
+
+// Job 获取任务进度，未执行时返回nil
+func (cp *Checkpoint) Job(index int) *Job {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+	return cp.Jobs[index]
+}
+
+// SetJob 记录任务进度
+func (cp *Checkpoint) SetJob(index int, job *Job) {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+	cp.Jobs[index] = job
+}
+
+// Result 获取已完成阶段的结果，不存在时返回false
+func (cp *Checkpoint) Result(name string, v any) (bool, error) {
+	cp.m.Lock()
+	data, ok := cp.Results[name]
+	cp.m.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("invalid checkpoint result [%s]: %w", name, err)
+	}
+	return true, nil
+}
+
+// SetResult 记录阶段的结果
+func (cp *Checkpoint) SetResult(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint result [%s] failed: %w", name, err)
+	}
+
+	cp.m.Lock()
+	defer cp.m.Unlock()
+	cp.Results[name] = data
+	return nil
+}
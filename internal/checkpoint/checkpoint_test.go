@@ -0,0 +1,56 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "entry", Filename)
+
+	cp := New()
+	assert.False(cp.Resumed())
+	assert.NoError(cp.Init(42, []string{"192.168.1.0/24"}, nil))
+	cp.SetStage(StagePortScanning, &Stage{
+		Targets:  []string{"192.168.1.1:80"},
+		Services: target.Services{"192.168.1.1:80": {Name: "http"}},
+	})
+	cp.SetJob(0, &Job{Cursor: 10, Items: []*types.JobResultItem{{TemplateID: "t1", EntryID: "entry"}}})
+	assert.NoError(cp.SetResult(StagePortScanning, &types.PortResult{Items: []*types.PortResultItem{{HostPort: "192.168.1.1:80"}}}))
+	assert.NoError(cp.Save(file))
+
+	loaded, err := Load(file)
+	assert.NoError(err)
+	assert.True(loaded.Resumed())
+	assert.Equal(int64(42), loaded.Seed)
+	assert.Equal([]string{"192.168.1.1:80"}, loaded.Stage(StagePortScanning).Targets)
+	assert.Equal("http", loaded.Stage(StagePortScanning).Services["192.168.1.1:80"].Name)
+	assert.Nil(loaded.Stage(StageHostDiscovery))
+	assert.Equal(uint64(10), loaded.Job(0).Cursor)
+	assert.Nil(loaded.Job(1))
+
+	var result types.PortResult
+	ok, err := loaded.Result(StagePortScanning, &result)
+	assert.NoError(err)
+	assert.True(ok)
+	assert.Equal("192.168.1.1:80", result.Items[0].HostPort)
+	ok, err = loaded.Result(StageCertificate, &result)
+	assert.NoError(err)
+	assert.False(ok)
+
+	// 继续执行时目标需一致，种子不变
+	assert.ErrorIs(loaded.Init(7, []string{"192.168.2.0/24"}, nil), types.ErrCheckpointMismatch)
+	assert.NoError(loaded.Init(7, []string{"192.168.1.0/24"}, nil))
+	assert.Equal(int64(42), loaded.Seed)
+
+	assert.NoError(Remove(file))
+	assert.NoError(Remove(file))
+	_, err = Load(file)
+	assert.Error(err)
+}
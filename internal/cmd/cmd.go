@@ -3,11 +3,15 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
 
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
 	"github.com/EscapeBearSecond/falcon/internal/engine"
 	"github.com/EscapeBearSecond/falcon/internal/flag"
 	"github.com/EscapeBearSecond/falcon/internal/global"
@@ -16,13 +20,16 @@ import (
 	"github.com/EscapeBearSecond/falcon/internal/meta"
 	"github.com/EscapeBearSecond/falcon/internal/monitor"
 	"github.com/EscapeBearSecond/falcon/internal/scanner"
+	"github.com/EscapeBearSecond/falcon/internal/stage"
 	"github.com/EscapeBearSecond/falcon/internal/util/log"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/rs/xid"
 	"github.com/spf13/cobra"
 )
 
 var (
 	cfgFile string //配置文件
+	resume  string //继续执行的断点ID
 
 	o types.Options //配置对象
 )
@@ -57,23 +64,61 @@ var rootCmd = cobra.Command{
 		}
 		defer global.Release()
 
+		// 各阶段进度条由执行事件渲染(按百分比)，扫描器与任务仅输出日志
+		stageManager := stage.NewManager()
+		stopProgress := renderProgress(stageManager, log.Must(log.NewLogger(log.WithStdout())))
+		defer stopProgress()
+
 		options := []engine.Option{
+			engine.WithStageManager(stageManager),
 			engine.WithTargets(o.Targets),
+			engine.WithSeed(o.Seed),
+			engine.WithPipeline(o.Pipeline),
+			engine.WithInteractsh(o.Interactsh),
 		}
 
 		if len(o.ExcludeTargets) > 0 {
 			options = append(options, engine.WithExcludeTargets(o.ExcludeTargets))
 		}
 
+		// 开启断点续扫时进度保存至<ID>/checkpoint.json，中断后通过--resume <ID>继续执行
+		if o.Checkpoint.Use || resume != "" {
+			id := resume
+			cp := checkpoint.New()
+			if id == "" {
+				id = xid.New().String()
+			} else {
+				var err error
+				cp, err = checkpoint.Load(filepath.Join(id, checkpoint.Filename))
+				if err != nil {
+					return err
+				}
+			}
+			interval, err := time.ParseDuration(o.Checkpoint.Interval)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("%w: invalid interval %q", types.ErrInvalidCheckpoint, o.Checkpoint.Interval)
+			}
+			options = append(options, engine.WithCheckpoint(cp, filepath.Join(id, checkpoint.Filename), interval))
+			fmt.Printf("checkpoint: %s\n", id)
+		}
+
 		if o.PortScanning.Use {
 			portScanner, err := scanner.NewPortScannerV3(&scanner.PortScannerConfig{
-				Ports:       o.PortScanning.Ports,
-				Timeout:     o.PortScanning.Timeout,
-				Count:       o.PortScanning.Count,
-				Format:      o.PortScanning.Format,
-				RateLimit:   o.PortScanning.RateLimit,
-				Concurrency: o.PortScanning.Concurrency,
-				Directory:   ".",
+				Ports:            o.PortScanning.Ports,
+				ExcludePorts:     o.PortScanning.ExcludePorts,
+				PortSets:         o.PortScanning.PortSets,
+				Mode:             o.PortScanning.Mode,
+				ServiceDetection: o.PortScanning.ServiceDetection,
+				Timeout:          o.PortScanning.Timeout,
+				Count:            o.PortScanning.Count,
+				Format:           o.PortScanning.Format,
+				RateLimit:        o.PortScanning.RateLimit,
+				HostRateLimit:    o.PortScanning.HostRateLimit,
+				Adaptive:         o.PortScanning.Adaptive,
+				Concurrency:      o.PortScanning.Concurrency,
+				Directory:        ".",
+				NoProgressbar:    true,
+				StageManager:     stageManager,
 			})
 			if err != nil {
 				return err
@@ -81,14 +126,37 @@ var rootCmd = cobra.Command{
 			options = append(options, engine.WithPortScanner(portScanner))
 		}
 
+		if o.DNSResolution.Use {
+			dnsResolver, err := scanner.NewDNSResolver(&scanner.DNSResolverConfig{
+				Resolvers:     o.DNSResolution.Resolvers,
+				Timeout:       o.DNSResolution.Timeout,
+				Count:         o.DNSResolution.Count,
+				Format:        o.DNSResolution.Format,
+				RateLimit:     o.DNSResolution.RateLimit,
+				Concurrency:   o.DNSResolution.Concurrency,
+				Directory:     ".",
+				NoProgressbar: true,
+				StageManager:  stageManager,
+			})
+			if err != nil {
+				return err
+			}
+			options = append(options, engine.WithDNSResolver(dnsResolver))
+		}
+
 		if o.HostDiscovery.Use {
 			hostDiscoverer, err := scanner.NewHostDiscoverer(&scanner.HostDiscovererConfig{
-				Timeout:     o.HostDiscovery.Timeout,
-				Count:       o.HostDiscovery.Count,
-				Format:      o.HostDiscovery.Format,
-				RateLimit:   o.HostDiscovery.RateLimit,
-				Concurrency: o.HostDiscovery.Concurrency,
-				Directory:   ".",
+				Methods:       o.HostDiscovery.Methods,
+				TCPPorts:      o.HostDiscovery.TCPPorts,
+				UDPPorts:      o.HostDiscovery.UDPPorts,
+				Timeout:       o.HostDiscovery.Timeout,
+				Count:         o.HostDiscovery.Count,
+				Format:        o.HostDiscovery.Format,
+				RateLimit:     o.HostDiscovery.RateLimit,
+				Concurrency:   o.HostDiscovery.Concurrency,
+				Directory:     ".",
+				NoProgressbar: true,
+				StageManager:  stageManager,
 			})
 			if err != nil {
 				return err
@@ -96,6 +164,23 @@ var rootCmd = cobra.Command{
 			options = append(options, engine.WithHostDiscoverer(hostDiscoverer))
 		}
 
+		if o.Certificate.Use {
+			certCollector, err := scanner.NewCertCollector(&scanner.CertCollectorConfig{
+				Timeout:       o.Certificate.Timeout,
+				Count:         o.Certificate.Count,
+				Format:        o.Certificate.Format,
+				RateLimit:     o.Certificate.RateLimit,
+				Concurrency:   o.Certificate.Concurrency,
+				Directory:     ".",
+				NoProgressbar: true,
+				StageManager:  stageManager,
+			})
+			if err != nil {
+				return err
+			}
+			options = append(options, engine.WithCertCollector(certCollector))
+		}
+
 		var logger *slog.Logger
 		if o.OutLog {
 			var err error
@@ -109,6 +194,26 @@ var rootCmd = cobra.Command{
 		if err != nil {
 			return err
 		}
+
+		if o.WebFingerprint.Use {
+			webFingerprinter, err := scanner.NewWebFingerprinter(&scanner.WebFingerprinterConfig{
+				Timeout:       o.WebFingerprint.Timeout,
+				Count:         o.WebFingerprint.Count,
+				Format:        o.WebFingerprint.Format,
+				RateLimit:     o.WebFingerprint.RateLimit,
+				Concurrency:   o.WebFingerprint.Concurrency,
+				Fingerprints:  o.WebFingerprint.Fingerprints,
+				VulnMapper:    vm,
+				Directory:     ".",
+				NoProgressbar: true,
+				StageManager:  stageManager,
+			})
+			if err != nil {
+				return err
+			}
+			options = append(options, engine.WithWebFingerprinter(webFingerprinter))
+		}
+
 		for i, j := range o.Jobs {
 			newJob, err := job.NewJob(
 				job.WithIndex(i),
@@ -122,7 +227,12 @@ var rootCmd = cobra.Command{
 				job.WithRetries(j.Count),
 				job.WithEnableHeadless(j.Headless),
 				job.WithVulnMapper(vm),
+				job.WithInput(j.Input),
+				job.WithChain(j.Chain),
+				job.WithFilter(j.Filter),
 				job.WithDirectory("."),
+				job.WithNoProgressbar(true),
+				job.WithStageManager(stageManager),
 			)
 			if err != nil {
 				return err
@@ -170,8 +280,17 @@ func init() {
 	rootCmd.Flags().StringVar(&cfgFile, "cfg", "", "config file")
 
 	{
-		rootCmd.Flags().StringSliceVarP(&o.Targets, "targets", "u", nil, "目标地址/文件")
-		rootCmd.Flags().StringSliceVar(&o.ExcludeTargets, "ue", nil, "排除目标地址/文件")
+		rootCmd.Flags().StringSliceVarP(&o.Targets, "targets", "u", nil, "目标地址/文件(@前缀强制作为文件)")
+		rootCmd.Flags().StringSliceVar(&o.ExcludeTargets, "ue", nil, "排除目标地址/文件(@前缀强制作为文件)")
+		rootCmd.Flags().Int64Var(&o.Seed, "seed", 0, "扫描顺序随机种子")
+		rootCmd.Flags().BoolVar(&o.Pipeline, "pipeline", false, "流水线模式(在线检测、端口扫描与任务同时执行)")
+	}
+
+	//断点续扫
+	{
+		rootCmd.Flags().BoolVar(&o.Checkpoint.Use, "checkpoint", false, "断点续扫(定期保存执行进度)")
+		rootCmd.Flags().StringVar(&o.Checkpoint.Interval, "ki", defaultOptions.Checkpoint.Interval, "断点保存间隔")
+		rootCmd.Flags().StringVar(&resume, "resume", "", "由断点ID继续执行中断的扫描(需使用相同的配置)")
 	}
 
 	rootCmd.Flags().BoolVarP(&o.OutLog, "out_log", "l", false, "任务执行日志")
@@ -187,9 +306,23 @@ func init() {
 		// rootCmd.Flags().IntVar(&cfg.Monitor.EtherNum, "me", 0, "网卡编号")
 	}
 
+	//域名解析
+	{
+		rootCmd.Flags().BoolVarP(&o.DNSResolution.Use, "resolve", "r", false, "域名解析")
+		rootCmd.Flags().StringSliceVar(&o.DNSResolution.Resolvers, "rs", nil, "域名解析DNS服务器")
+		rootCmd.Flags().StringVar(&o.DNSResolution.Timeout, "re", defaultOptions.DNSResolution.Timeout, "域名解析超时时间")
+		rootCmd.Flags().IntVar(&o.DNSResolution.Count, "rn", defaultOptions.DNSResolution.Count, "域名解析轮次")
+		rootCmd.Flags().StringVar(&o.DNSResolution.Format, "ra", defaultOptions.DNSResolution.Format, "域名解析输出格式")
+		rootCmd.Flags().IntVar(&o.DNSResolution.RateLimit, "rr", defaultOptions.DNSResolution.RateLimit, "域名解析频率")
+		rootCmd.Flags().IntVar(&o.DNSResolution.Concurrency, "rc", defaultOptions.DNSResolution.Concurrency, "域名解析并发数")
+	}
+
 	//设备在线监测
 	{
 		rootCmd.Flags().BoolVarP(&o.HostDiscovery.Use, "discovery", "d", false, "设备探活")
+		rootCmd.Flags().StringSliceVar(&o.HostDiscovery.Methods, "dm", nil, "探活方式(icmp,tcp,syn,arp,udp)")
+		rootCmd.Flags().StringVar(&o.HostDiscovery.TCPPorts, "dt", defaultOptions.HostDiscovery.TCPPorts, "探活TCP端口")
+		rootCmd.Flags().StringVar(&o.HostDiscovery.UDPPorts, "du", defaultOptions.HostDiscovery.UDPPorts, "探活UDP端口")
 		rootCmd.Flags().StringVar(&o.HostDiscovery.Timeout, "de", defaultOptions.HostDiscovery.Timeout, "探活超时时间")
 		rootCmd.Flags().IntVar(&o.HostDiscovery.Count, "dn", defaultOptions.HostDiscovery.Count, "探活轮次")
 		rootCmd.Flags().StringVar(&o.HostDiscovery.Format, "da", defaultOptions.HostDiscovery.Format, "探活输出格式")
@@ -204,10 +337,51 @@ func init() {
 		rootCmd.Flags().IntVar(&o.PortScanning.Count, "pn", defaultOptions.PortScanning.Count, "端口扫描轮次")
 		rootCmd.Flags().StringVar(&o.PortScanning.Format, "pa", defaultOptions.PortScanning.Format, "端口扫描输出格式")
 		rootCmd.Flags().StringVar(&o.PortScanning.Ports, "pp", defaultOptions.PortScanning.Ports, "端口扫描端口")
+		rootCmd.Flags().StringVar(&o.PortScanning.ExcludePorts, "px", "", "端口扫描排除端口")
+		rootCmd.Flags().StringVar(&o.PortScanning.PortSets, "pf", "", "端口扫描自定义端口集合文件")
+		rootCmd.Flags().StringVar(&o.PortScanning.Mode, "pm", defaultOptions.PortScanning.Mode, "端口扫描方式(connect,syn)")
+		rootCmd.Flags().BoolVar(&o.PortScanning.ServiceDetection, "ps", false, "端口服务识别")
 		rootCmd.Flags().IntVar(&o.PortScanning.RateLimit, "pr", defaultOptions.PortScanning.RateLimit, "端口扫描频率")
+		rootCmd.Flags().IntVar(&o.PortScanning.HostRateLimit, "ph", 0, "端口扫描单主机最大频率")
+		rootCmd.Flags().BoolVar(&o.PortScanning.Adaptive, "pd", false, "端口扫描自适应限速")
 		rootCmd.Flags().IntVar(&o.PortScanning.Concurrency, "pc", defaultOptions.PortScanning.Concurrency, "端口扫描并发数")
 	}
 
+	//证书采集
+	{
+		rootCmd.Flags().BoolVar(&o.Certificate.Use, "cert", false, "TLS证书采集")
+		rootCmd.Flags().StringVar(&o.Certificate.Timeout, "ce", defaultOptions.Certificate.Timeout, "证书采集超时时间")
+		rootCmd.Flags().IntVar(&o.Certificate.Count, "cn", defaultOptions.Certificate.Count, "证书采集轮次")
+		rootCmd.Flags().StringVar(&o.Certificate.Format, "ca", defaultOptions.Certificate.Format, "证书采集输出格式")
+		rootCmd.Flags().IntVar(&o.Certificate.RateLimit, "cr", defaultOptions.Certificate.RateLimit, "证书采集频率")
+		rootCmd.Flags().IntVar(&o.Certificate.Concurrency, "cc", defaultOptions.Certificate.Concurrency, "证书采集并发数")
+	}
+
+	//Web指纹识别
+	{
+		rootCmd.Flags().BoolVar(&o.WebFingerprint.Use, "web", false, "Web指纹识别")
+		rootCmd.Flags().StringVar(&o.WebFingerprint.Fingerprints, "wf", "", "Web指纹库文件")
+		rootCmd.Flags().StringVar(&o.WebFingerprint.Timeout, "we", defaultOptions.WebFingerprint.Timeout, "Web指纹识别超时时间")
+		rootCmd.Flags().IntVar(&o.WebFingerprint.Count, "wn", defaultOptions.WebFingerprint.Count, "Web指纹识别轮次")
+		rootCmd.Flags().StringVar(&o.WebFingerprint.Format, "wa", defaultOptions.WebFingerprint.Format, "Web指纹识别输出格式")
+		rootCmd.Flags().IntVar(&o.WebFingerprint.RateLimit, "wr", defaultOptions.WebFingerprint.RateLimit, "Web指纹识别频率")
+		rootCmd.Flags().IntVar(&o.WebFingerprint.Concurrency, "wc", defaultOptions.WebFingerprint.Concurrency, "Web指纹识别并发数")
+	}
+
+	//带外交互
+	{
+		rootCmd.Flags().BoolVar(&o.Interactsh.Use, "oob", false, "带外交互(OOB)")
+		rootCmd.Flags().StringVar(&o.Interactsh.Server, "os", "", "交互服务地址(为空时启动内置服务)")
+		rootCmd.Flags().StringVar(&o.Interactsh.Token, "ot", "", "交互服务认证令牌")
+		rootCmd.Flags().StringVar(&o.Interactsh.PollInterval, "op", defaultOptions.Interactsh.PollInterval, "交互轮询间隔")
+		rootCmd.Flags().StringVar(&o.Interactsh.Cooldown, "oc", defaultOptions.Interactsh.Cooldown, "任务结束后等待交互时间")
+		rootCmd.Flags().StringVar(&o.Interactsh.Eviction, "ov", defaultOptions.Interactsh.Eviction, "请求等待交互最长时间")
+		rootCmd.Flags().StringVar(&o.Interactsh.Listen, "ol", defaultOptions.Interactsh.Listen, "内置交互服务HTTP监听地址")
+		rootCmd.Flags().StringVar(&o.Interactsh.DNSListen, "on", "", "内置交互服务DNS监听地址")
+		rootCmd.Flags().StringVar(&o.Interactsh.Domain, "oh", "", "内置交互服务交互域名")
+		rootCmd.Flags().StringVar(&o.Interactsh.IP, "oi", "", "内置交互服务DNS应答地址")
+	}
+
 	rootCmd.Flags().VarP(flag.NewJobFlag(&o.Jobs), "job", "j", "任务配置")
 
 	rootCmd.AddCommand(&metaCmd, &ifaceCmd, &mockServerCmd, &apiserverCmd, &mmh3Cmd, &licenseCmd, &templateCmd, &reportCmd)
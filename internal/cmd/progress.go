@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/schollz/progressbar/v3"
+)
+
+// renderProgress 订阅执行事件，按阶段渲染进度条(单位为%)并输出警告与执行失败，返回的函数关闭订阅并等待渲染结束
+func renderProgress(manager *stage.Manager, logger *slog.Logger) func() {
+	events, _ := manager.Subscribe(256)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		bars := make(map[string]*progressbar.ProgressBar)
+		for event := range events {
+			name := string(event.Stage)
+			if event.Stage == types.StageJob {
+				name = fmt.Sprint(event.Entries[types.StageEntryJobName])
+			}
+			// 流水线模式下多个阶段同时执行，任务按序号区分
+			key := fmt.Sprintf("%s/%v", event.Stage, event.Entries[types.StageEntryJobIndex])
+
+			switch event.Type {
+			case types.EventStageStarted:
+				if event.Stage == types.StagePreExecute || event.Stage == types.StagePostExecute {
+					continue
+				}
+				logger.Info("Stage started", "stage", name)
+				bars[key] = util.NewPercentProgressbar(name)
+			case types.EventProgress:
+				if bar, ok := bars[key]; ok {
+					bar.Set(int(event.Percent * 100))
+				}
+			case types.EventStageFinished:
+				if bar, ok := bars[key]; ok {
+					bar.Finish()
+					delete(bars, key)
+				}
+				logger.Info("Stage finished", "stage", name)
+			case types.EventWarning:
+				logger.Warn(event.Message, "stage", name)
+			case types.EventError:
+				logger.Error(event.Message, "stage", name)
+			}
+		}
+	}()
+
+	return func() {
+		manager.Close()
+		<-done
+	}
+}
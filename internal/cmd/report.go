@@ -16,6 +16,7 @@ import (
 var (
 	hostDiscoveryFile string
 	portScanningFile  string
+	certificateFile   string
 	vulnJobFile       string
 )
 
@@ -23,7 +24,7 @@ var reportCmd = cobra.Command{
 	Use:   "report",
 	Short: "generate report",
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if hostDiscoveryFile == "" && portScanningFile == "" && vulnJobFile == "" {
+		if hostDiscoveryFile == "" && portScanningFile == "" && certificateFile == "" && vulnJobFile == "" {
 			return errors.New("no result files specified")
 		}
 
@@ -61,6 +62,20 @@ var reportCmd = cobra.Command{
 			})
 		}
 
+		if certificateFile != "" {
+			cf, err := os.Open(certificateFile)
+			if err != nil {
+				return fmt.Errorf("open certificate result file failed: %w", err)
+			}
+			defer cf.Close()
+
+			resultReaders = append(resultReaders, &types.ResultReader{
+				Format: export.Format(certificateFile),
+				Stage:  types.StageCertificate,
+				Reader: cf,
+			})
+		}
+
 		if vulnJobFile != "" {
 			f, err := os.Open(vulnJobFile)
 			if err != nil {
@@ -98,5 +113,6 @@ var reportCmd = cobra.Command{
 func init() {
 	reportCmd.Flags().StringVarP(&hostDiscoveryFile, "host_discovery", "d", "", "在线扫描结果文件")
 	reportCmd.Flags().StringVarP(&portScanningFile, "port_scanning", "p", "", "端口扫描结果文件")
+	reportCmd.Flags().StringVarP(&certificateFile, "certificate", "c", "", "证书采集结果文件")
 	reportCmd.Flags().StringVarP(&vulnJobFile, "job", "j", "", "漏洞扫描任务结果文件")
 }
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"reflect"
+
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+)
+
+// adjuster 支持运行中调整速率与并发数的阶段(扫描器、任务)
+type adjuster interface {
+	Adjust(rate, concurrency int)
+}
+
+// runningStage 执行中的阶段
+type runningStage struct {
+	name  types.StageName
+	index int // 任务序号，非任务阶段为0
+	adjuster
+}
+
+// stageOf 构建执行中的阶段，未配置(nil)或不支持调整时adjuster为nil
+func stageOf(name types.StageName, index int, stage any) runningStage {
+	rs := runningStage{name: name, index: index}
+	if a, ok := stage.(adjuster); ok && !reflect.ValueOf(a).IsNil() {
+		rs.adjuster = a
+	}
+	return rs
+}
+
+// setRunning 记录执行中的阶段(忽略未配置的nil阶段)，阶段结束时以空参数调用
+func (e *Engine) setRunning(stages ...runningStage) {
+	running := make([]runningStage, 0, len(stages))
+	for _, stage := range stages {
+		if stage.adjuster != nil {
+			running = append(running, stage)
+		}
+	}
+
+	e.rm.Lock()
+	defer e.rm.Unlock()
+	e.running = running
+}
+
+// Adjust 调整执行中阶段的速率(每秒)与并发数，小于等于0的值不调整
+//
+// 各阶段速率单位不同(在线检测、端口扫描为包/秒，任务为请求/秒)，因此只调整指定的阶段，阶段为任务时按任务序号区分
+func (e *Engine) Adjust(name types.StageName, index, rate, concurrency int) error {
+	if name == "" || rate < 0 || concurrency < 0 || rate == 0 && concurrency == 0 {
+		return types.ErrInvalidAdjustment
+	}
+
+	e.rm.Lock()
+	defer e.rm.Unlock()
+
+	for _, stage := range e.running {
+		if stage.name == name && (name != types.StageJob || stage.index == index) {
+			stage.Adjust(rate, concurrency)
+			return nil
+		}
+	}
+	return types.ErrNoRunningStage
+}
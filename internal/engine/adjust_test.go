@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/job"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAdjuster struct {
+	rate, concurrency int
+}
+
+func (f *fakeAdjuster) Adjust(rate, concurrency int) {
+	f.rate, f.concurrency = rate, concurrency
+}
+
+func TestAdjust(t *testing.T) {
+	assert := assert.New(t)
+
+	e := &Engine{}
+	assert.ErrorIs(e.Adjust(types.StagePortScanning, 0, 0, 0), types.ErrInvalidAdjustment)
+	assert.ErrorIs(e.Adjust(types.StagePortScanning, 0, -1, 10), types.ErrInvalidAdjustment)
+	assert.ErrorIs(e.Adjust("", 0, 100, 10), types.ErrInvalidAdjustment)
+	assert.ErrorIs(e.Adjust(types.StagePortScanning, 0, 100, 0), types.ErrNoRunningStage)
+
+	// 未配置的阶段(nil)不记录
+	var j *job.Job
+	ports, job1, job2 := &fakeAdjuster{}, &fakeAdjuster{}, &fakeAdjuster{}
+	e.setRunning(
+		stageOf(types.StagePortScanning, 0, ports),
+		stageOf(types.StageJob, 0, j),
+		stageOf(types.StageJob, 1, job1),
+		stageOf(types.StageJob, 2, job2),
+	)
+	assert.Len(e.running, 3)
+
+	tests := []struct {
+		name  types.StageName
+		index int
+		err   error
+		want  []fakeAdjuster
+	}{
+		// 只调整指定的阶段
+		{types.StagePortScanning, 0, nil, []fakeAdjuster{{100, 20}, {}, {}}},
+		// 任务按序号区分
+		{types.StageJob, 2, nil, []fakeAdjuster{{100, 20}, {}, {100, 20}}},
+		{types.StageJob, 0, types.ErrNoRunningStage, []fakeAdjuster{{100, 20}, {}, {100, 20}}},
+		{types.StageHostDiscovery, 0, types.ErrNoRunningStage, []fakeAdjuster{{100, 20}, {}, {100, 20}}},
+	}
+	for _, test := range tests {
+		err := e.Adjust(test.name, test.index, 100, 20)
+		if test.err != nil {
+			assert.ErrorIs(err, test.err, test.name)
+		} else {
+			assert.NoError(err, test.name)
+		}
+		assert.Equal(test.want, []fakeAdjuster{*ports, *job1, *job2}, test.name)
+	}
+
+	e.setRunning()
+	assert.ErrorIs(e.Adjust(types.StagePortScanning, 0, 100, 20), types.ErrNoRunningStage)
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+)
+
+// completedStage 获取由断点恢复的已完成阶段，未记录进度时返回nil
+func (e *Engine) completedStage(name string) *checkpoint.Stage {
+	if e.checkpoint == nil {
+		return nil
+	}
+	return e.checkpoint.Stage(name)
+}
+
+// completeStage 记录已完成阶段的输出
+func (e *Engine) completeStage(name string, stage *checkpoint.Stage) {
+	if e.checkpoint != nil {
+		e.checkpoint.SetStage(name, stage)
+	}
+}
+
+// restoreStage 由已完成的在线检测、端口扫描阶段恢复任务输入来源，返回阶段输出的目标
+func (e *Engine) restoreStage(name string) target.Source {
+	stage := e.checkpoint.Stage(name)
+	targets := target.Slice(stage.Targets)
+	if stage.Services != nil {
+		e.services = stage.Services
+	}
+	e.sources[name] = targets
+	return targets
+}
+
+// saveCheckpoint 收集各任务进度并写入断点文件
+func (e *Engine) saveCheckpoint() error {
+	for _, j := range e.jobs {
+		e.checkpoint.SetJob(j.Index(), j.Checkpoint())
+	}
+	return e.checkpoint.Save(e.checkpointFile)
+}
+
+// startCheckpoint 定期保存断点，返回的函数停止保存：执行完成时删除断点文件，否则保存最终进度
+func (e *Engine) startCheckpoint() func(completed bool) error {
+	if e.checkpoint == nil {
+		return func(bool) error { return nil }
+	}
+
+	ok := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(e.checkpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ok:
+				return
+			case <-ticker.C:
+				// 保存失败时在下个周期重试
+				_ = e.saveCheckpoint()
+			}
+		}
+	}()
+
+	return func(completed bool) error {
+		close(ok)
+		<-done
+
+		if completed {
+			return checkpoint.Remove(e.checkpointFile)
+		}
+		return e.saveCheckpoint()
+	}
+}
+
+// values 展开目标
+func values(source target.Source) []string {
+	if source == nil {
+		return nil
+	}
+
+	values := make([]string, 0, source.Size())
+	it := source.Iterator()
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		values = append(values, v)
+	}
+	return values
+}
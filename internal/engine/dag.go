@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/EscapeBearSecond/falcon/internal/job"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/samber/lo"
+)
+
+// dependencies 任务依赖的其他任务(输入来源任务、任务链的指纹任务)
+func dependencies(j *job.Job) []string {
+	var names []string
+	if input := j.Input(); input.From == types.JobInputJob {
+		names = append(names, input.Job)
+	}
+	if chain := j.Chain(); chain.Job != "" {
+		names = append(names, chain.Job)
+	}
+	return names
+}
+
+// sortJobs 按任务间的依赖关系排序
+//
+// 依赖其他任务结果的任务在来源任务之后执行，无依赖关系的任务保持配置顺序，存在循环依赖时返回错误
+func sortJobs(jobs []*job.Job) ([]*job.Job, error) {
+	byName := make(map[string]*job.Job, len(jobs))
+	for _, j := range jobs {
+		byName[j.Name()] = j
+	}
+
+	for _, j := range jobs {
+		for _, name := range dependencies(j) {
+			if _, ok := byName[name]; !ok {
+				return nil, fmt.Errorf("%w: job [%s] source job [%s] does not exist", types.ErrInvalidJobInput, j.Name(), name)
+			}
+			if name == j.Name() {
+				return nil, fmt.Errorf("%w: job [%s] depends on itself", types.ErrInvalidJobInput, j.Name())
+			}
+		}
+	}
+
+	sorted := make([]*job.Job, 0, len(jobs))
+	done := make(map[*job.Job]bool, len(jobs))
+	for len(sorted) < len(jobs) {
+		progressed := false
+		for _, j := range jobs {
+			if done[j] {
+				continue
+			}
+			if !lo.EveryBy(dependencies(j), func(name string) bool { return done[byName[name]] }) {
+				continue
+			}
+			done[j] = true
+			sorted = append(sorted, j)
+			progressed = true
+			// 每次仅取第一个可执行的任务，尽量保持配置顺序
+			break
+		}
+
+		if !progressed {
+			return nil, fmt.Errorf("%w: jobs have circular dependencies", types.ErrInvalidJobInput)
+		}
+	}
+
+	return sorted, nil
+}
+
+// checkJobInputs 检查任务输入的扫描阶段是否已配置
+func (e *Engine) checkJobInputs() error {
+	for _, j := range e.jobs {
+		switch j.Input().From {
+		case types.JobInputHosts:
+			if e.hostDiscoverer == nil {
+				return fmt.Errorf("%w: job [%s] requires host discovery", types.ErrInvalidJobInput, j.Name())
+			}
+		case types.JobInputPorts:
+			if e.portScanner == nil {
+				return fmt.Errorf("%w: job [%s] requires port scanning", types.ErrInvalidJobInput, j.Name())
+			}
+		}
+	}
+	return nil
+}
+
+// jobTargets 获取任务的输入目标，未指定来源时使用最后一个扫描阶段的结果(last)
+func (e *Engine) jobTargets(j *job.Job, last target.Source) target.Source {
+	input := j.Input()
+	switch input.From {
+	case types.JobInputTargets, types.JobInputHosts, types.JobInputPorts:
+		return e.sources[input.From]
+	case types.JobInputJob:
+		if source := e.job(input.Job); source != nil {
+			return source.Matched(input.Tags, input.Templates)
+		}
+	}
+	return last
+}
+
+// jobFingerprints 获取任务链中指纹任务的识别结果
+func (e *Engine) jobFingerprints(j *job.Job) job.Fingerprints {
+	if source := e.job(j.Chain().Job); source != nil {
+		return source.Fingerprints()
+	}
+	return nil
+}
+
+func (e *Engine) job(name string) *job.Job {
+	if name == "" {
+		return nil
+	}
+	for _, j := range e.jobs {
+		if j.Name() == name {
+			return j
+		}
+	}
+	return nil
+}
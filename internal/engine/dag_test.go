@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
+	"github.com/EscapeBearSecond/falcon/internal/job"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+// jobSpec 测试任务的名称、输入来源任务及指纹任务
+type jobSpec struct {
+	name, from, chain string
+}
+
+func newJobs(t *testing.T, specs ...jobSpec) []*job.Job {
+	jobs := make([]*job.Job, 0, len(specs))
+	for i, spec := range specs {
+		var input types.JobInputOptions
+		if spec.from != "" {
+			input = types.JobInputOptions{From: types.JobInputJob, Job: spec.from}
+		}
+		j, err := job.NewJob(
+			job.WithName(spec.name),
+			job.WithIndex(i),
+			job.WithGetTemplates(func() []*types.RawTemplate { return nil }),
+			job.WithInput(input),
+			job.WithChain(types.JobChainOptions{Job: spec.chain}),
+			job.WithExportFormat("console"),
+			job.WithTimeout("5s"),
+			job.WithSilent(true),
+		)
+		assert.NoError(t, err)
+		jobs = append(jobs, j)
+	}
+	return jobs
+}
+
+func TestSortJobs(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []jobSpec
+		want  []string
+		err   error
+	}{
+		{
+			name:  "no dependencies",
+			specs: []jobSpec{{name: "a"}, {name: "b"}, {name: "c"}},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "input after source",
+			specs: []jobSpec{{name: "a", from: "c"}, {name: "b"}, {name: "c"}},
+			want:  []string{"b", "c", "a"},
+		},
+		{
+			name:  "chain after fingerprint",
+			specs: []jobSpec{{name: "vuln", chain: "fp"}, {name: "fp"}},
+			want:  []string{"fp", "vuln"},
+		},
+		{
+			name:  "transitive",
+			specs: []jobSpec{{name: "a", from: "b"}, {name: "b", chain: "c"}, {name: "c"}},
+			want:  []string{"c", "b", "a"},
+		},
+		{
+			name:  "unknown source",
+			specs: []jobSpec{{name: "a", from: "none"}},
+			err:   types.ErrInvalidJobInput,
+		},
+		{
+			name:  "unknown chain",
+			specs: []jobSpec{{name: "a", chain: "none"}},
+			err:   types.ErrInvalidJobInput,
+		},
+		{
+			name:  "self",
+			specs: []jobSpec{{name: "a", from: "a"}},
+			err:   types.ErrInvalidJobInput,
+		},
+		{
+			name:  "cycle",
+			specs: []jobSpec{{name: "a", from: "b"}, {name: "b", chain: "c"}, {name: "c", from: "a"}, {name: "d"}},
+			err:   types.ErrInvalidJobInput,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			sorted, err := sortJobs(newJobs(t, test.specs...))
+			if test.err != nil {
+				assert.ErrorIs(err, test.err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(test.want, lo.Map(sorted, func(j *job.Job, _ int) string { return j.Name() }))
+		})
+	}
+}
+
+func TestCheckJobInputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		hosts  bool
+		ports  bool
+		hasErr bool
+	}{
+		{name: "default", from: ""},
+		{name: "targets", from: types.JobInputTargets},
+		{name: "hosts", from: types.JobInputHosts, hosts: true},
+		{name: "hosts without discovery", from: types.JobInputHosts, ports: true, hasErr: true},
+		{name: "ports", from: types.JobInputPorts, ports: true},
+		{name: "ports without scanning", from: types.JobInputPorts, hosts: true, hasErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			j, err := job.NewJob(
+				job.WithName("job"),
+				job.WithGetTemplates(func() []*types.RawTemplate { return nil }),
+				job.WithInput(types.JobInputOptions{From: test.from}),
+				job.WithExportFormat("console"),
+				job.WithTimeout("5s"),
+				job.WithSilent(true),
+			)
+			assert.NoError(err)
+
+			e := &Engine{jobs: []*job.Job{j}}
+			if test.hosts {
+				e.hostDiscoverer = fakeDiscoverer{}
+			}
+			if test.ports {
+				e.portScanner = fakePortScanner{}
+			}
+
+			err = e.checkJobInputs()
+			if test.hasErr {
+				assert.ErrorIs(err, types.ErrInvalidJobInput)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestJobTargets(t *testing.T) {
+	assert := assert.New(t)
+
+	jobs := newJobs(t, jobSpec{name: "source"})
+	jobs[0].Restore(&checkpoint.Job{Matches: []*checkpoint.Match{
+		{Target: "http://10.0.0.1", TemplateID: "wp-detect", Tags: []string{"wordpress", "tech"}},
+		{Target: "http://10.0.0.2", TemplateID: "nginx-detect", Tags: []string{"nginx", "tech"}},
+	}})
+
+	e := &Engine{
+		jobs: jobs,
+		sources: map[string]target.Source{
+			types.JobInputTargets: target.Slice{"10.0.0.0"},
+			types.JobInputHosts:   target.Slice{"10.0.0.1", "10.0.0.2"},
+			types.JobInputPorts:   target.Slice{"10.0.0.1:80"},
+		},
+	}
+	last := target.Slice{"last"}
+
+	tests := []struct {
+		name  string
+		input types.JobInputOptions
+		want  target.Source
+	}{
+		{"last stage", types.JobInputOptions{}, last},
+		{"targets", types.JobInputOptions{From: types.JobInputTargets}, target.Slice{"10.0.0.0"}},
+		{"hosts", types.JobInputOptions{From: types.JobInputHosts}, target.Slice{"10.0.0.1", "10.0.0.2"}},
+		{"ports", types.JobInputOptions{From: types.JobInputPorts}, target.Slice{"10.0.0.1:80"}},
+		{"job", types.JobInputOptions{From: types.JobInputJob, Job: "source"}, target.Slice{"http://10.0.0.1", "http://10.0.0.2"}},
+		{"job tags", types.JobInputOptions{From: types.JobInputJob, Job: "source", Tags: []string{"WordPress"}}, target.Slice{"http://10.0.0.1"}},
+		{"job templates", types.JobInputOptions{From: types.JobInputJob, Job: "source", Templates: []string{"nginx-detect"}}, target.Slice{"http://10.0.0.2"}},
+		{"unknown job", types.JobInputOptions{From: types.JobInputJob, Job: "none"}, last},
+	}
+
+	for _, test := range tests {
+		j, err := job.NewJob(
+			job.WithName(test.name),
+			job.WithGetTemplates(func() []*types.RawTemplate { return nil }),
+			job.WithInput(test.input),
+			job.WithExportFormat("console"),
+			job.WithTimeout("5s"),
+			job.WithSilent(true),
+		)
+		assert.NoError(err, test.name)
+		assert.Equal(test.want, e.jobTargets(j, last), test.name)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"runtime/debug"
+	"sync"
 	"time"
 
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
 	"github.com/EscapeBearSecond/falcon/internal/global"
 	"github.com/EscapeBearSecond/falcon/internal/job"
 	"github.com/EscapeBearSecond/falcon/internal/meta"
@@ -14,25 +17,51 @@ import (
 	"github.com/EscapeBearSecond/falcon/internal/stage"
 	"github.com/EscapeBearSecond/falcon/internal/target"
 	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/internal/util/oob"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
 	"github.com/common-nighthawk/go-figure"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/interactsh"
 	"github.com/samber/lo"
 )
 
+// pipelineBuffer 流水线模式下阶段之间通道的容量
+const pipelineBuffer = 256
+
 type Engine struct {
 	targets        []string
 	excludeTargets []string
+	space          *target.Space // 惰性展开的目标空间
+
+	dnsResolver      scanner.Scanner[*target.Space, *scanner.Resolution]               // 域名解析
+	portScanner      scanner.Scanner[target.Source, *scanner.PortScanning]             // 端口扫描器
+	hostDiscoverer   scanner.Scanner[target.Source, target.Source]                     // 探活扫描器
+	certCollector    scanner.Scanner[*scanner.ServiceTargets, []*types.CertResultItem] // 证书采集
+	webFingerprinter scanner.Scanner[*scanner.ServiceTargets, []*types.WebResultItem]  // Web指纹识别
 
-	portScanner    scanner.Scanner[[]string, []string] // 端口扫描器
-	hostDiscoverer scanner.Scanner[[]string, []string] // 探活扫描器
+	hostnames target.Hostnames         // 域名解析得到的IP与域名对应关系
+	services  target.Services          // 端口扫描识别的服务
+	sources   map[string]target.Source // 各扫描阶段的结果(任务输入来源)
+
+	seed     int64 // 扫描顺序随机种子
+	pipeline bool  // 流水线模式
 
 	jobs []*job.Job
 
 	disableBanner bool
 	eOptions      *protocols.ExecutorOptions
 
+	interactsh types.InteractshOptions // 带外交互
+	oobServer  *oob.Server             // 内置交互服务(未指定交互服务地址时启动)
+
+	checkpoint         *checkpoint.Checkpoint // 执行进度(为nil时不记录)
+	checkpointFile     string                 // 断点文件
+	checkpointInterval time.Duration          // 断点保存间隔
+
 	stageManager *stage.Manager
+
+	rm      sync.Mutex
+	running []runningStage // 执行中的阶段(用于运行中调整速率与并发数)
 }
 
 // New 实例化引擎
@@ -60,11 +89,20 @@ func (e *Engine) init() error {
 		return types.ErrInvalidTargets
 	}
 
-	targets, err := target.ProcessAsync(e.targets, e.excludeTargets...)
+	space, err := target.NewSpace(e.targets, e.excludeTargets...)
 	if err != nil {
 		return fmt.Errorf("process targets failed: %w", err)
 	}
-	e.targets = targets
+	e.space = space
+
+	// 继续执行时使用之前的种子，保证扫描顺序一致
+	if e.checkpoint != nil && e.checkpoint.Resumed() {
+		e.seed = e.checkpoint.Seed
+	}
+	// 未指定种子时随机生成，可通过Seed()获取用于复现
+	for e.seed == 0 {
+		e.seed = rand.Int63()
+	}
 
 	e.eOptions = global.ExecutorOptions()
 
@@ -73,6 +111,11 @@ func (e *Engine) init() error {
 	browser, _ := global.Browser()
 	e.eOptions.Browser = browser
 
+	iOptions, err := e.interactshOptions()
+	if err != nil {
+		return err
+	}
+
 	names := make([]string, 0, len(e.jobs))
 	// 遍历所有的job
 	for _, j := range e.jobs {
@@ -82,6 +125,8 @@ func (e *Engine) init() error {
 		} else { //如果job的name已存在，则存在重名任务，给当前job添加随机后缀
 			j.SetName(fmt.Sprintf("%s_%s", j.Name(), util.RandomStr(10)))
 		}
+		// 各任务使用独立的interactsh客户端，交互命中的结果关联到对应任务
+		j.SetInteractsh(iOptions)
 		// 加载模版，使用filepath的walkdir方式
 		err := j.LoadTemplates(e.eOptions)
 		if err != nil {
@@ -89,11 +134,82 @@ func (e *Engine) init() error {
 		}
 	}
 
+	// 按任务输入的依赖关系确定执行顺序
+	e.jobs, err = sortJobs(e.jobs)
+	if err != nil {
+		return err
+	}
+	if err := e.checkJobInputs(); err != nil {
+		return err
+	}
+	e.sources = make(map[string]target.Source)
+
+	if e.checkpoint != nil {
+		if err := e.checkpoint.Init(e.seed, e.targets, e.excludeTargets); err != nil {
+			return err
+		}
+		for _, j := range e.jobs {
+			j.Restore(e.checkpoint.Job(j.Index()))
+		}
+	}
+
+	if e.oobServer != nil {
+		if err := e.oobServer.Start(); err != nil {
+			return err
+		}
+	}
+
 	e.stageManager.Put(types.StagePreExecute, 0)
 
 	return nil
 }
 
+// interactshOptions 解析带外交互选项，未开启时返回nil
+//
+// 未指定交互服务地址时使用内置服务(在初始化完成时启动)，交互域名需为目标可访问的地址
+func (e *Engine) interactshOptions() (*interactsh.Options, error) {
+	if !e.interactsh.Use {
+		return nil, nil
+	}
+
+	durations := make([]time.Duration, 0, 3)
+	for _, value := range []string{e.interactsh.PollInterval, e.interactsh.Cooldown, e.interactsh.Eviction} {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%w: invalid duration %q", types.ErrInvalidInteractsh, value)
+		}
+		durations = append(durations, duration)
+	}
+
+	server := e.interactsh.Server
+	if server == "" {
+		if e.interactsh.Domain == "" {
+			return nil, fmt.Errorf("%w: domain is required for built-in server", types.ErrInvalidInteractsh)
+		}
+		e.oobServer = oob.New(&oob.Config{
+			Listen:    e.interactsh.Listen,
+			DNSListen: e.interactsh.DNSListen,
+			Domain:    e.interactsh.Domain,
+			IP:        e.interactsh.IP,
+			Token:     e.interactsh.Token,
+		})
+		server = e.oobServer.URL()
+	}
+
+	opts := global.InteractshOptions(nil)
+	opts.ServerURL = server
+	opts.Authorization = e.interactsh.Token
+	opts.PollDuration = durations[0]
+	opts.CooldownPeriod = durations[1]
+	opts.Eviction = durations[2]
+	return opts, nil
+}
+
+// Seed 扫描顺序随机种子
+func (e *Engine) Seed() int64 {
+	return e.seed
+}
+
 // printBanner 打印banner信息
 func printBanner() {
 	figure.NewColorFigure("Eagleeye", "rectangles", "green", true).Print()
@@ -102,20 +218,99 @@ func printBanner() {
 
 // ExecuteWithContext 执行
 func (e *Engine) ExecuteWithContext(c context.Context) error {
+	stop := e.startCheckpoint()
+	err := e.execute(c)
+	return errors.Join(err, stop(err == nil && c.Err() == nil))
+}
+
+func (e *Engine) execute(c context.Context) error {
 	defer e.stageManager.Put(types.StagePostExecute, 0)
 	defer e.close()
 
 	if !e.disableBanner {
 		printBanner()
+		fmt.Printf("seed: %d\n\n", e.seed)
 	}
 
-	// 用于任务间隔的计时（当前置任务结束，重置计时器）
+	// 用于任务间隔的计时（当前置任务结束，重置计时器），流水线模式下不间隔
+	interval := 5 * time.Second
+	if e.pipeline {
+		interval = 0
+	}
 	timer := time.NewTimer(0)
 	defer timer.Stop()
 
-	if e.hostDiscoverer != nil {
+	// 各阶段之间传递的目标
+	var targets target.Source = e.space
+	e.sources[types.JobInputTargets] = e.space
+
+	// 执行域名解析
+	if stage := e.completedStage(checkpoint.StageDNSResolution); e.dnsResolver != nil && stage != nil {
+		targets = e.space.Resolve(stage.Records)
+		e.hostnames = stage.Hostnames
+		e.sources[types.JobInputTargets] = targets
+	} else if e.dnsResolver != nil {
 		<-timer.C
-		targets, err := e.hostDiscoverer.Scan(c, &scanner.Options[[]string]{Targets: e.targets})
+		e.setRunning(stageOf(types.StageDNSResolution, 0, e.dnsResolver))
+		resolution, err := e.dnsResolver.Scan(c, &scanner.Options[*target.Space]{Targets: e.space, Seed: e.seed})
+		e.setRunning()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("run dns resolution failed: %w", err)
+		}
+
+		if resolution.Targets.Size() == 0 {
+			return types.ErrNoResolvedHost
+		}
+
+		targets = resolution.Targets
+		e.hostnames = resolution.Hostnames
+		e.sources[types.JobInputTargets] = targets
+		e.completeStage(checkpoint.StageDNSResolution, &checkpoint.Stage{Records: resolution.Records, Hostnames: resolution.Hostnames})
+		timer.Reset(interval)
+	}
+
+	jobs := e.jobs
+
+	// 流水线模式下在线检测、端口扫描与第一个任务同时执行
+	last := lo.If(e.portScanner != nil, checkpoint.StagePortScanning).Else(checkpoint.StageHostDiscovery)
+	if e.pipeline && e.completedStage(last) != nil {
+		if e.hostDiscoverer != nil {
+			targets = e.restoreStage(checkpoint.StageHostDiscovery)
+		}
+		if e.portScanner != nil {
+			targets = e.restoreStage(checkpoint.StagePortScanning)
+		}
+	} else if e.pipeline {
+		<-timer.C
+		var err error
+		targets, jobs, err = e.executePipeline(c, targets)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+		if e.hostDiscoverer != nil {
+			e.completeStage(checkpoint.StageHostDiscovery, &checkpoint.Stage{Targets: values(e.sources[types.JobInputHosts])})
+		}
+		if e.portScanner != nil {
+			e.completeStage(checkpoint.StagePortScanning, &checkpoint.Stage{Targets: values(targets), Services: e.services})
+		}
+		timer.Reset(interval)
+
+		debug.FreeOSMemory()
+	}
+
+	if e.hostDiscoverer != nil && !e.pipeline && e.completedStage(checkpoint.StageHostDiscovery) != nil {
+		targets = e.restoreStage(checkpoint.StageHostDiscovery)
+	} else if e.hostDiscoverer != nil && !e.pipeline {
+		<-timer.C
+		e.setRunning(stageOf(types.StageHostDiscovery, 0, e.hostDiscoverer))
+		results, err := e.hostDiscoverer.Scan(c, &scanner.Options[target.Source]{Targets: targets, Seed: e.seed})
+		e.setRunning()
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				return nil
@@ -123,20 +318,26 @@ func (e *Engine) ExecuteWithContext(c context.Context) error {
 			return fmt.Errorf("run host discovery failed: %w", err)
 		}
 
-		if len(targets) == 0 {
+		if results.Size() == 0 {
 			return types.ErrNoActiveHost
 		}
 
-		e.targets = targets
-		timer.Reset(5 * time.Second)
+		targets = results
+		e.sources[types.JobInputHosts] = targets
+		e.completeStage(checkpoint.StageHostDiscovery, &checkpoint.Stage{Targets: values(targets)})
+		timer.Reset(interval)
 
 		debug.FreeOSMemory()
 	}
 
 	// 执行端口扫描
-	if e.portScanner != nil {
+	if e.portScanner != nil && !e.pipeline && e.completedStage(checkpoint.StagePortScanning) != nil {
+		targets = e.restoreStage(checkpoint.StagePortScanning)
+	} else if e.portScanner != nil && !e.pipeline {
 		<-timer.C
-		targets, err := e.portScanner.Scan(c, &scanner.Options[[]string]{Targets: e.targets})
+		e.setRunning(stageOf(types.StagePortScanning, 0, e.portScanner))
+		results, err := e.portScanner.Scan(c, &scanner.Options[target.Source]{Targets: targets, Seed: e.seed})
+		e.setRunning()
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				return nil
@@ -144,33 +345,92 @@ func (e *Engine) ExecuteWithContext(c context.Context) error {
 			return fmt.Errorf("run port scanning failed: %w", err)
 		}
 
-		if len(targets) == 0 {
+		if results.Targets.Size() == 0 {
 			return types.ErrNoExistPort
 		}
 
-		e.targets = targets
-		timer.Reset(5 * time.Second)
+		targets = results.Targets
+		e.services = results.Services
+		e.sources[types.JobInputPorts] = targets
+		e.completeStage(checkpoint.StagePortScanning, &checkpoint.Stage{Targets: values(targets), Services: e.services})
+		timer.Reset(interval)
 
 		debug.FreeOSMemory()
 	}
 
-	for _, j := range e.jobs {
+	// 执行证书采集(不改变后续任务的目标)
+	if e.certCollector != nil && e.completedStage(checkpoint.StageCertificate) == nil {
+		<-timer.C
+		e.setRunning(stageOf(types.StageCertificate, 0, e.certCollector))
+		_, err := e.certCollector.Scan(c, &scanner.Options[*scanner.ServiceTargets]{
+			Targets: &scanner.ServiceTargets{Targets: targets, Services: e.services, Hostnames: e.hostnames},
+			Seed:    e.seed,
+		})
+		e.setRunning()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("run certificate collection failed: %w", err)
+		}
+		e.completeStage(checkpoint.StageCertificate, &checkpoint.Stage{})
+		timer.Reset(interval)
+
+		debug.FreeOSMemory()
+	}
+
+	// 执行Web指纹识别(不改变后续任务的目标)
+	if e.webFingerprinter != nil && e.completedStage(checkpoint.StageWebFingerprint) == nil {
+		<-timer.C
+		e.setRunning(stageOf(types.StageWebFingerprint, 0, e.webFingerprinter))
+		_, err := e.webFingerprinter.Scan(c, &scanner.Options[*scanner.ServiceTargets]{
+			Targets: &scanner.ServiceTargets{Targets: targets, Services: e.services, Hostnames: e.hostnames},
+			Seed:    e.seed,
+		})
+		e.setRunning()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("run web fingerprint failed: %w", err)
+		}
+		e.completeStage(checkpoint.StageWebFingerprint, &checkpoint.Stage{})
+		timer.Reset(interval)
+
+		debug.FreeOSMemory()
+	}
+
+	for _, j := range jobs {
 		select {
 		case <-c.Done():
 			return nil
 		default:
 		}
 
+		// 由断点恢复的已完成任务只回调已有结果，无需间隔
+		if j.Completed() {
+			j.ExecuteWithContext(c, &job.Options{})
+			continue
+		}
+
 		<-timer.C
 
-		err := j.ExecuteWithContext(c, &job.Options{Targets: e.targets})
+		e.setRunning(stageOf(types.StageJob, j.Index(), j))
+		err := j.ExecuteWithContext(c, &job.Options{
+			Targets:      e.jobTargets(j, targets),
+			Hostnames:    e.hostnames,
+			Services:     e.services,
+			Seed:         e.seed,
+			Fingerprints: e.jobFingerprints(j),
+		})
+		e.setRunning()
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				return nil
 			}
 			return fmt.Errorf("excute job [%s] failed: %w", j.Name(), err)
 		}
-		timer.Reset(5 * time.Second)
+		timer.Reset(interval)
 
 		debug.FreeOSMemory()
 	}
@@ -178,6 +438,138 @@ func (e *Engine) ExecuteWithContext(c context.Context) error {
 	return nil
 }
 
+// executePipeline 流水线模式执行在线检测、端口扫描与第一个任务
+//
+// 存活主机发现后立即进入端口扫描，开放端口发现后立即进入第一个任务，阶段之间通过有界通道传递目标，
+// 下游处理不及时时上游阻塞(背压)。证书采集、Web指纹识别及其余任务需要完整的端口扫描结果，仍在之后依次执行。
+// 返回端口扫描(或在线检测)的结果与未执行的任务
+func (e *Engine) executePipeline(c context.Context, targets target.Source) (target.Source, []*job.Job, error) {
+	c, cancel := context.WithCancel(c)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		input <-chan target.Item // 下一阶段的输入
+
+		hosts     target.Source
+		scanning  *scanner.PortScanning
+		streamed  *job.Job
+		discovery error
+		port      error
+		execute   error
+	)
+
+	jobs := e.jobs
+	// 流水线最后一个阶段，仅未指定输入或以其为输入的第一个任务可接收流式目标
+	last := lo.If(e.portScanner != nil, types.JobInputPorts).Else(types.JobInputHosts)
+	stream := len(jobs) > 0 && lo.Contains([]string{"", last}, jobs[0].Input().From)
+
+	if e.hostDiscoverer != nil {
+		// 仅在存在下游(端口扫描或接收流式目标的任务)时输出，否则无人接收导致阻塞
+		var out chan target.Item
+		if e.portScanner != nil || stream {
+			out = make(chan target.Item, pipelineBuffer)
+		}
+		input = out
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out != nil {
+				defer close(out)
+			}
+			hosts, discovery = e.hostDiscoverer.Scan(c, &scanner.Options[target.Source]{Targets: targets, Seed: e.seed, Output: out})
+			if discovery != nil {
+				cancel()
+			}
+		}()
+	}
+
+	if e.portScanner != nil {
+		in := input
+		var out chan target.Item
+		if stream {
+			out = make(chan target.Item, pipelineBuffer)
+		}
+		input = out
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out != nil {
+				defer close(out)
+			}
+			scanning, port = e.portScanner.Scan(c, &scanner.Options[target.Source]{Targets: targets, Seed: e.seed, Input: in, Output: out})
+			if port != nil {
+				cancel()
+			}
+		}()
+	}
+
+	// 同时执行的各阶段
+	running := make([]runningStage, 0, 3)
+	if e.hostDiscoverer != nil {
+		running = append(running, stageOf(types.StageHostDiscovery, 0, e.hostDiscoverer))
+	}
+	if e.portScanner != nil {
+		running = append(running, stageOf(types.StagePortScanning, 0, e.portScanner))
+	}
+
+	if input != nil && stream {
+		streamed, jobs = jobs[0], jobs[1:]
+		running = append(running, stageOf(types.StageJob, streamed.Index(), streamed))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 任务提前结束(如无可执行模板)时继续接收，避免上游阻塞
+			defer func() {
+				for range input {
+				}
+			}()
+			execute = streamed.ExecuteWithContext(c, &job.Options{Hostnames: e.hostnames, Seed: e.seed, Input: input})
+			if execute != nil {
+				cancel()
+			}
+		}()
+	}
+
+	e.setRunning(running...)
+	wg.Wait()
+	e.setRunning()
+
+	// 优先返回上游阶段的错误，因其他阶段出错而取消的阶段忽略
+	switch {
+	case discovery != nil && !errors.Is(discovery, context.Canceled):
+		return nil, nil, fmt.Errorf("run host discovery failed: %w", discovery)
+	case port != nil && !errors.Is(port, context.Canceled):
+		return nil, nil, fmt.Errorf("run port scanning failed: %w", port)
+	case execute != nil && !errors.Is(execute, context.Canceled):
+		return nil, nil, fmt.Errorf("excute job [%s] failed: %w", streamed.Name(), execute)
+	case discovery != nil || port != nil || execute != nil:
+		return nil, nil, context.Canceled
+	}
+
+	if hosts != nil {
+		if hosts.Size() == 0 {
+			return nil, nil, types.ErrNoActiveHost
+		}
+		targets = hosts
+		e.sources[types.JobInputHosts] = targets
+	}
+
+	if scanning != nil {
+		if scanning.Targets.Size() == 0 {
+			return nil, nil, types.ErrNoExistPort
+		}
+		targets = scanning.Targets
+		e.services = scanning.Services
+		e.sources[types.JobInputPorts] = targets
+	}
+
+	return targets, jobs, nil
+}
+
 func (e *Engine) close() {
 	e.eOptions.Output.Close()
 	e.eOptions.Progress.Stop()
@@ -186,4 +578,8 @@ func (e *Engine) close() {
 	if e.eOptions.Browser != nil {
 		e.eOptions.Browser.Close()
 	}
+
+	if e.oobServer != nil {
+		e.oobServer.Close()
+	}
 }
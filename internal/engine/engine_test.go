@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/scanner"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDiscoverer 存活主机多于流水线通道容量的在线检测
+type fakeDiscoverer struct{}
+
+func (fakeDiscoverer) Scan(c context.Context, o *scanner.Options[target.Source]) (target.Source, error) {
+	hosts := make(target.Slice, 0, 2*pipelineBuffer)
+	for i := range 2 * pipelineBuffer {
+		host := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		hosts = append(hosts, host)
+		if o.Output != nil {
+			o.Output <- target.Item{Target: host}
+		}
+	}
+	return hosts, nil
+}
+
+// fakePortScanner 每个存活主机开放80端口
+type fakePortScanner struct{}
+
+func (fakePortScanner) Scan(c context.Context, o *scanner.Options[target.Source]) (*scanner.PortScanning, error) {
+	var ports target.Slice
+	for item := range o.Input {
+		ports = append(ports, item.Target+":80")
+	}
+	return &scanner.PortScanning{Targets: ports}, nil
+}
+
+func TestExecutePipeline(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name  string
+		e     *Engine
+		stage string
+		size  int
+	}{
+		// 无下游阶段时不输出，不阻塞在线检测
+		{"discovery only", &Engine{hostDiscoverer: fakeDiscoverer{}}, types.JobInputHosts, 2 * pipelineBuffer},
+		{"discovery and ports", &Engine{hostDiscoverer: fakeDiscoverer{}, portScanner: fakePortScanner{}}, types.JobInputPorts, 2 * pipelineBuffer},
+	}
+	for _, tt := range tests {
+		tt.e.sources = make(map[string]target.Source)
+
+		done := make(chan struct{})
+		var (
+			targets target.Source
+			err     error
+		)
+		go func() {
+			defer close(done)
+			targets, _, err = tt.e.executePipeline(context.Background(), target.Slice{"10.0.0.0/16"})
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: pipeline blocked", tt.name)
+		}
+		assert.NoError(err, tt.name)
+		if assert.NotNil(targets, tt.name) {
+			assert.Equal(uint64(tt.size), targets.Size(), tt.name)
+		}
+		assert.Equal(targets, tt.e.sources[tt.stage], tt.name)
+	}
+}
@@ -1,9 +1,14 @@
 package engine
 
 import (
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
 	"github.com/EscapeBearSecond/falcon/internal/job"
 	"github.com/EscapeBearSecond/falcon/internal/scanner"
 	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
 )
 
 type Option func(*Engine)
@@ -16,19 +21,40 @@ func WithTargets(targets []string) Option {
 }
 
 // WithPortScanner 配置端口扫描
-func WithPortScanner(sc scanner.Scanner[[]string, []string]) Option {
+func WithPortScanner(sc scanner.Scanner[target.Source, *scanner.PortScanning]) Option {
 	return func(e *Engine) {
 		e.portScanner = sc
 	}
 }
 
+// WithDNSResolver 配置域名解析
+func WithDNSResolver(sc scanner.Scanner[*target.Space, *scanner.Resolution]) Option {
+	return func(e *Engine) {
+		e.dnsResolver = sc
+	}
+}
+
 // WithHostDiscoverer 配置在线监测
-func WithHostDiscoverer(sc scanner.Scanner[[]string, []string]) Option {
+func WithHostDiscoverer(sc scanner.Scanner[target.Source, target.Source]) Option {
 	return func(e *Engine) {
 		e.hostDiscoverer = sc
 	}
 }
 
+// WithCertCollector 配置证书采集
+func WithCertCollector(sc scanner.Scanner[*scanner.ServiceTargets, []*types.CertResultItem]) Option {
+	return func(e *Engine) {
+		e.certCollector = sc
+	}
+}
+
+// WithWebFingerprinter 配置Web指纹识别
+func WithWebFingerprinter(sc scanner.Scanner[*scanner.ServiceTargets, []*types.WebResultItem]) Option {
+	return func(e *Engine) {
+		e.webFingerprinter = sc
+	}
+}
+
 // WithJobs 配置任务
 func WithJobs(jobs ...*job.Job) Option {
 	return func(e *Engine) {
@@ -54,3 +80,33 @@ func WithStageManager(manager *stage.Manager) Option {
 		e.stageManager = manager
 	}
 }
+
+// WithSeed 配置扫描顺序随机种子(0则随机生成)
+func WithSeed(seed int64) Option {
+	return func(e *Engine) {
+		e.seed = seed
+	}
+}
+
+// WithPipeline 配置流水线模式(在线检测、端口扫描与任务同时执行)
+func WithPipeline(pipeline bool) Option {
+	return func(e *Engine) {
+		e.pipeline = pipeline
+	}
+}
+
+// WithInteractsh 配置带外交互(OOB)
+func WithInteractsh(opts types.InteractshOptions) Option {
+	return func(e *Engine) {
+		e.interactsh = opts
+	}
+}
+
+// WithCheckpoint 配置执行进度，按间隔保存至断点文件(包含之前的进度时继续执行)
+func WithCheckpoint(cp *checkpoint.Checkpoint, file string, interval time.Duration) Option {
+	return func(e *Engine) {
+		e.checkpoint = cp
+		e.checkpointFile = file
+		e.checkpointInterval = interval
+	}
+}
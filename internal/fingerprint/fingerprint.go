@@ -0,0 +1,210 @@
+package fingerprint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 版本提取的响应位置
+const (
+	PartTitle  = "title"
+	PartServer = "server"
+	PartHeader = "header"
+	PartBody   = "body"
+)
+
+// Database 本地指纹库
+type Database struct {
+	fingerprints []*Fingerprint
+}
+
+// Fingerprint Web指纹
+//
+// ID同时作为版本漏洞映射文件中的template_id
+type Fingerprint struct {
+	ID       string       `yaml:"id"`
+	Name     string       `yaml:"name"`
+	Matchers []*Matcher   `yaml:"matchers"` //任一匹配器命中即命中
+	Versions []*Extractor `yaml:"versions"` //依次提取版本，取第一个结果
+}
+
+// Matcher 指纹匹配器，所有非空条件均满足才命中
+//
+// 字符串条件为忽略大小写的包含匹配
+type Matcher struct {
+	Status  int     `yaml:"status"`
+	Title   string  `yaml:"title"`
+	Server  string  `yaml:"server"`
+	Header  string  `yaml:"header"`
+	Body    string  `yaml:"body"`
+	Favicon []int32 `yaml:"favicon"` //favicon mmh3 hash，命中任一即可
+}
+
+// Extractor 版本提取，取正则第一个分组(无分组时取整个匹配)
+type Extractor struct {
+	Part  string `yaml:"part"`
+	Regex string `yaml:"regex"`
+
+	re *regexp.Regexp
+}
+
+// Response 待匹配的HTTP响应
+type Response struct {
+	StatusCode  int
+	Title       string
+	Server      string
+	Header      string //原始响应头(Key: Value)
+	Body        string
+	FaviconHash int32
+	HasFavicon  bool
+}
+
+// Match 命中的指纹
+type Match struct {
+	ID      string
+	Name    string
+	Version string
+}
+
+// String 产品/版本
+func (m *Match) String() string {
+	if m.Version == "" {
+		return m.Name
+	}
+	return m.Name + "/" + m.Version
+}
+
+// New 加载指纹库文件，文件名为空时返回空指纹库
+func New(filename string) (*Database, error) {
+	db := &Database{}
+	if filename == "" {
+		return db, nil
+	}
+
+	fBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read fingerprints file failed: %w", err)
+	}
+
+	var fingerprints []*Fingerprint
+	err = yaml.Unmarshal(fBytes, &fingerprints)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal fingerprints file failed: %w", err)
+	}
+
+	for _, fp := range fingerprints {
+		if err := fp.compile(); err != nil {
+			return nil, fmt.Errorf("invalid fingerprint %s: %w", fp.ID, err)
+		}
+	}
+	db.fingerprints = fingerprints
+
+	return db, nil
+}
+
+func (fp *Fingerprint) compile() error {
+	if fp.ID == "" {
+		return errors.New("id is not specified")
+	}
+	if fp.Name == "" {
+		fp.Name = fp.ID
+	}
+	if len(fp.Matchers) == 0 {
+		return errors.New("matchers are not specified")
+	}
+	// 无条件的匹配器会命中所有响应
+	if slices.ContainsFunc(fp.Matchers, (*Matcher).empty) {
+		return errors.New("matcher has no conditions")
+	}
+
+	for _, e := range fp.Versions {
+		switch e.Part {
+		case PartTitle, PartServer, PartHeader, PartBody:
+		default:
+			return fmt.Errorf("unsupport version part %q", e.Part)
+		}
+
+		re, err := regexp.Compile(e.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid version regex: %w", err)
+		}
+		e.re = re
+	}
+	return nil
+}
+
+// Len 指纹数量
+func (db *Database) Len() int {
+	return len(db.fingerprints)
+}
+
+// Match 匹配响应，返回命中的指纹
+func (db *Database) Match(resp *Response) []*Match {
+	var matches []*Match
+	for _, fp := range db.fingerprints {
+		if !slices.ContainsFunc(fp.Matchers, func(m *Matcher) bool { return m.match(resp) }) {
+			continue
+		}
+		matches = append(matches, &Match{ID: fp.ID, Name: fp.Name, Version: fp.version(resp)})
+	}
+	return matches
+}
+
+func (m *Matcher) empty() bool {
+	return m.Status == 0 && len(m.Favicon) == 0 && m.Title == "" && m.Server == "" && m.Header == "" && m.Body == ""
+}
+
+func (m *Matcher) match(resp *Response) bool {
+	if m.Status != 0 && m.Status != resp.StatusCode {
+		return false
+	}
+	if len(m.Favicon) != 0 && (!resp.HasFavicon || !slices.Contains(m.Favicon, resp.FaviconHash)) {
+		return false
+	}
+
+	for part, substr := range map[string]string{
+		PartTitle:  m.Title,
+		PartServer: m.Server,
+		PartHeader: m.Header,
+		PartBody:   m.Body,
+	} {
+		if substr != "" && !strings.Contains(strings.ToLower(resp.part(part)), strings.ToLower(substr)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (fp *Fingerprint) version(resp *Response) string {
+	for _, e := range fp.Versions {
+		match := e.re.FindStringSubmatch(resp.part(e.Part))
+		if match == nil {
+			continue
+		}
+		if len(match) > 1 {
+			return match[1]
+		}
+		return match[0]
+	}
+	return ""
+}
+
+func (resp *Response) part(name string) string {
+	switch name {
+	case PartTitle:
+		return resp.Title
+	case PartServer:
+		return resp.Server
+	case PartHeader:
+		return resp.Header
+	case PartBody:
+		return resp.Body
+	}
+	return ""
+}
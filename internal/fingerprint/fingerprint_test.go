@@ -0,0 +1,75 @@
+package fingerprint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := os.CreateTemp("", "fingerprints.yaml")
+	assert.NoError(err)
+	_, err = f.WriteString(`- id: nginx
+  name: Nginx
+  matchers:
+    - server: nginx
+  versions:
+    - part: server
+      regex: 'nginx/([\d.]+)'
+
+- id: thinkphp
+  name: ThinkPHP
+  matchers:
+    - header: "X-Powered-By: ThinkPHP"
+    - favicon: [1165838194]
+
+- id: tomcat
+  name: Apache Tomcat
+  matchers:
+    - status: 404
+      title: Apache Tomcat
+  versions:
+    - part: title
+      regex: 'Tomcat/[\d.]+'`)
+	assert.NoError(err)
+
+	filename := f.Name()
+	defer os.Remove(filename)
+	f.Close()
+
+	db, err := New(filename)
+	assert.NoError(err)
+	assert.Equal(3, db.Len())
+
+	matches := db.Match(&Response{StatusCode: 200, Server: "nginx/1.18.0", Header: "Server: nginx/1.18.0\r\nX-Powered-By: ThinkPHP\r\n"})
+	if assert.Len(matches, 2) {
+		assert.Equal("nginx", matches[0].ID)
+		assert.Equal("1.18.0", matches[0].Version)
+		assert.Equal("Nginx/1.18.0", matches[0].String())
+		assert.Equal("thinkphp", matches[1].ID)
+		assert.Equal("ThinkPHP", matches[1].String())
+	}
+
+	matches = db.Match(&Response{StatusCode: 200, FaviconHash: 1165838194, HasFavicon: true})
+	if assert.Len(matches, 1) {
+		assert.Equal("thinkphp", matches[0].ID)
+	}
+
+	// 所有条件均满足才命中
+	assert.Empty(db.Match(&Response{StatusCode: 200, Title: "Apache Tomcat/9.0.1"}))
+	matches = db.Match(&Response{StatusCode: 404, Title: "HTTP Status 404 – Not Found - apache tomcat/9.0.1"})
+	if assert.Len(matches, 1) {
+		assert.Equal("", matches[0].Version)
+	}
+	matches = db.Match(&Response{StatusCode: 404, Title: "Apache Tomcat/9.0.1"})
+	if assert.Len(matches, 1) {
+		assert.Equal("Tomcat/9.0.1", matches[0].Version)
+	}
+
+	db, err = New("")
+	assert.NoError(err)
+	assert.Empty(db.Match(&Response{Server: "nginx"}))
+}
@@ -30,6 +30,22 @@ func NewJobFlag(jobs *[]types.JobOptions) *JobFlag {
 	flagSet.String("e", defaultOptions.Jobs[0].Timeout, "任务超时时间")
 	flagSet.Int("r", defaultOptions.Jobs[0].RateLimit, "任务执行频率")
 	flagSet.Int("c", defaultOptions.Jobs[0].Concurrency, "任务并发数")
+	flagSet.String("i", "", "任务输入(targets,hosts,ports,job:<任务名称>)")
+	flagSet.String("g", "", "按模板标签过滤来源任务命中的目标(逗号分隔)")
+	flagSet.String("p", "", "按模板ID过滤来源任务命中的目标(逗号分隔)")
+	flagSet.String("f", "", "任务链指纹任务名称(按其识别结果选择模板)")
+	flagSet.String("tags", "", "包含模板标签(逗号分隔)")
+	flagSet.String("etags", "", "排除模板标签(逗号分隔)")
+	flagSet.String("severity", "", "包含严重程度(逗号分隔)")
+	flagSet.String("eseverity", "", "排除严重程度(逗号分隔)")
+	flagSet.String("id", "", "包含模板ID(逗号分隔)")
+	flagSet.String("eid", "", "排除模板ID(逗号分隔)")
+	flagSet.String("author", "", "包含模板作者(逗号分隔)")
+	flagSet.String("eauthor", "", "排除模板作者(逗号分隔)")
+	flagSet.String("type", "", "包含协议类型(逗号分隔)")
+	flagSet.String("etype", "", "排除协议类型(逗号分隔)")
+	flagSet.String("path", "", "包含模板路径glob(逗号分隔)")
+	flagSet.String("epath", "", "排除模板路径glob(逗号分隔)")
 
 	// 设置flag set输出到buf中
 	buf := &bytes.Buffer{}
@@ -57,6 +73,11 @@ func (f *JobFlag) Set(v string) error {
 		}
 	}
 
+	// 重置为默认值，避免沿用上一个任务的参数
+	f.flagSet.VisitAll(func(f *flag.Flag) {
+		f.Value.Set(f.DefValue)
+	})
+
 	if err := f.flagSet.Parse(args); err != nil {
 		return fmt.Errorf("invalid job args: %w", err)
 	}
@@ -93,12 +114,52 @@ func (f *JobFlag) Set(v string) error {
 			if err == nil {
 				j.Concurrency = v
 			}
+		case "i":
+			j.Input.From, j.Input.Job, _ = strings.Cut(f.Value.String(), ":")
+		case "g":
+			j.Input.Tags = splitList(f.Value.String())
+		case "p":
+			j.Input.Templates = splitList(f.Value.String())
+		case "f":
+			j.Chain.Job = f.Value.String()
+		case "tags":
+			j.Filter.Tags = splitList(f.Value.String())
+		case "etags":
+			j.Filter.ExcludeTags = splitList(f.Value.String())
+		case "severity":
+			j.Filter.Severities = splitList(f.Value.String())
+		case "eseverity":
+			j.Filter.ExcludeSeverities = splitList(f.Value.String())
+		case "id":
+			j.Filter.IDs = splitList(f.Value.String())
+		case "eid":
+			j.Filter.ExcludeIDs = splitList(f.Value.String())
+		case "author":
+			j.Filter.Authors = splitList(f.Value.String())
+		case "eauthor":
+			j.Filter.ExcludeAuthors = splitList(f.Value.String())
+		case "type":
+			j.Filter.Protocols = splitList(f.Value.String())
+		case "etype":
+			j.Filter.ExcludeProtocols = splitList(f.Value.String())
+		case "path":
+			j.Filter.Paths = splitList(f.Value.String())
+		case "epath":
+			j.Filter.ExcludePaths = splitList(f.Value.String())
 		}
 	})
 	*f.jobs = append(*f.jobs, j)
 	return nil
 }
 
+// splitList 拆分逗号分隔的列表
+func splitList(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
 func (f *JobFlag) Type() string {
 	return "goflag"
 }
@@ -32,4 +32,30 @@ func Test(t *testing.T) {
 		RateLimit:   2000,
 		Concurrency: 2000,
 	})
+
+	err = jobFlag.Set("-m weak -t ./templates/弱口令 -i job:demo -g ssh,ftp -p ssh-detect")
+	assert.NoError(err)
+
+	assert.Equal((*jobFlag.jobs)[1].Input, types.JobInputOptions{
+		From:      "job",
+		Job:       "demo",
+		Tags:      []string{"ssh", "ftp"},
+		Templates: []string{"ssh-detect"},
+	})
+
+	err = jobFlag.Set("-m vuln -t ./templates/漏洞扫描 -f demo")
+	assert.NoError(err)
+	assert.Equal((*jobFlag.jobs)[2].Chain.Job, "demo")
+	// 不沿用上一个任务的参数
+	assert.Empty((*jobFlag.jobs)[2].Input)
+
+	err = jobFlag.Set("-m cve -t ./templates/漏洞扫描 -tags cve,rce -etags dos -severity high,critical -type http -epath fuzzing/*")
+	assert.NoError(err)
+	assert.Equal((*jobFlag.jobs)[3].Filter, types.TemplateFilterOptions{
+		Tags:         []string{"cve", "rce"},
+		ExcludeTags:  []string{"dos"},
+		Severities:   []string{"high", "critical"},
+		Protocols:    []string{"http"},
+		ExcludePaths: []string{"fuzzing/*"},
+	})
 }
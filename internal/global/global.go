@@ -14,7 +14,9 @@ import (
 	"github.com/projectdiscovery/goflags"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/gologger/levels"
+	"github.com/projectdiscovery/nuclei/v3/pkg/output"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/interactsh"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/protocolinit"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/protocolstate"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/headless/engine"
@@ -108,11 +110,6 @@ func ExecutorOptions() *protocols.ExecutorOptions {
 	output := &fakeWriter{}
 	progress := &fakeProgress{}
 
-	// interactshClient, err := interactsh.New(interactsh.DefaultOptions(output, nil, progress))
-	// if err != nil {
-	// 	return fmt.Errorf("create interactsh client failed: %w", err)
-	// }
-
 	eOptions := &protocols.ExecutorOptions{
 		Parser:  templates.NewParser(),
 		Options: tOptions,
@@ -122,12 +119,20 @@ func ExecutorOptions() *protocols.ExecutorOptions {
 		Output:      output,
 		DoNotCache:  true,
 		// Browser:     browser,
-		// Interactsh:  interactshClient,
 	}
 
 	return eOptions
 }
 
+// InteractshOptions 获取interactsh客户端选项(非单例)
+//
+// 不使用公共的oast服务，服务地址需由业务侧配置；交互命中的结果写入output
+func InteractshOptions(output output.Writer) *interactsh.Options {
+	opts := interactsh.DefaultOptions(output, nil, &fakeProgress{})
+	opts.ServerURL = ""
+	return opts
+}
+
 func Browser() (*engine.Browser, error) {
 	// 初始化浏览器
 	browser, err := engine.New(tOptions)
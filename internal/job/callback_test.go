@@ -0,0 +1,80 @@
+package job
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/global"
+	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	ptarget "github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemCallback(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	target := strings.TrimPrefix(server.URL, "http://")
+
+	global.Init()
+
+	vm, err := vuln.New("")
+	assert.NoError(err)
+
+	var (
+		m     sync.Mutex
+		items []*types.JobResultItem
+	)
+	j, err := NewJob(
+		WithName("stream"),
+		WithGetTemplates(func() []*types.RawTemplate {
+			return []*types.RawTemplate{{ID: "t1", Original: `id: t1
+
+info:
+  name: t1
+  author: falcon
+  severity: info
+
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/t1"
+    matchers:
+      - type: word
+        words:
+          - "ok"
+`}}
+		}),
+		WithConcurrency(1),
+		WithRateLimit(10),
+		WithExportFormat("console"),
+		WithTimeout("5s"),
+		WithRetries(1),
+		WithSilent(true),
+		WithVulnMapper(vm),
+		WithEntryID("entry"),
+		WithItemCallback(func(_ context.Context, item *types.JobResultItem) {
+			m.Lock()
+			items = append(items, item)
+			m.Unlock()
+		}),
+	)
+	assert.NoError(err)
+	assert.NoError(j.LoadTemplates(global.ExecutorOptions()))
+	assert.NoError(j.ExecuteWithContext(context.Background(), &Options{Targets: ptarget.Slice{target}, Seed: 1}))
+
+	// 命中结果实时回调，未配置结果回调时不累积
+	if assert.Len(items, 1) {
+		assert.Equal("t1", items[0].TemplateID)
+		assert.Equal("entry", items[0].EntryID)
+	}
+	assert.Empty(j.cbResults)
+}
@@ -0,0 +1,109 @@
+package job
+
+import (
+	"net"
+	"strings"
+
+	"github.com/EscapeBearSecond/falcon/internal/tpl"
+	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+)
+
+// genericTags 不代表具体产品的通用标签，不用于选择模板
+var genericTags = map[string]struct{}{
+	"tech": {}, "detect": {}, "detection": {}, "fingerprint": {}, "favicon": {},
+	"panel": {}, "login": {}, "misc": {}, "discovery": {}, "exposure": {},
+	"config": {}, "network": {}, "service": {}, "http": {}, "https": {},
+	"tcp": {}, "udp": {}, "ssl": {}, "tls": {}, "dns": {}, "cve": {},
+}
+
+// productSuffixes 指纹模板ID中产品名称之后的常见后缀
+var productSuffixes = []string{"-detect", "-detection", "-fingerprint", "-version", "-panel", "-login"}
+
+// Fingerprints 主机识别出的产品关键字(指纹模板的标签及模板ID中的产品名称)
+type Fingerprints map[string]map[string]struct{}
+
+// NewFingerprints 由指纹任务的结果构建
+func NewFingerprints(items []*types.JobResultItem) Fingerprints {
+	fps := make(Fingerprints)
+	for _, item := range items {
+		fps.add(item.Host, item.TemplateID, tpl.SplitTags(item.Tags))
+	}
+	return fps
+}
+
+func (fps Fingerprints) add(host, templateID string, tags []string) {
+	keywords, ok := fps[host]
+	if !ok {
+		keywords = make(map[string]struct{})
+		fps[host] = keywords
+	}
+
+	for _, tag := range tags {
+		if _, generic := genericTags[tag]; !generic {
+			keywords[tag] = struct{}{}
+		}
+	}
+
+	id := strings.ToLower(templateID)
+	for _, suffix := range productSuffixes {
+		if product, found := strings.CutSuffix(id, suffix); found {
+			keywords[product] = struct{}{}
+			break
+		}
+	}
+}
+
+// Lookup 获取目标所在主机的产品关键字
+func (fps Fingerprints) Lookup(target string) map[string]struct{} {
+	host := target
+	if util.IsHostPort(target) {
+		host, _, _ = net.SplitHostPort(target)
+	}
+	return fps[host]
+}
+
+// Fingerprints 获取任务命中结果中的产品关键字(作为任务链的指纹任务)
+func (j *Job) Fingerprints() Fingerprints {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	fps := make(Fingerprints)
+	for _, m := range j.matches {
+		host := m.target
+		if util.IsHostPort(m.target) {
+			host, _, _ = net.SplitHostPort(m.target)
+		}
+		fps.add(host, m.templateID, m.tags)
+	}
+	return fps
+}
+
+// Chain 任务链选项
+func (j *Job) Chain() types.JobChainOptions {
+	return j.chain
+}
+
+// chained 是否按指纹任务的结果选择模板
+func (j *Job) chained() bool {
+	return j.chain.Job != "" || j.chain.Result != nil
+}
+
+// selected 判断模板是否适用于目标：模板标签包含目标所在主机识别出的产品关键字
+func (j *Job) selected(tags []string, target string) bool {
+	if !j.chained() {
+		return true
+	}
+
+	keywords := j.fingerprints.Lookup(target)
+	if len(keywords) == 0 {
+		return j.chain.Fallback
+	}
+
+	for _, tag := range tags {
+		if _, ok := keywords[tag]; ok {
+			return true
+		}
+	}
+	return false
+}
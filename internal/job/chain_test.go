@@ -0,0 +1,43 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprints(t *testing.T) {
+	assert := assert.New(t)
+
+	fps := NewFingerprints([]*types.JobResultItem{
+		{Host: "192.168.1.1", TemplateID: "nginx-detect", Tags: "tech, nginx"},
+		{Host: "192.168.1.1", TemplateID: "weblogic-detect", Tags: "tech,panel,oracle"},
+		{Host: "192.168.1.2", TemplateID: "openssh-version", Tags: "network,ssh"},
+	})
+
+	assert.Equal(map[string]struct{}{"nginx": {}, "weblogic": {}, "oracle": {}}, fps.Lookup("192.168.1.1:8080"))
+	assert.Equal(map[string]struct{}{"openssh": {}, "ssh": {}}, fps.Lookup("192.168.1.2"))
+	assert.Nil(fps.Lookup("192.168.1.3"))
+}
+
+func TestSelected(t *testing.T) {
+	assert := assert.New(t)
+
+	j := &Job{}
+	assert.True(j.selected([]string{"cve", "apache"}, "192.168.1.1"))
+
+	j.chain = types.JobChainOptions{Job: "资产识别"}
+	j.fingerprints = NewFingerprints([]*types.JobResultItem{
+		{Host: "192.168.1.1", TemplateID: "weblogic-detect", Tags: "tech,panel,oracle"},
+	})
+	assert.True(j.selected([]string{"cve", "weblogic", "rce"}, "192.168.1.1:7001"))
+	assert.False(j.selected([]string{"cve", "apache"}, "192.168.1.1:7001"))
+	// 通用标签不参与匹配
+	assert.False(j.selected([]string{"cve", "panel"}, "192.168.1.1:7001"))
+
+	// 未识别出指纹的主机
+	assert.False(j.selected([]string{"cve", "apache"}, "192.168.1.2"))
+	j.chain.Fallback = true
+	assert.True(j.selected([]string{"cve", "apache"}, "192.168.1.2"))
+}
@@ -0,0 +1,97 @@
+package job
+
+import (
+	"sync"
+
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+)
+
+// cursor 按执行顺序已完成的 模板×目标 数量
+//
+// 任务并发执行、完成顺序不固定，仅当之前的全部完成时前移，继续执行时跳过之前的部分
+type cursor struct {
+	m    sync.Mutex
+	next uint64
+	done map[uint64]struct{} // 已完成但之前仍有未完成的序号
+}
+
+func newCursor(start uint64) *cursor {
+	return &cursor{next: start, done: make(map[uint64]struct{})}
+}
+
+// complete 标记序号已完成
+func (c *cursor) complete(seq uint64) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if seq < c.next {
+		return
+	}
+	c.done[seq] = struct{}{}
+	for {
+		if _, ok := c.done[c.next]; !ok {
+			return
+		}
+		delete(c.done, c.next)
+		c.next++
+	}
+}
+
+func (c *cursor) value() uint64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.next
+}
+
+// Index 任务配置顺序
+func (j *Job) Index() int {
+	return j.index
+}
+
+// Completed 任务是否已完成(继续执行时由断点恢复)
+func (j *Job) Completed() bool {
+	return j.done.Load()
+}
+
+// Checkpoint 获取任务进度
+//
+// 中断时执行中的任务在继续执行时重新执行，其结果可能重复；流水线模式下目标顺序不固定，不记录执行位置
+func (j *Job) Checkpoint() *checkpoint.Job {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	cp := &checkpoint.Job{
+		Done:    j.done.Load(),
+		Matches: make([]*checkpoint.Match, 0, len(j.matches)),
+		Items:   append([]*types.JobResultItem(nil), j.cbResults...),
+	}
+	if !j.streamed.Load() {
+		cp.Cursor = j.cursor.value()
+	}
+	for _, m := range j.matches {
+		cp.Matches = append(cp.Matches, &checkpoint.Match{Target: m.target, TemplateID: m.templateID, Tags: m.tags})
+	}
+	return cp
+}
+
+// Restore 由断点恢复任务进度，需在执行前调用
+func (j *Job) Restore(cp *checkpoint.Job) {
+	if cp == nil {
+		return
+	}
+
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	j.done.Store(cp.Done)
+	j.cursor = newCursor(cp.Cursor)
+	for _, m := range cp.Matches {
+		j.matches = append(j.matches, &match{target: m.Target, templateID: m.TemplateID, tags: m.Tags})
+	}
+	if j.callback != nil {
+		for _, item := range cp.Items {
+			j.cbResults = append(j.cbResults, item.WithEntryID(j.entryID))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package job
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
+	"github.com/EscapeBearSecond/falcon/internal/global"
+	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	ptarget "github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	assert := assert.New(t)
+
+	c := newCursor(2)
+	// 之前的位置未完成时不前移
+	c.complete(3)
+	c.complete(5)
+	assert.Equal(uint64(2), c.value())
+	c.complete(2)
+	assert.Equal(uint64(4), c.value())
+	c.complete(1)
+	c.complete(4)
+	assert.Equal(uint64(6), c.value())
+}
+
+func TestExecuteWithCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	target := strings.TrimPrefix(server.URL, "http://")
+
+	global.Init()
+
+	vm, err := vuln.New("")
+	assert.NoError(err)
+
+	newJob := func(callback types.JobResultCallback) *Job {
+		j, err := NewJob(
+			WithName("checkpoint"),
+			WithGetTemplates(func() []*types.RawTemplate {
+				var templates []*types.RawTemplate
+				for _, id := range []string{"t1", "t2", "t3"} {
+					templates = append(templates, &types.RawTemplate{ID: id, Original: `id: ` + id + `
+
+info:
+  name: ` + id + `
+  author: falcon
+  severity: info
+
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/` + id + `"
+    matchers:
+      - type: word
+        words:
+          - "ok"
+`})
+				}
+				return templates
+			}),
+			WithConcurrency(1),
+			WithRateLimit(10),
+			WithExportFormat("console"),
+			WithTimeout("5s"),
+			WithRetries(1),
+			WithSilent(true),
+			WithVulnMapper(vm),
+			WithEntryID("entry"),
+			WithCallback(callback),
+		)
+		assert.NoError(err)
+		assert.NoError(j.LoadTemplates(global.ExecutorOptions()))
+		return j
+	}
+
+	// 由断点继续执行时跳过已完成的位置，保留之前的结果
+	var result *types.JobResult
+	j := newJob(func(_ context.Context, r *types.JobResult) error {
+		result = r
+		return nil
+	})
+	j.Restore(&checkpoint.Job{
+		Cursor:  2,
+		Matches: []*checkpoint.Match{{Target: target, TemplateID: "t1"}},
+		Items:   []*types.JobResultItem{{TemplateID: "t1"}},
+	})
+	assert.NoError(j.ExecuteWithContext(context.Background(), &Options{Targets: ptarget.Slice{target}, Seed: 1}))
+	assert.Equal(int64(1), requests.Load())
+	if assert.NotNil(result) && assert.Len(result.Items, 2) {
+		assert.Equal("entry", result.Items[0].EntryID)
+	}
+
+	cp := j.Checkpoint()
+	assert.True(cp.Done)
+	assert.Equal(uint64(3), cp.Cursor)
+	assert.Len(cp.Matches, 2)
+
+	// 已完成的任务只回调已有结果
+	result = nil
+	j = newJob(func(_ context.Context, r *types.JobResult) error {
+		result = r
+		return nil
+	})
+	j.Restore(cp)
+	assert.True(j.Completed())
+	assert.NoError(j.ExecuteWithContext(context.Background(), &Options{Targets: ptarget.Slice{target}, Seed: 1}))
+	assert.Equal(int64(1), requests.Load())
+	if assert.NotNil(result) {
+		assert.Len(result.Items, 2)
+	}
+}
@@ -0,0 +1,168 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/nuclei/v3/pkg/output"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/interactsh"
+)
+
+// oobBuffer 带外交互结果通道的容量
+const oobBuffer = 64
+
+var _ output.Writer = (*oobWriter)(nil)
+
+// oobWriter 接收interactsh客户端轮询到交互后异步命中的结果
+type oobWriter struct {
+	m       sync.Mutex
+	closed  bool
+	results chan *output.ResultEvent
+}
+
+func newOOBWriter() *oobWriter {
+	return &oobWriter{results: make(chan *output.ResultEvent, oobBuffer)}
+}
+
+func (w *oobWriter) Write(event *output.ResultEvent) error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	// 任务结束后到达的交互丢弃
+	if !w.closed {
+		w.results <- event
+	}
+	return nil
+}
+
+// Close 关闭结果通道，由任务在interactsh客户端停止轮询后调用
+func (w *oobWriter) Close() {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if !w.closed {
+		w.closed = true
+		close(w.results)
+	}
+}
+
+func (w *oobWriter) Colorizer() aurora.Aurora                                            { return aurora.NewAurora(false) }
+func (w *oobWriter) WriteFailure(event *output.InternalWrappedEvent) error               { return nil }
+func (w *oobWriter) Request(templateID, url, requestType string, err error)              {}
+func (w *oobWriter) WriteStoreDebugData(host, templateID, eventType string, data string) {}
+
+// SetInteractsh 配置interactsh客户端选项(为nil时不启用带外交互)，需在加载模板前配置
+func (j *Job) SetInteractsh(opts *interactsh.Options) {
+	j.iOptions = opts
+}
+
+// loadInteractsh 创建任务独立的interactsh客户端，交互命中的结果只写入当前任务
+func (j *Job) loadInteractsh(eOptions *protocols.ExecutorOptions) error {
+	eOptions.Interactsh = nil
+	if j.iOptions == nil {
+		return nil
+	}
+
+	opts := *j.iOptions
+	j.oob = newOOBWriter()
+	opts.Output = j.oob
+	client, err := interactsh.New(&opts)
+	if err != nil {
+		return fmt.Errorf("job [%s] create interactsh client failed: %w", j.name, err)
+	}
+	j.interactsh = client
+	eOptions.Interactsh = client
+	return nil
+}
+
+// startInteractsh 处理带外交互命中的结果，返回的函数等待交互(冷却时间)后停止轮询并处理剩余结果
+func (j *Job) startInteractsh(c context.Context) func() {
+	if j.interactsh == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range j.oob.results {
+			if c.Err() != nil {
+				continue
+			}
+			target := j.oobTarget(result)
+			if j.outLogger != nil {
+				j.outLogger.InfoContext(c, "Execute Task Interaction",
+					"job_name", j.name,
+					"template_id", result.TemplateID,
+					"type", result.Type,
+					"target", target,
+					"protocol", result.Interaction.Protocol,
+					"remote_address", result.Interaction.RemoteAddress,
+				)
+			}
+			j.processResult(c, result, target)
+		}
+	}()
+
+	return func() {
+		j.interactsh.Close()
+		j.oob.Close()
+		<-done
+	}
+}
+
+// trackInteractsh 记录发送了交互地址的请求主机与目标的对应关系，用于关联异步命中的结果
+func (j *Job) trackInteractsh(target string, inputs []*taskInput) {
+	for _, input := range inputs {
+		host, port := splitInput(input.input)
+		j.oobTargets.Store(oobKey(host, port), target)
+		j.oobTargets.Store(oobKey(host, ""), target)
+	}
+}
+
+// oobTarget 获取结果对应的目标，未记录时使用结果中的主机
+func (j *Job) oobTarget(result *output.ResultEvent) string {
+	host, port := splitInput(result.Host)
+	if result.Port != "" {
+		port = result.Port
+	}
+	for _, key := range []string{oobKey(host, port), oobKey(host, "")} {
+		if target, ok := j.oobTargets.Load(key); ok {
+			return target.(string)
+		}
+	}
+	return result.Host
+}
+
+// splitInput 拆分url或host[:port]输入中的主机与端口，url未指定端口时使用scheme的默认端口
+func splitInput(input string) (string, string) {
+	if u, err := url.Parse(input); err == nil && u.Host != "" {
+		port := u.Port()
+		if port == "" {
+			switch u.Scheme {
+			case "http":
+				port = "80"
+			case "https":
+				port = "443"
+			}
+		}
+		return u.Hostname(), port
+	}
+	if host, port, err := net.SplitHostPort(input); err == nil {
+		return host, port
+	}
+	return strings.Trim(input, "[]"), ""
+}
+
+func oobKey(host, port string) string {
+	host = strings.ToLower(host)
+	if port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
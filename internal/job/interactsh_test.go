@@ -0,0 +1,99 @@
+package job
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/global"
+	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	ptarget "github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/internal/util/oob"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteWithInteractsh(t *testing.T) {
+	assert := assert.New(t)
+
+	s := oob.New(&oob.Config{Listen: "127.0.0.1:0"})
+	assert.NoError(s.Start())
+	defer s.Close()
+
+	// 盲SSRF：异步请求url参数中的地址，响应中不包含回连结果
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.URL.Query().Get("url")
+		go func() {
+			req, _ := http.NewRequest(http.MethodGet, s.URL(), nil)
+			req.Host = host
+			if resp, err := http.DefaultClient.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	global.Init()
+
+	vm, err := vuln.New("")
+	assert.NoError(err)
+
+	var result *types.JobResult
+	j, err := NewJob(
+		WithName("oob"),
+		WithGetTemplates(func() []*types.RawTemplate {
+			return []*types.RawTemplate{{ID: "blind-ssrf", Original: `id: blind-ssrf
+
+info:
+  name: Blind SSRF
+  author: falcon
+  severity: high
+
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/fetch?url={{interactsh-url}}"
+    matchers:
+      - type: word
+        part: interactsh_protocol
+        words:
+          - "http"
+`}}
+		}),
+		WithConcurrency(1),
+		WithRateLimit(10),
+		WithExportFormat("console"),
+		WithTimeout("5s"),
+		WithRetries(1),
+		WithSilent(true),
+		WithVulnMapper(vm),
+		WithCallback(func(_ context.Context, r *types.JobResult) error {
+			result = r
+			return nil
+		}),
+	)
+	assert.NoError(err)
+
+	// 未启用带外交互时跳过模板
+	assert.ErrorIs(j.LoadTemplates(global.ExecutorOptions()), types.ErrInvalidTemplates)
+
+	iOptions := global.InteractshOptions(nil)
+	iOptions.ServerURL = s.URL()
+	iOptions.PollDuration = 100 * time.Millisecond
+	iOptions.CooldownPeriod = time.Second
+	j.SetInteractsh(iOptions)
+	assert.NoError(j.LoadTemplates(global.ExecutorOptions()))
+
+	target := strings.TrimPrefix(server.URL, "http://")
+	assert.NoError(j.ExecuteWithContext(context.Background(), &Options{Targets: ptarget.Slice{target}}))
+
+	// 交互命中的结果关联到发送请求的任务及目标
+	if assert.NotNil(result) && assert.Len(result.Items, 1) {
+		assert.Equal("blind-ssrf", result.Items[0].TemplateID)
+	}
+	assert.Equal(ptarget.Slice{target}, j.Matched(nil, nil))
+}
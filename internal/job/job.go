@@ -8,6 +8,7 @@ import (
 	"net"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,10 +16,12 @@ import (
 	"github.com/EscapeBearSecond/falcon/internal/global"
 	"github.com/EscapeBearSecond/falcon/internal/mapper"
 	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
 	"github.com/EscapeBearSecond/falcon/internal/stage"
 	ptarget "github.com/EscapeBearSecond/falcon/internal/target"
 	"github.com/EscapeBearSecond/falcon/internal/tpl"
 	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/internal/util/cyclic"
 	"github.com/EscapeBearSecond/falcon/internal/util/log"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
 	"github.com/panjf2000/ants/v2"
@@ -26,8 +29,10 @@ import (
 	"github.com/projectdiscovery/nuclei/v3/pkg/output"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/contextargs"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/interactsh"
 	"github.com/projectdiscovery/nuclei/v3/pkg/scan"
 	"github.com/projectdiscovery/ratelimit"
+	"github.com/samber/lo"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -59,6 +64,7 @@ type Job struct {
 
 	logger    *slog.Logger
 	silent    bool
+	noBar     bool // 仅禁用进度条
 	outLogger *slog.Logger
 
 	m         sync.Mutex
@@ -66,6 +72,8 @@ type Job struct {
 	callback  types.JobResultCallback
 	entryID   string
 
+	itemCallback types.JobItemCallback // 命中结果实时回调
+
 	enableHeadless     bool
 	skipHeadlessSize   int
 	skipHeadlessReason string
@@ -75,8 +83,41 @@ type Job struct {
 
 	stageManager *stage.Manager
 	index        int
+	pause        *pause.Controller // 暂停控制
+
+	hostnames ptarget.Hostnames
+	services  ptarget.Services
+	sm        sync.RWMutex // 流水线模式下services随目标写入
 
+	total     *atomic.Int64
 	completed *atomic.Int64
+
+	input   types.JobInputOptions
+	matches []*match // 命中的目标(供后续任务作为输入)
+
+	chain        types.JobChainOptions
+	fingerprints Fingerprints // 任务链模式下各主机识别出的产品关键字
+
+	filter types.TemplateFilterOptions // 模板过滤
+
+	iOptions   *interactsh.Options // interactsh客户端选项(为nil时不启用带外交互)
+	interactsh *interactsh.Client
+	oob        *oobWriter
+	oobTargets sync.Map // 发送交互地址的请求主机(host[:port]) -> 目标
+
+	skipInteractshSize   int
+	skipInteractshReason string
+
+	cursor   *cursor     // 按执行顺序已完成的 模板×目标 数量(用于断点恢复)
+	streamed atomic.Bool // 流水线模式
+	done     atomic.Bool // 已完成
+}
+
+// match 命中模板的目标
+type match struct {
+	target     string
+	templateID string
+	tags       []string
 }
 
 func (j *Job) Name() string {
@@ -87,6 +128,36 @@ func (j *Job) SetName(name string) {
 	j.name = name
 }
 
+// Input 任务输入
+func (j *Job) Input() types.JobInputOptions {
+	return j.input
+}
+
+// Matched 获取命中的目标，tags与templates分别为模板标签与模板ID过滤条件(为空则不过滤)
+func (j *Job) Matched(tags, templates []string) ptarget.Source {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	targets := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, m := range j.matches {
+		if len(templates) != 0 && !lo.Contains(templates, m.templateID) {
+			continue
+		}
+		if len(tags) != 0 && !lo.SomeBy(tags, func(tag string) bool {
+			return lo.ContainsBy(m.tags, func(t string) bool { return strings.EqualFold(t, tag) })
+		}) {
+			continue
+		}
+		if _, contained := seen[m.target]; contained {
+			continue
+		}
+		seen[m.target] = struct{}{}
+		targets = append(targets, m.target)
+	}
+	return ptarget.Slice(targets)
+}
+
 // New 实例化任务（一组模版的扫描行为）
 func NewJob(opts ...Option) (*Job, error) {
 	j := &Job{
@@ -120,6 +191,23 @@ func (j *Job) init() error {
 		j.name = util.RandomStr(10)
 	}
 
+	switch j.input.From {
+	case "", types.JobInputTargets, types.JobInputHosts, types.JobInputPorts:
+		if j.input.Job != "" || len(j.input.Tags) != 0 || len(j.input.Templates) != 0 {
+			return fmt.Errorf("%w: job, tags and templates require from %q", types.ErrInvalidJobInput, types.JobInputJob)
+		}
+	case types.JobInputJob:
+		if j.input.Job == "" {
+			return fmt.Errorf("%w: source job is not specified", types.ErrInvalidJobInput)
+		}
+	default:
+		return fmt.Errorf("%w: unsupport from %q", types.ErrInvalidJobInput, j.input.From)
+	}
+
+	if j.chain.Job != "" && j.chain.Result != nil {
+		return fmt.Errorf("%w: chain job and result are mutually exclusive", types.ErrInvalidJobInput)
+	}
+
 	if j.silent {
 		j.logger = log.Must(log.NewLogger(log.WithSilent(true)))
 	} else {
@@ -149,6 +237,10 @@ func (j *Job) init() error {
 		pool, err := ants.NewPoolWithFunc(j.c, func(i interface{}) {
 			task := i.(*task)
 			j.executePOCForTarget(task.c, task.poc, task.input)
+			// 中断的任务不计入进度，继续执行时重新执行
+			if task.c.Err() == nil {
+				j.cursor.complete(task.seq)
+			}
 		})
 		if err != nil {
 			return fmt.Errorf("create job [%s] routine pool failed: %w", j.name, err)
@@ -156,7 +248,9 @@ func (j *Job) init() error {
 		j.pool = pool
 	}
 
+	j.total = &atomic.Int64{}
 	j.completed = &atomic.Int64{}
+	j.cursor = newCursor(0)
 
 	return nil
 }
@@ -166,15 +260,22 @@ func (j *Job) LoadTemplates(eOptions *protocols.ExecutorOptions) error {
 	var result *tpl.Result
 	var err error
 
+	// 各任务使用独立的interactsh客户端，未启用时清空
+	if err := j.loadInteractsh(eOptions); err != nil {
+		return err
+	}
+
 	// 为execute options配置模版地址
 	eOptions.Catalog = disk.NewCatalog(j.template)
 	// 如果模版地址不为空，则使用文件路径中的模板
 	if j.template != "" {
 		result, err = tpl.LoadWithFileWalk(j.template, eOptions,
-			tpl.WithEnableHeadless(j.enableHeadless))
+			tpl.WithEnableHeadless(j.enableHeadless),
+			tpl.WithFilter(j.filter))
 	} else { // 否则使用getTemplates获取原始字符串模板（由于不可都为空）
 		result, err = tpl.LoadWithFunc(j.getTemplates, eOptions,
-			tpl.WithEnableHeadless(j.enableHeadless))
+			tpl.WithEnableHeadless(j.enableHeadless),
+			tpl.WithFilter(j.filter))
 	}
 	if err != nil {
 		return fmt.Errorf("job [%s] templates load failed: %w", j.name, err)
@@ -186,18 +287,30 @@ func (j *Job) LoadTemplates(eOptions *protocols.ExecutorOptions) error {
 
 	j.skipHeadlessSize = result.SkipHeadlessSize
 	j.skipHeadlessReason = result.SkipHeadlessReason
+	j.skipInteractshSize = result.SkipInteractshSize
+	j.skipInteractshReason = result.SkipInteractshReason
 	j.pocs = result.Pocs
 	return nil
 }
 
 // ExecuteWithContext 执行
 func (j *Job) ExecuteWithContext(c context.Context, o *Options) error {
+	// 由断点恢复的已完成任务只回调已有结果
+	if j.done.Load() {
+		j.close()
+		j.logger.InfoContext(c, "Execute job already completed")
+		j.stageManager.Put(types.StageJob, 1, j.stageEntries()...)
+		j.doCallback(c)
+		return nil
+	}
+
 	j.logger.InfoContext(c, "Execute job start")
 
 	err := j.executeWithContext(c, o)
 	if err != nil {
 		return err
 	}
+	j.done.Store(c.Err() == nil)
 	j.logger.InfoContext(c, "Execute job end")
 
 	j.doCallback(c)
@@ -224,6 +337,7 @@ func (j *Job) executeWithContext(c context.Context, o *Options) error {
 	defer j.close()
 
 	if j.skipHeadlessSize > 0 {
+		j.stageManager.Warn(types.StageJob, fmt.Sprintf("skip %d headless templates: %s", j.skipHeadlessSize, j.skipHeadlessReason), j.stageEntries()...)
 		if len(j.pocs) == 0 {
 			j.logger.InfoContext(c, "No Remaining Templates",
 				"skip_headless_size", j.skipHeadlessSize,
@@ -237,54 +351,118 @@ func (j *Job) executeWithContext(c context.Context, o *Options) error {
 		)
 	}
 
+	if j.skipInteractshSize > 0 {
+		j.stageManager.Warn(types.StageJob, fmt.Sprintf("skip %d interactsh templates: %s", j.skipInteractshSize, j.skipInteractshReason), j.stageEntries()...)
+		j.logger.InfoContext(c, "Skip Interactsh Templates",
+			"skip_interactsh_size", j.skipInteractshSize,
+			"skip_interactsh_reason", j.skipInteractshReason,
+		)
+	}
+
 	if len(j.pocs) == 0 {
 		return fmt.Errorf("job [%s] pocs are empty", j.name)
 	}
 
-	total := int64(len(j.pocs)) * int64(len(o.Targets))
-	// 进度条
-	j.bar = util.NewProgressbar(j.name, total, j.silent)
+	j.hostnames = o.Hostnames
+	j.services = o.Services
+	j.fingerprints = o.Fingerprints
+	if j.chain.Result != nil {
+		j.fingerprints = NewFingerprints(j.chain.Result.Items)
+	}
+
+	// 进度条(流水线模式下目标数量未知，随接收的目标增加)
+	if o.Input != nil {
+		j.streamed.Store(true)
+		if j.services == nil {
+			j.services = make(ptarget.Services)
+		}
+		j.bar = util.NewProgressbar(j.name, -1, j.silent || j.noBar)
+	} else {
+		j.total.Store(int64(len(j.pocs)) * int64(o.Targets.Size()))
+		j.bar = util.NewProgressbar(j.name, j.total.Load(), j.silent || j.noBar)
+	}
 
 	ok := make(chan struct{})
 	defer close(ok)
 	go j.progress(c, ok)
 
+	// 带外交互的结果在任务结束前(等待冷却时间)处理
+	stopInteractsh := j.startInteractsh(c)
+	defer stopInteractsh()
+
+	pocTimeouts := make([]time.Duration, 0, len(j.pocs))
+	pocPorts := make([][]string, 0, len(j.pocs))
+	pocTags := make([][]string, 0, len(j.pocs))
 	for _, poc := range j.pocs {
+		pocTags = append(pocTags, poc.GetTags())
+		pocTimeout := j.duration
+		if len(poc.RequestsJavascript) > 0 {
+			pocTimeout = j.duration * 5
+		}
+		// 工作流按子模板数量累计超时
+		if size := poc.WorkflowSize(); size > 0 {
+			pocTimeout = j.duration * time.Duration(size)
+		}
+		pocTimeouts = append(pocTimeouts, pocTimeout)
+		pocPorts = append(pocPorts, poc.GetPorts())
+	}
+
+	// 继续执行时跳过之前已完成的部分(流水线模式下目标顺序不固定，从头执行)
+	var seq, resumed uint64
+	if o.Input == nil {
+		resumed = j.cursor.value()
+	}
+	invoke := func(idx uint64, target string) error {
 		select {
 		case <-c.Done():
 			return context.Canceled
 		default:
 		}
 
-		pocTimeout := j.duration
-		if len(poc.RequestsJavascript) > 0 {
-			pocTimeout = j.duration * 5
+		n := seq
+		seq++
+		if n < resumed {
+			j.completed.Add(1)
+			return nil
 		}
 
-		ports := poc.GetPorts()
+		if ptarget.ShouldSkip(target, pocPorts[idx]...) || !j.selected(pocTags[idx], target) {
+			j.completed.Add(1)
+			j.cursor.complete(n)
+			return nil
+		}
 
-		for _, target := range o.Targets {
-			select {
-			case <-c.Done():
-				return context.Canceled
-			default:
-			}
+		// 暂停时等待继续
+		if err := j.pause.Wait(c); err != nil {
+			return err
+		}
 
-			if ptarget.ShouldSkip(target, ports...) {
-				j.completed.Add(1)
-				continue
-			}
+		j.wg.Add(1)
+		j.ratelimit.Take()
 
-			j.wg.Add(1)
-			j.ratelimit.Take()
+		j.pool.Invoke(
+			newTask(
+				context.WithValue(c, pocTimeoutKey, pocTimeouts[idx]),
+				j.pocs[idx],
+				target,
+				n,
+			),
+		)
+		return nil
+	}
 
-			j.pool.Invoke(
-				newTask(
-					context.WithValue(c, pocTimeoutKey, pocTimeout),
-					poc,
-					target,
-				),
-			)
+	if o.Input != nil {
+		if err := j.executeStream(c, o, invoke); err != nil {
+			return err
+		}
+	} else {
+		// 按随机排列遍历 模板×目标，避免短时间内集中请求同一主机
+		size := o.Targets.Size()
+		perm := cyclic.New(uint64(len(j.pocs))*size, o.Seed)
+		for i, more := perm.Next(); more; i, more = perm.Next() {
+			if err := invoke(i/size, o.Targets.At(i%size)); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -293,6 +471,37 @@ func (j *Job) executeWithContext(c context.Context, o *Options) error {
 	return nil
 }
 
+// executeStream 流水线模式：逐个接收上游发现的目标，按随机顺序执行所有模板
+func (j *Job) executeStream(c context.Context, o *Options, invoke func(idx uint64, target string) error) error {
+	size := uint64(len(j.pocs))
+	for n := int64(0); ; n++ {
+		var item ptarget.Item
+		var more bool
+		select {
+		case <-c.Done():
+			return context.Canceled
+		case item, more = <-o.Input:
+		}
+		if !more {
+			return nil
+		}
+
+		if item.Service.Name != "" {
+			j.sm.Lock()
+			j.services[item.Target] = item.Service
+			j.sm.Unlock()
+		}
+
+		j.total.Add(int64(size))
+		perm := cyclic.New(size, o.Seed+n)
+		for idx, more := perm.Next(); more; idx, more = perm.Next() {
+			if err := invoke(idx, item.Target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (j *Job) progress(c context.Context, ok <-chan struct{}) {
 	ticker := time.NewTicker(10 * time.Millisecond)
 	defer ticker.Stop()
@@ -307,15 +516,37 @@ func (j *Job) progress(c context.Context, ok <-chan struct{}) {
 			return
 		case <-ticker.C:
 			j.bar.Set64(j.completed.Load())
-			j.stageManager.Put(types.StageJob, j.bar.State().CurrentPercent, j.stageEntries()...)
+			j.stageManager.Put(types.StageJob, j.percent(), j.stageEntries()...)
 		}
 	}
 }
 
+// Adjust 运行中调整速率(每秒)与并发数，小于等于0的值不调整
+//
+// 限流器周期固定为1秒，从下个周期起按新速率发放令牌；goroutine池扩容立即生效，缩容在执行中的任务完成后生效
+func (j *Job) Adjust(rate, concurrency int) {
+	if rate > 0 {
+		j.ratelimit.SetLimit(uint(rate))
+	}
+	if concurrency > 0 {
+		j.pool.Tune(concurrency)
+	}
+}
+
+// percent 计算进度，总数未知(流水线模式尚未接收目标)时为0
+func (j *Job) percent() float64 {
+	total := j.total.Load()
+	if total <= 0 {
+		return 0
+	}
+	return min(float64(j.completed.Load())/float64(total), 1)
+}
+
 func (j *Job) stageEntries() []stage.Entry {
 	entries := []stage.Entry{
 		stage.NewEntry(types.StageEntryJobKind, j.kind),
 		stage.NewEntry(types.StageEntryJobIndex, j.index),
+		stage.NewEntry(types.StageEntryJobName, j.name),
 	}
 	return entries
 }
@@ -333,20 +564,31 @@ func (j *Job) executePOCForTarget(c context.Context, poc *tpl.POC, input string)
 					"reason", r,
 				)
 			}
+			j.stageManager.Error(types.StageJob, fmt.Sprintf("template [%s] target [%s] panic: %v", poc.ID, input, r), j.stageEntries()...)
 		}
 	}()
 
 	defer j.wg.Done()
 	defer j.completed.Add(1)
 
-	inputs := make([]string, 0, 2)
+	inputs := make([]*taskInput, 0, 2)
 	// 执行http预处理
-	if len(poc.RequestsHTTP) > 0 || len(poc.RequestsHeadless) > 0 {
-		schemes := make([]string, 0)
+	if poc.UsesHTTP() {
+		host, port := input, ""
+		if util.IsHostPort(input) {
+			host, port, _ = net.SplitHostPort(input)
+		}
+
+		// 优先使用端口扫描识别的服务，已识别为非HTTP服务的端口不执行http模板
+		j.sm.RLock()
+		schemes := j.services.Schemes(input)
+		j.sm.RUnlock()
+		if schemes != nil && len(schemes) == 0 {
+			return
+		}
 
 		// 如果input包含:80和:443的端口，则使用对应的scheme
-		if util.IsHostPort(input) {
-			_, port, _ := net.SplitHostPort(input)
+		if len(schemes) == 0 && port != "" {
 			switch port {
 			case "80":
 				schemes = append(schemes, "http")
@@ -366,13 +608,34 @@ func (j *Job) executePOCForTarget(c context.Context, poc *tpl.POC, input string)
 			schemes = append(schemes, "http", "https")
 		}
 
+		// 如果ip存在解析前的域名，则使用域名作为Host/SNI，并指定连接的ip
+		addrs := make([]string, 0, 1)
+		customIP := ""
+		if hostnames := j.hostnames.Lookup(host); len(hostnames) != 0 {
+			customIP = host
+			for _, hostname := range hostnames {
+				addrs = append(addrs, lo.If(port != "", net.JoinHostPort(hostname, port)).Else(hostname))
+			}
+		} else {
+			// IPv6地址需添加方括号
+			addrs = append(addrs, lo.If(port != "", input).Else(util.FormatHost(input)))
+		}
+
 		// 格式化url输入
 		for _, scheme := range schemes {
-			formedURL := fmt.Sprintf("%s://%s", scheme, input)
-			inputs = append(inputs, formedURL)
+			for _, addr := range addrs {
+				formedURL := fmt.Sprintf("%s://%s", scheme, addr)
+				inputs = append(inputs, &taskInput{input: formedURL, customIP: customIP})
+			}
 		}
 	} else {
-		inputs = append(inputs, input)
+		inputs = append(inputs, &taskInput{input: input})
+	}
+
+	// 带外交互的结果异步到达，记录请求主机以关联到当前目标
+	oob := j.interactsh != nil && poc.UsesInteractsh()
+	if oob {
+		j.trackInteractsh(input, inputs)
 	}
 
 	timeout := c.Value(pocTimeoutKey).(time.Duration)
@@ -400,15 +663,22 @@ retry:
 
 				var ctxErrors []error
 
-				scanContext := scan.NewScanContext(cc,
-					contextargs.NewWithInput(cc, input))
+				ctxArgs := contextargs.NewWithInput(cc, input.input)
+				ctxArgs.MetaInput.CustomIP = input.customIP
+				scanContext := scan.NewScanContext(cc, ctxArgs)
 				scanContext.OnResult = func(event *output.InternalWrappedEvent) {}
 				scanContext.OnError = func(err error) {
 					ctxErrors = append(ctxErrors, err)
 				}
-				results, err := poc.Executer.ExecuteWithResults(
-					scanContext,
-				)
+				var results []*output.ResultEvent
+				var err error
+				if poc.IsWorkflow() {
+					results = executeWorkflow(scanContext, poc.CompiledWorkflow)
+				} else {
+					results, err = poc.Executer.ExecuteWithResults(
+						scanContext,
+					)
+				}
 				// 1.判断execute是否有错，有错则返回错误
 				if err != nil {
 					return nil, err
@@ -421,7 +691,11 @@ retry:
 				if len(ctxErrors) != 0 {
 					return nil, util.JoinErrors(ctxErrors)
 				}
-				// 4.如果上下文错误也为空，返回未知错误
+				// 4.需要带外交互的模板等待交互，不重试(避免重复发送交互地址)
+				if oob {
+					return nil, nil
+				}
+				// 5.如果上下文错误也为空，返回未知错误
 				return nil, errors.New("unknown internal error")
 			}()
 
@@ -447,6 +721,7 @@ retry:
 				"reason", err.Error(),
 			)
 		}
+		j.stageManager.Error(types.StageJob, fmt.Sprintf("template [%s] target [%s] failed: %s", poc.ID, input, err), j.stageEntries()...)
 		return
 	}
 
@@ -467,49 +742,59 @@ retry:
 			}
 			exists[result.TemplateID] = true
 
+			j.processResult(c, result, input)
+		} else {
 			if j.outLogger != nil {
-				j.outLogger.InfoContext(c, "Execute Task Result Exist",
+				j.outLogger.WarnContext(c, "Execute Task Result Empty",
 					"job_name", j.name,
-					"template_id", result.TemplateID,
-					"type", result.Type,
+					"template_id", poc.ID,
+					"type", poc.Type().String(),
 					"target", input,
 				)
 			}
+		}
+	}
+}
 
-			dests := []mapper.Dest{}
-			// mappings
-			{
-				vulnMappings, err := j.vulnMapper.Get(result.TemplateID).By(result.ExtractedResults...)
-				if err != nil {
-					if j.outLogger != nil {
-						j.outLogger.WarnContext(c, "Get Vulnerability Mappings Failed",
-							"job_name", j.name,
-							"template_id", result.TemplateID,
-							"version", result.ExtractedResults[0],
-							"reason", err.Error(),
-						)
-					}
-					continue
-				}
+// processResult 处理命中结果(记录命中目标、映射漏洞并输出)
+func (j *Job) processResult(c context.Context, result *output.ResultEvent, target string) {
+	j.m.Lock()
+	j.matches = append(j.matches, &match{target: target, templateID: result.TemplateID, tags: tpl.SplitTags(result.Info.Tags.ToSlice()...)})
+	j.m.Unlock()
 
-				dests = append(dests, vulnMappings...)
-			}
+	if j.outLogger != nil {
+		j.outLogger.InfoContext(c, "Execute Task Result Exist",
+			"job_name", j.name,
+			"template_id", result.TemplateID,
+			"type", result.Type,
+			"target", target,
+		)
+	}
 
-			if global.UseSyncPool() {
-				j.handleResultUseSyncPool(c, result, dests)
-			} else {
-				j.handleResult(c, result, dests)
-			}
-		} else {
+	dests := []mapper.Dest{}
+	// mappings
+	{
+		vulnMappings, err := j.vulnMapper.Get(result.TemplateID).By(result.ExtractedResults...)
+		if err != nil {
 			if j.outLogger != nil {
-				j.outLogger.WarnContext(c, "Execute Task Result Empty",
+				j.outLogger.WarnContext(c, "Get Vulnerability Mappings Failed",
 					"job_name", j.name,
-					"template_id", poc.ID,
-					"type", poc.Type().String(),
-					"target", input,
+					"template_id", result.TemplateID,
+					"version", result.ExtractedResults[0],
+					"reason", err.Error(),
 				)
 			}
+			j.stageManager.Warn(types.StageJob, fmt.Sprintf("get vulnerability mappings of template [%s] failed: %s", result.TemplateID, err), j.stageEntries()...)
+			return
 		}
+
+		dests = append(dests, vulnMappings...)
+	}
+
+	if global.UseSyncPool() {
+		j.handleResultUseSyncPool(c, result, dests)
+	} else {
+		j.handleResult(c, result, dests)
 	}
 }
 
@@ -535,6 +820,8 @@ func (j *Job) handleResult(c context.Context, result *output.ResultEvent, dests
 		j.m.Unlock()
 	}
 
+	j.publishResults(c, results)
+
 	if j.exp != nil {
 		for _, r := range results {
 			j.exp.Export(c, r)
@@ -543,7 +830,7 @@ func (j *Job) handleResult(c context.Context, result *output.ResultEvent, dests
 }
 
 func (j *Job) handleResultUseSyncPool(c context.Context, result *output.ResultEvent, dests []mapper.Dest) {
-	if j.callback != nil {
+	if j.callback != nil || j.itemCallback != nil || j.stageManager != nil {
 		j.pushCbResult(c, result, dests)
 	}
 
@@ -571,7 +858,7 @@ func (j *Job) export(c context.Context, result *output.ResultEvent, dests []mapp
 	}
 }
 
-func (j *Job) pushCbResult(_ context.Context, result *output.ResultEvent, dests []mapper.Dest) {
+func (j *Job) pushCbResult(c context.Context, result *output.ResultEvent, dests []mapper.Dest) {
 	results := make([]*types.JobResultItem, 0, len(dests)+1)
 	if len(dests) != 0 {
 		for _, dest := range dests {
@@ -587,9 +874,23 @@ func (j *Job) pushCbResult(_ context.Context, result *output.ResultEvent, dests
 				Fill(result))
 	}
 
-	j.m.Lock()
-	j.cbResults = append(j.cbResults, results...)
-	j.m.Unlock()
+	if j.callback != nil {
+		j.m.Lock()
+		j.cbResults = append(j.cbResults, results...)
+		j.m.Unlock()
+	}
+
+	j.publishResults(c, results)
+}
+
+// publishResults 实时回调并发布命中结果
+func (j *Job) publishResults(c context.Context, results []*types.JobResultItem) {
+	for _, r := range results {
+		if j.itemCallback != nil {
+			j.itemCallback(c, r)
+		}
+		j.stageManager.Finding(types.StageJob, r, j.stageEntries()...)
+	}
 }
 
 // close 关闭或停止相关对象
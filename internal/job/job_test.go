@@ -0,0 +1,80 @@
+package job
+
+import (
+	"testing"
+
+	ptarget "github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjust(t *testing.T) {
+	assert := assert.New(t)
+
+	j, err := NewJob(
+		WithName("adjust"),
+		WithGetTemplates(func() []*types.RawTemplate { return nil }),
+		WithConcurrency(5),
+		WithRateLimit(10),
+		WithExportFormat("console"),
+		WithTimeout("5s"),
+		WithSilent(true),
+	)
+	assert.NoError(err)
+	defer j.pool.Release()
+
+	// 小于等于0的值不调整
+	j.Adjust(0, 0)
+	assert.Equal(5, j.pool.Cap())
+	assert.EqualValues(10, j.ratelimit.GetLimit())
+
+	j.Adjust(100, 0)
+	assert.Equal(5, j.pool.Cap())
+	assert.EqualValues(100, j.ratelimit.GetLimit())
+
+	j.Adjust(0, 20)
+	assert.Equal(20, j.pool.Cap())
+	assert.EqualValues(100, j.ratelimit.GetLimit())
+
+	// 执行中获取令牌时调整(配合-race检测)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 50 {
+			j.ratelimit.Take()
+		}
+	}()
+	for i := range 50 {
+		j.Adjust(1000+i, 0)
+	}
+	<-done
+	assert.EqualValues(1049, j.ratelimit.GetLimit())
+}
+
+func TestMatched(t *testing.T) {
+	assert := assert.New(t)
+
+	j := &Job{matches: []*match{
+		{target: "http://10.0.0.1", templateID: "wp-detect", tags: []string{"wordpress", "tech"}},
+		{target: "http://10.0.0.1", templateID: "wp-login", tags: []string{"wordpress", "panel"}},
+		{target: "http://10.0.0.2", templateID: "nginx-detect", tags: []string{"nginx", "tech"}},
+		{target: "http://10.0.0.3", templateID: "wp-login", tags: []string{"wordpress", "panel"}},
+	}}
+
+	tests := []struct {
+		name      string
+		tags      []string
+		templates []string
+		want      ptarget.Slice
+	}{
+		{"all", nil, nil, ptarget.Slice{"http://10.0.0.1", "http://10.0.0.2", "http://10.0.0.3"}},
+		{"tags ignore case", []string{"WordPress"}, nil, ptarget.Slice{"http://10.0.0.1", "http://10.0.0.3"}},
+		{"any tag", []string{"nginx", "panel"}, nil, ptarget.Slice{"http://10.0.0.1", "http://10.0.0.2", "http://10.0.0.3"}},
+		{"templates", nil, []string{"wp-detect", "nginx-detect"}, ptarget.Slice{"http://10.0.0.1", "http://10.0.0.2"}},
+		{"tags and templates", []string{"tech"}, []string{"wp-login"}, ptarget.Slice{}},
+		{"no match", []string{"iis"}, nil, ptarget.Slice{}},
+	}
+	for _, test := range tests {
+		assert.Equal(test.want, j.Matched(test.tags, test.templates), test.name)
+	}
+}
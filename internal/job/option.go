@@ -4,7 +4,9 @@ import (
 	"log/slog"
 
 	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
 	"github.com/EscapeBearSecond/falcon/internal/stage"
+	ptarget "github.com/EscapeBearSecond/falcon/internal/target"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
 )
 
@@ -93,6 +95,13 @@ func WithCallback(callback types.JobResultCallback) Option {
 	}
 }
 
+// WithItemCallback 配置命中结果实时回调
+func WithItemCallback(callback types.JobItemCallback) Option {
+	return func(j *Job) {
+		j.itemCallback = callback
+	}
+}
+
 // WithEntryID 配置条目ID
 func WithEntryID(id string) Option {
 	return func(j *Job) {
@@ -107,6 +116,13 @@ func WithSilent(silent bool) Option {
 	}
 }
 
+// WithNoProgressbar 配置仅禁用进度条(日志照常输出)
+func WithNoProgressbar(noBar bool) Option {
+	return func(j *Job) {
+		j.noBar = noBar
+	}
+}
+
 func WithEnableHeadless(headless bool) Option {
 	return func(j *Job) {
 		j.enableHeadless = headless
@@ -131,6 +147,41 @@ func WithStageManager(manager *stage.Manager) Option {
 	}
 }
 
+// WithPause 配置暂停控制
+func WithPause(p *pause.Controller) Option {
+	return func(j *Job) {
+		j.pause = p
+	}
+}
+
+// WithInput 配置任务输入
+func WithInput(input types.JobInputOptions) Option {
+	return func(j *Job) {
+		j.input = input
+	}
+}
+
+// WithChain 配置任务链
+func WithChain(chain types.JobChainOptions) Option {
+	return func(j *Job) {
+		j.chain = chain
+	}
+}
+
+// WithFilter 配置模板过滤
+func WithFilter(filter types.TemplateFilterOptions) Option {
+	return func(j *Job) {
+		j.filter = filter
+	}
+}
+
 type Options struct {
-	Targets []string
+	Targets   ptarget.Source
+	Hostnames ptarget.Hostnames // IP对应的域名(用于http模板的Host/SNI)
+	Services  ptarget.Services  // ip:port对应的服务(用于选择http模板的scheme)
+	Seed      int64             // 扫描顺序随机种子
+
+	Input <-chan ptarget.Item // 流水线模式下上游实时发现的目标(非nil时替代Targets)，上游结束后关闭
+
+	Fingerprints Fingerprints // 任务链模式下指纹任务的识别结果
 }
@@ -15,13 +15,21 @@ type task struct {
 	c     context.Context
 	poc   *tpl.POC
 	input string
+	seq   uint64 // 执行顺序
 }
 
 // 实例化task
-func newTask(c context.Context, poc *tpl.POC, input string) *task {
+func newTask(c context.Context, poc *tpl.POC, input string, seq uint64) *task {
 	return &task{
 		c:     c,
 		poc:   poc,
 		input: input,
+		seq:   seq,
 	}
 }
+
+// taskInput 单次执行的输入
+type taskInput struct {
+	input    string // 目标(url或host:port)
+	customIP string // 实际连接的ip(input为域名时)
+}
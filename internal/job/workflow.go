@@ -0,0 +1,90 @@
+package job
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/projectdiscovery/nuclei/v3/pkg/output"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/contextargs"
+	"github.com/projectdiscovery/nuclei/v3/pkg/scan"
+	"github.com/projectdiscovery/nuclei/v3/pkg/workflows"
+	"github.com/samber/lo"
+)
+
+// executeWorkflow 执行工作流模板，返回子模板的命中结果
+//
+// 与nuclei一致：未配置matchers的步骤命中后执行subtemplates，配置matchers的步骤按命中的matcher名称执行对应的subtemplates，
+// 步骤提取的值作为后续子模板的变量。返回所有命中步骤(包括父模板)的结果，结果的模板ID为命中的模板，
+// 步骤执行的错误通过ctx.OnError上报
+func executeWorkflow(ctx *scan.ScanContext, workflow *workflows.Workflow) []*output.ResultEvent {
+	var results []*output.ResultEvent
+	for _, step := range workflow.Workflows {
+		results = append(results, executeWorkflowStep(ctx, step)...)
+	}
+	return results
+}
+
+// executeWorkflowStep 执行工作流步骤及命中后的子模板
+func executeWorkflowStep(ctx *scan.ScanContext, step *workflows.WorkflowTemplate) []*output.ResultEvent {
+	var results, stepResults []*output.ResultEvent
+	var next []*workflows.WorkflowTemplate
+	var mu sync.Mutex // OnResult可能被执行器并发调用
+
+	for _, executer := range step.Executers {
+		if ctx.Context().Err() != nil {
+			return results
+		}
+
+		stepCtx := scan.NewScanContext(ctx.Context(), ctx.Input)
+		stepCtx.OnResult = func(event *output.InternalWrappedEvent) {
+			if event.OperatorsResult == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+
+			setExtracts(ctx.Input, event.OperatorsResult.Extracts)
+			for _, matcher := range step.Matchers {
+				if matcher.Match(event.OperatorsResult) {
+					next = append(next, matcher.Subtemplates...)
+				}
+			}
+		}
+
+		executed, err := executer.Executer.ExecuteWithResults(stepCtx)
+		if err != nil {
+			ctx.LogError(err)
+			continue
+		}
+		stepResults = append(stepResults, executed...)
+	}
+
+	if len(step.Matchers) == 0 && len(stepResults) != 0 {
+		next = append(next, step.Subtemplates...)
+	}
+	results = append(results, stepResults...)
+
+	// 多个结果命中同一matcher时子模板只执行一次
+	for _, subtemplate := range lo.Uniq(next) {
+		results = append(results, executeWorkflowStep(ctx, subtemplate)...)
+	}
+	return results
+}
+
+// setExtracts 将提取的值设置为后续子模板的变量
+func setExtracts(input *contextargs.Context, extracts map[string][]string) {
+	for k, v := range extracts {
+		switch len(v) {
+		case 0:
+		case 1:
+			// key:[item] => key:item
+			input.Set(k, v[0])
+		default:
+			// key:[item_0, ..., item_n] => key0:item_0, ..., keyn:item_n，同时保留key:[...]
+			for i, item := range v {
+				input.Set(fmt.Sprintf("%s%d", k, i), item)
+			}
+			input.Set(k, v)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package job
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/global"
+	"github.com/EscapeBearSecond/falcon/internal/tpl"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/projectdiscovery/nuclei/v3/pkg/catalog/disk"
+	"github.com/projectdiscovery/nuclei/v3/pkg/output"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/contextargs"
+	"github.com/projectdiscovery/nuclei/v3/pkg/scan"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteWorkflow(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<meta name="generator" content="WordPress 6.0">`))
+	}))
+	defer server.Close()
+
+	detect := func(id, name, word string) *types.RawTemplate {
+		return &types.RawTemplate{ID: id, Original: `id: ` + id + `
+
+info:
+  name: ` + id + `
+  author: falcon
+  severity: info
+
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: word
+        name: ` + name + `
+        words:
+          - "` + word + `"
+`}
+	}
+
+	global.Init()
+
+	eOptions := global.ExecutorOptions()
+	eOptions.Catalog = disk.NewCatalog("")
+	result, err := tpl.LoadWithFunc(func() []*types.RawTemplate {
+		return []*types.RawTemplate{
+			detect("tech-detect", "wordpress", "WordPress"),
+			detect("wp-cve", "wp", "WordPress 6.0"),
+			detect("drupal-cve", "drupal", "WordPress"),
+			detect("wp-plugin", "plugin", "WordPress"),
+			{ID: "workflow", Original: `id: workflow
+
+info:
+  name: workflow
+  author: falcon
+
+workflows:
+  - template: tech-detect
+    matchers:
+      - name: wordpress
+        subtemplates:
+          - template: wp-cve
+            subtemplates:
+              - template: wp-plugin
+      - name: drupal
+        subtemplates:
+          - template: drupal-cve
+`},
+		}
+	}, eOptions)
+	assert.NoError(err)
+	assert.Len(result.Pocs, 1)
+
+	poc := result.Pocs[0]
+	assert.True(poc.IsWorkflow())
+
+	var errs []error
+	ctx := scan.NewScanContext(context.Background(), contextargs.NewWithInput(context.Background(), server.URL))
+	ctx.OnError = func(err error) {
+		errs = append(errs, err)
+	}
+	results := executeWorkflow(ctx, poc.CompiledWorkflow)
+	assert.Empty(errs)
+
+	// 返回所有命中步骤的结果，tech-detect/wp-cve命中后执行后续子模板，tech-detect未命中drupal，drupal-cve不执行
+	ids := lo.Map(lo.Compact(results), func(result *output.ResultEvent, _ int) string { return result.TemplateID })
+	assert.Equal([]string{"tech-detect", "wp-cve", "wp-plugin"}, lo.Uniq(ids))
+}
@@ -115,5 +115,10 @@ func Hash(str string, base64Func Base64Func) (int32, error) {
 	if err != nil {
 		return 0, err
 	}
-	return calculateHash(base64Func(iconBytes)), nil
+	return Sum(iconBytes, base64Func), nil
+}
+
+// Sum 计算已获取的favicon内容的hash
+func Sum(iconBytes []byte, base64Func Base64Func) int32 {
+	return calculateHash(base64Func(iconBytes))
 }
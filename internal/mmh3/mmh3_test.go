@@ -42,3 +42,16 @@ func TestCalculateHash(t *testing.T) {
 	assert.NoError(err)
 	assert.EqualValues(-1588080585, hash)
 }
+
+func TestSum(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir := generateICON()
+	defer os.RemoveAll(tempDir)
+
+	iconBytes, err := os.ReadFile(filepath.Join(tempDir, "favicon.ico"))
+	assert.NoError(err)
+
+	assert.EqualValues(-412497964, Sum(iconBytes, Base64Encode))
+	assert.EqualValues(-1588080585, Sum(iconBytes, Base64PyEncode))
+}
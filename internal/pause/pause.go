@@ -0,0 +1,75 @@
+package pause
+
+import (
+	"context"
+	"sync"
+)
+
+// Controller 暂停控制
+//
+// 暂停后各阶段在提交下一个任务前阻塞，已提交的任务执行完成后goroutine池与限流器空闲，继续后从阻塞处执行
+type Controller struct {
+	m      sync.Mutex
+	resume chan struct{} // 暂停时非nil，继续时关闭
+}
+
+func New() *Controller {
+	return &Controller{}
+}
+
+// Pause 暂停，已暂停时返回false
+func (p *Controller) Pause() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.resume != nil {
+		return false
+	}
+	p.resume = make(chan struct{})
+	return true
+}
+
+// Resume 继续，未暂停时返回false
+func (p *Controller) Resume() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.resume == nil {
+		return false
+	}
+	close(p.resume)
+	p.resume = nil
+	return true
+}
+
+// Paused 是否已暂停
+func (p *Controller) Paused() bool {
+	if p == nil {
+		return false
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.resume != nil
+}
+
+// Wait 暂停时阻塞至继续或上下文取消
+func (p *Controller) Wait(c context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.m.Lock()
+	resume := p.resume
+	p.m.Unlock()
+	if resume == nil {
+		return nil
+	}
+
+	select {
+	case <-c.Done():
+		return context.Canceled
+	case <-resume:
+		return nil
+	}
+}
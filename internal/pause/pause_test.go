@@ -0,0 +1,45 @@
+package pause
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestController(t *testing.T) {
+	assert := assert.New(t)
+
+	var nilController *Controller
+	assert.NoError(nilController.Wait(context.Background()))
+	assert.False(nilController.Paused())
+
+	p := New()
+	assert.NoError(p.Wait(context.Background()))
+	assert.False(p.Resume())
+
+	assert.True(p.Pause())
+	assert.False(p.Pause())
+	assert.True(p.Paused())
+
+	// 暂停时阻塞至继续
+	done := make(chan error)
+	go func() {
+		done <- p.Wait(context.Background())
+	}()
+	select {
+	case <-done:
+		assert.Fail("wait returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.True(p.Resume())
+	assert.NoError(<-done)
+	assert.False(p.Paused())
+
+	// 暂停时上下文取消返回错误
+	p.Pause()
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(p.Wait(c), context.Canceled)
+}
@@ -0,0 +1,104 @@
+//go:build linux
+// +build linux
+
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// localNets 本机直连网段(IPv4)
+var localNets = sync.OnceValue(func() []*net.IPNet {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	nets := make([]*net.IPNet, 0, len(addrs))
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.To4() == nil {
+			continue
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets
+})
+
+// arp 通过内核ARP表探测直连网段主机(仅IPv4)
+//
+// 向目标发送UDP报文触发内核ARP解析，随后轮询/proc/net/arp直到出现已完成的表项
+func arp(c context.Context, host string, timeout time.Duration) bool {
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		return false
+	}
+
+	connected := false
+	for _, ipnet := range localNets() {
+		if ipnet.Contains(ip) {
+			connected = true
+			break
+		}
+	}
+	if !connected {
+		return false
+	}
+
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: ip, Port: 9})
+	if err != nil {
+		return false
+	}
+	conn.Write([]byte{0})
+	conn.Close()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		if arpResolved(ip) {
+			return true
+		}
+		select {
+		case <-c.Done():
+			return false
+		case <-deadline:
+			return arpResolved(ip)
+		case <-ticker.C:
+		}
+	}
+}
+
+// arpResolved 判断ARP表中是否存在ip对应的已完成表项
+func arpResolved(ip net.IP) bool {
+	f, err := os.Open("/proc/net/arp")
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	target := ip.String()
+	scanner := bufio.NewScanner(f)
+	// 跳过表头
+	scanner.Scan()
+	for scanner.Scan() {
+		// IP address, HW type, Flags, HW address, Mask, Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] != target {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[2], 0, 32)
+		if err != nil {
+			return false
+		}
+		// ATF_COM
+		return flags&0x2 != 0 && fields[3] != "00:00:00:00:00:00"
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package scanner
+
+import (
+	"context"
+	"time"
+)
+
+// arp 非Linux平台不支持ARP探测
+func arp(_ context.Context, _ string, _ time.Duration) bool {
+	return false
+}
@@ -0,0 +1,347 @@
+package scanner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/export"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
+	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/internal/util/log"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/panjf2000/ants/v2"
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/samber/lo"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cast"
+)
+
+var _ Scanner[*ServiceTargets, []*types.CertResultItem] = (*certCollector)(nil)
+
+// defaultTLSPort 非ip:port目标使用的端口
+const defaultTLSPort = "443"
+
+// weakSignatureAlgorithms 弱签名算法
+var weakSignatureAlgorithms = []x509.SignatureAlgorithm{
+	x509.MD2WithRSA,
+	x509.MD5WithRSA,
+	x509.SHA1WithRSA,
+	x509.DSAWithSHA1,
+	x509.ECDSAWithSHA1,
+}
+
+// certCollector TLS证书采集
+type certCollector struct {
+	name         string
+	entryID      string
+	timeout      time.Duration
+	retries      int
+	exporter     export.Exporter
+	logger       *slog.Logger
+	rl           *ratelimit.Limiter
+	pool         *ants.Pool
+	m            sync.Mutex
+	results      []*types.CertResultItem
+	bar          *progressbar.ProgressBar
+	callback     types.CertResultCallback
+	silent       bool
+	noBar        bool // 仅禁用进度条
+	stageManager *stage.Manager
+	pause        *pause.Controller
+	completed    *atomic.Int64
+}
+
+// NewCertCollector 实例化证书采集
+func NewCertCollector(cfg *CertCollectorConfig) (Scanner[*ServiceTargets, []*types.CertResultItem], error) {
+	duration, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate collection timeout: %w", err)
+	}
+
+	collector := &certCollector{
+		name:         certName,
+		entryID:      cfg.EntryID,
+		timeout:      duration,
+		retries:      max(cfg.Count, 1),
+		callback:     cfg.ResultCallback,
+		silent:       cfg.Silent,
+		noBar:        cfg.NoProgressbar,
+		stageManager: cfg.StageManager,
+		pause:        cfg.Pause,
+		completed:    &atomic.Int64{},
+		rl:           ratelimit.New(context.Background(), uint(cfg.RateLimit), 1*time.Second),
+	}
+
+	if collector.silent {
+		collector.logger = log.Must(log.NewLogger(log.WithSilent(true)))
+	} else {
+		collector.logger = log.Must(log.NewLogger(log.WithStdout()))
+	}
+
+	switch cfg.Format {
+	case "csv":
+		exporter, err := export.NewCsvExporter(filepath.Join(cfg.Directory, cfg.EntryID, collector.name), certHeader...)
+		if err != nil {
+			return nil, err
+		}
+		collector.exporter = exporter
+	case "excel":
+		exporter, err := export.NewExcelExporter(filepath.Join(cfg.Directory, cfg.EntryID, collector.name), certHeader...)
+		if err != nil {
+			return nil, err
+		}
+		collector.exporter = exporter
+	default:
+		return nil, ErrCertOuputSupport
+	}
+
+	pool, err := ants.NewPool(cfg.Concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate collector routine pool failed: %w", err)
+	}
+	collector.pool = pool
+
+	return collector, nil
+}
+
+func (cc *certCollector) Scan(c context.Context, o *Options[*ServiceTargets]) ([]*types.CertResultItem, error) {
+	cc.logger.InfoContext(c, "Running certificate collection")
+	results, err := cc.scan(c, o)
+	if err != nil {
+		return nil, err
+	}
+	cc.logger.InfoContext(c, "Certificate collection completed")
+
+	cc.doCallback(c)
+
+	return results, nil
+}
+
+func (cc *certCollector) doCallback(c context.Context) error {
+	if cc.callback != nil {
+		err := cc.callback(c, &types.CertResult{EntryID: cc.entryID, Items: cc.results})
+		if err != nil {
+			return fmt.Errorf("certificate collection callback failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// scan 对目标进行TLS握手并采集证书
+func (cc *certCollector) scan(c context.Context, o *Options[*ServiceTargets]) ([]*types.CertResultItem, error) {
+	defer cc.exporter.Close()
+	defer cc.pool.Release()
+	defer cc.rl.Stop()
+
+	cc.results = make([]*types.CertResultItem, 0)
+
+	cc.bar = util.NewProgressbar(cc.name, int64(o.Targets.Targets.Size()), cc.silent || cc.noBar)
+
+	ok := make(chan struct{})
+	defer close(ok)
+	go cc.progress(c, ok)
+
+	wg := sync.WaitGroup{}
+	it := o.Targets.Targets.Iterator()
+	for t, more := it.Next(); more; t, more = it.Next() {
+		select {
+		case <-c.Done():
+			return nil, context.Canceled
+		default:
+		}
+
+		hostPort := t
+		if !util.IsHostPort(t) {
+			hostPort = net.JoinHostPort(t, defaultTLSPort)
+		}
+
+		// 已识别为非TLS服务的端口无需握手
+		if service, found := o.Targets.Services[hostPort]; found && service.Name != "" && !service.TLS {
+			cc.completed.Add(1)
+			continue
+		}
+
+		// 暂停时等待继续
+		if err := cc.pause.Wait(c); err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		cc.rl.Take()
+		cc.pool.Submit(func() {
+			defer wg.Done()
+			defer cc.completed.Add(1)
+
+			host, _, _ := net.SplitHostPort(hostPort)
+			serverNames := o.Targets.Hostnames.Lookup(host)
+			if len(serverNames) == 0 {
+				serverNames = []string{""}
+			}
+
+			for _, serverName := range serverNames {
+				result := cc.collect(c, hostPort, serverName)
+
+				select {
+				case <-c.Done():
+					return
+				default:
+				}
+
+				if result == nil {
+					continue
+				}
+
+				cc.m.Lock()
+				cc.results = append(cc.results, result)
+				cc.m.Unlock()
+
+				cc.exporter.Export(c, certRow(result))
+			}
+		})
+	}
+
+	wg.Wait()
+
+	select {
+	case <-c.Done():
+		return nil, context.Canceled
+	default:
+	}
+
+	return cc.results, nil
+}
+
+// collect 握手获取服务端证书，失败返回nil
+func (cc *certCollector) collect(c context.Context, hostPort, serverName string) *types.CertResultItem {
+	for range cc.retries {
+		select {
+		case <-c.Done():
+			return nil
+		default:
+		}
+
+		state, err := cc.handshake(c, hostPort, serverName)
+		if err != nil || len(state.PeerCertificates) == 0 {
+			continue
+		}
+
+		ip, port, _ := net.SplitHostPort(hostPort)
+		result := parseCertificate(state.PeerCertificates[0])
+		result.EntryID = cc.entryID
+		result.IP = ip
+		result.Port = cast.ToInt(port)
+		result.HostPort = hostPort
+		result.ServerName = serverName
+		result.TLSVersion = tls.VersionName(state.Version)
+		return result
+	}
+	return nil
+}
+
+func (cc *certCollector) handshake(c context.Context, hostPort, serverName string) (*tls.ConnectionState, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: cc.timeout},
+		Config: &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         serverName,
+			// 采集旧版本服务端证书
+			MinVersion: tls.VersionTLS10,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(c, cc.timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	return &state, nil
+}
+
+// parseCertificate 提取证书信息
+func parseCertificate(cert *x509.Certificate) *types.CertResultItem {
+	fingerprint := sha256.Sum256(cert.Raw)
+	result := &types.CertResultItem{
+		Subject:            cert.Subject.String(),
+		SANs:               slices.Concat(cert.DNSNames, lo.Map(cert.IPAddresses, func(ip net.IP, _ int) string { return ip.String() })),
+		Issuer:             cert.Issuer.String(),
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		Expired:            time.Now().After(cert.NotAfter),
+		KeyAlgorithm:       cert.PublicKeyAlgorithm.String(),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		WeakSignature:      lo.Contains(weakSignatureAlgorithms, cert.SignatureAlgorithm),
+		Fingerprint:        hex.EncodeToString(fingerprint[:]),
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		result.KeySize = key.N.BitLen()
+		result.WeakKey = result.KeySize < 2048
+	case *ecdsa.PublicKey:
+		result.KeySize = key.Curve.Params().BitSize
+		result.WeakKey = result.KeySize < 224
+	case ed25519.PublicKey:
+		result.KeySize = 256
+	}
+
+	// 主题与颁发者相同且可用自身公钥验证签名(CheckSignatureFrom要求CA标识，不适用于非CA的自签名证书)
+	if cert.Subject.String() == cert.Issuer.String() {
+		result.SelfSigned = cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+	}
+
+	return result
+}
+
+func certRow(result *types.CertResultItem) []any {
+	yes := func(b bool) string { return lo.If(b, "是").Else("否") }
+	return []any{
+		result.IP, result.Port, result.ServerName, result.Subject, strings.Join(result.SANs, ","), result.Issuer,
+		result.NotBefore.Local().Format(time.DateTime), result.NotAfter.Local().Format(time.DateTime), yes(result.Expired),
+		result.KeyAlgorithm, result.KeySize, yes(result.WeakKey), result.SignatureAlgorithm, yes(result.WeakSignature),
+		yes(result.SelfSigned), result.TLSVersion, result.Fingerprint,
+	}
+}
+
+func (cc *certCollector) progress(c context.Context, ok <-chan struct{}) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ok:
+			cc.bar.Finish()
+			cc.stageManager.Put(types.StageCertificate, 1)
+			return
+		case <-ticker.C:
+			cc.bar.Set64(cc.completed.Load())
+			cc.stageManager.Put(types.StageCertificate, cc.bar.State().CurrentPercent)
+		}
+	}
+}
+
+// Adjust 运行中调整速率与并发数
+func (cc *certCollector) Adjust(rate, concurrency int) {
+	adjust(cc.rl, cc.pool, rate, concurrency)
+}
@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/csv"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+// selfSigned 生成自签名证书
+func selfSigned(t *testing.T, notBefore, notAfter time.Time) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "app.example.com", Organization: []string{"Falcon"}},
+		DNSNames:     []string{"app.example.com", "www.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestCertCollector(t *testing.T) {
+	assert := assert.New(t)
+
+	notBefore := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	notAfter := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{selfSigned(t, notBefore, notAfter)}}
+	server.StartTLS()
+	defer server.Close()
+	hostPort := server.Listener.Addr().String()
+
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "entry"), 0755))
+
+	var result *types.CertResult
+	collector, err := NewCertCollector(&CertCollectorConfig{
+		Timeout:     "2s",
+		Count:       1,
+		Format:      "csv",
+		RateLimit:   10,
+		Concurrency: 1,
+		EntryID:     "entry",
+		Silent:      true,
+		Directory:   dir,
+		ResultCallback: func(_ context.Context, cr *types.CertResult) error {
+			result = cr
+			return nil
+		},
+	})
+	assert.NoError(err)
+
+	// 已识别为非TLS服务的端口不握手
+	items, err := collector.Scan(context.Background(), &Options[*ServiceTargets]{Targets: &ServiceTargets{
+		Targets:  target.Slice{hostPort, "127.0.0.1:1"},
+		Services: target.Services{"127.0.0.1:1": {Name: serviceHTTP}},
+	}})
+	assert.NoError(err)
+	if !assert.Len(items, 1) {
+		return
+	}
+
+	item := items[0]
+	assert.Equal("entry", item.EntryID)
+	assert.Equal(hostPort, item.HostPort)
+	assert.Equal("CN=app.example.com,O=Falcon", item.Subject)
+	assert.Equal([]string{"app.example.com", "www.example.com", "127.0.0.1"}, item.SANs)
+	assert.Equal(item.Subject, item.Issuer)
+	assert.True(item.NotBefore.Equal(notBefore))
+	assert.True(item.NotAfter.Equal(notAfter))
+	assert.True(item.Expired)
+	assert.True(item.SelfSigned)
+	assert.Equal("ECDSA", item.KeyAlgorithm)
+	assert.Equal(256, item.KeySize)
+	assert.False(item.WeakKey)
+	assert.False(item.WeakSignature)
+	assert.Len(item.Fingerprint, 64)
+
+	if assert.NotNil(result) {
+		assert.Equal(items, result.Items)
+	}
+
+	// 导出的主题、备用名称、颁发者与过期时间
+	f, err := os.Open(filepath.Join(dir, "entry", certName+".csv"))
+	assert.NoError(err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	assert.NoError(err)
+	if assert.Len(rows, 2) {
+		header, row := rows[0], rows[1]
+		column := func(name string) string {
+			return row[lo.IndexOf(header, name)]
+		}
+		assert.Equal("CN=app.example.com,O=Falcon", column("主题"))
+		assert.Equal("app.example.com,www.example.com,127.0.0.1", column("备用名称"))
+		assert.Equal("CN=app.example.com,O=Falcon", column("颁发者"))
+		assert.Equal(notAfter.Local().Format(time.DateTime), column("过期时间"))
+		assert.Equal("是", column("已过期"))
+		assert.Equal("是", column("自签名"))
+	}
+}
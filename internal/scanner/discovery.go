@@ -0,0 +1,174 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+
+	"github.com/EscapeBearSecond/falcon/internal/util/synscan"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+)
+
+// 在线检测方式
+const (
+	discoveryICMP = "icmp"
+	discoveryTCP  = "tcp"
+	discoverySYN  = "syn"
+	discoveryARP  = "arp"
+	discoveryUDP  = "udp"
+)
+
+var discoveryMethods = []string{discoveryICMP, discoveryTCP, discoverySYN, discoveryARP, discoveryUDP}
+
+// discover 按配置顺序使用各探测方式，任一方式成功即认为存活
+func (p *hostDiscoverer) discover(c context.Context, host string) (*types.PingResultItem, bool) {
+	for _, method := range p.methods {
+		select {
+		case <-c.Done():
+			return nil, false
+		default:
+		}
+
+		var (
+			result *types.PingResultItem
+			alive  bool
+		)
+		switch method {
+		case discoveryICMP:
+			result, alive = p.icmp(c, host)
+		case discoveryTCP:
+			alive = p.tcp(c, host)
+		case discoverySYN:
+			alive = p.synTCP(c, host)
+		case discoveryARP:
+			alive = arp(c, host, p.timeout)
+		case discoveryUDP:
+			alive = p.udp(c, host)
+		}
+
+		if alive {
+			if result == nil {
+				result = &types.PingResultItem{IP: host}
+			}
+			result.EntryID = p.entryID
+			result.Active = true
+			result.Method = method
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// icmp ICMP echo探测(共用单个socket收发)
+func (p *hostDiscoverer) icmp(c context.Context, host string) (*types.PingResultItem, bool) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		addrs, err := net.DefaultResolver.LookupIPAddr(c, host)
+		if err != nil || len(addrs) == 0 {
+			return nil, false
+		}
+		ip = addrs[0].IP
+	}
+
+	stats, err := p.pinger.Ping(c, ip, max(p.count, 1), p.timeout)
+	if err != nil {
+		return nil, false
+	}
+
+	result := &types.PingResultItem{
+		IP:     stats.Addr,
+		TTL:    stats.TTL,
+		MinRTT: float64(stats.MinRTT.Microseconds()) / 1000,
+		AvgRTT: float64(stats.AvgRTT.Microseconds()) / 1000,
+		Loss:   stats.Loss(),
+	}
+	result.InitialTTL, result.OS = guessOS(stats.TTL)
+	return result, true
+}
+
+// guessOS 根据响应TTL估算初始TTL并推测系统类型
+//
+// 常见初始TTL: 32(早期Windows) 64(Linux/Unix) 128(Windows) 255(网络设备/Solaris)，
+// 响应TTL为初始TTL减去经过的跳数，取不小于响应TTL的最小初始值
+func guessOS(ttl int) (int, string) {
+	switch {
+	case ttl <= 0:
+		return 0, "unknown"
+	case ttl <= 32:
+		return 32, "windows"
+	case ttl <= 64:
+		return 64, "linux"
+	case ttl <= 128:
+		return 128, "windows"
+	default:
+		return 255, "network"
+	}
+}
+
+// tcp TCP连接探测，连接成功或被拒绝(RST)均认为存活
+func (p *hostDiscoverer) tcp(c context.Context, host string) bool {
+	for range max(p.count, 1) {
+		alive := probeAny(c, len(p.tcpPorts), func(c context.Context, i int) bool {
+			dialer := net.Dialer{Timeout: p.timeout}
+			conn, err := dialer.DialContext(c, "tcp", net.JoinHostPort(host, strconv.Itoa(p.tcpPorts[i])))
+			if err == nil {
+				conn.Close()
+				return true
+			}
+			return errors.Is(err, syscall.ECONNREFUSED)
+		})
+		if alive {
+			return true
+		}
+	}
+	return false
+}
+
+// synTCP 半开放(SYN)探测，收到SYN/ACK或RST均认为存活；SYN扫描不可用或非IPv4地址时使用TCP连接探测
+func (p *hostDiscoverer) synTCP(c context.Context, host string) bool {
+	ip := net.ParseIP(host).To4()
+	if p.syn == nil || ip == nil {
+		return p.tcp(c, host)
+	}
+
+	for range max(p.count, 1) {
+		alive := probeAny(c, len(p.tcpPorts), func(c context.Context, i int) bool {
+			err := p.syn.Check(c, ip, p.tcpPorts[i], p.timeout)
+			return err == nil || errors.Is(err, synscan.ErrPortClosed)
+		})
+		if alive {
+			return true
+		}
+	}
+	return false
+}
+
+// udp UDP探测，收到响应或ICMP端口不可达均认为存活
+func (p *hostDiscoverer) udp(c context.Context, host string) bool {
+	for range max(p.count, 1) {
+		alive := probeAny(c, len(p.udpPorts), func(c context.Context, i int) bool {
+			return probeUDP(c, host, p.udpPorts[i], p.timeout) != udpNoResponse
+		})
+		if alive {
+			return true
+		}
+	}
+	return false
+}
+
+// probeAny 依次执行n个探测，任一成功即返回true
+//
+// 探测在goroutine池的worker内顺序执行，使实际并发数受限于配置的并发数
+func probeAny(c context.Context, n int, probe func(context.Context, int) bool) bool {
+	for i := range n {
+		if c.Err() != nil {
+			return false
+		}
+		if probe(c, i) {
+			return true
+		}
+	}
+	return false
+}
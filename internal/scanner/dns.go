@@ -0,0 +1,289 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/export"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
+	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/internal/util/log"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/panjf2000/ants/v2"
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/samber/lo"
+	"github.com/schollz/progressbar/v3"
+)
+
+var _ Scanner[*target.Space, *Resolution] = (*dnsResolver)(nil)
+
+// Resolution 域名解析结果
+type Resolution struct {
+	Targets   *target.Space       // 解析后的目标(域名替换为IP，其余目标保持不变)
+	Hostnames target.Hostnames    // IP对应的原始域名
+	Records   map[string][]string // 域名对应的IP(用于断点恢复时重建目标)
+}
+
+// dnsResolver 域名解析
+type dnsResolver struct {
+	name         string
+	entryID      string
+	timeout      time.Duration
+	retries      int
+	resolver     *net.Resolver
+	records      map[string][]string
+	exporter     export.Exporter
+	logger       *slog.Logger
+	rl           *ratelimit.Limiter
+	pool         *ants.Pool
+	m            sync.Mutex
+	bar          *progressbar.ProgressBar
+	callback     types.DNSResultCallback
+	silent       bool
+	noBar        bool // 仅禁用进度条
+	stageManager *stage.Manager
+	pause        *pause.Controller
+	completed    *atomic.Int64
+}
+
+// NewDNSResolver 实例化域名解析
+func NewDNSResolver(cfg *DNSResolverConfig) (Scanner[*target.Space, *Resolution], error) {
+	duration, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dns resolution timeout: %w", err)
+	}
+
+	resolver := &dnsResolver{
+		name:         dnsName,
+		entryID:      cfg.EntryID,
+		timeout:      duration,
+		retries:      max(cfg.Count, 1),
+		resolver:     newNetResolver(cfg.Resolvers, duration),
+		callback:     cfg.ResultCallback,
+		silent:       cfg.Silent,
+		noBar:        cfg.NoProgressbar,
+		stageManager: cfg.StageManager,
+		pause:        cfg.Pause,
+		completed:    &atomic.Int64{},
+		rl:           ratelimit.New(context.Background(), uint(cfg.RateLimit), 1*time.Second),
+	}
+
+	if resolver.silent {
+		resolver.logger = log.Must(log.NewLogger(log.WithSilent(true)))
+	} else {
+		resolver.logger = log.Must(log.NewLogger(log.WithStdout()))
+	}
+
+	switch cfg.Format {
+	case "csv":
+		exporter, err := export.NewCsvExporter(filepath.Join(cfg.Directory, cfg.EntryID, resolver.name), dnsHeader...)
+		if err != nil {
+			return nil, err
+		}
+		resolver.exporter = exporter
+	case "excel":
+		exporter, err := export.NewExcelExporter(filepath.Join(cfg.Directory, cfg.EntryID, resolver.name), dnsHeader...)
+		if err != nil {
+			return nil, err
+		}
+		resolver.exporter = exporter
+	default:
+		return nil, ErrDNSOuputSupport
+	}
+
+	pool, err := ants.NewPool(cfg.Concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("create dns resolver routine pool failed: %w", err)
+	}
+	resolver.pool = pool
+
+	return resolver, nil
+}
+
+// newNetResolver 构建解析器，未配置DNS服务器时使用系统配置
+func newNetResolver(servers []string, timeout time.Duration) *net.Resolver {
+	servers = lo.FilterMap(servers, func(server string, _ int) (string, bool) {
+		if server == "" {
+			return "", false
+		}
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			return net.JoinHostPort(server, "53"), true
+		}
+		return server, true
+	})
+	if len(servers) == 0 {
+		return net.DefaultResolver
+	}
+
+	next := &atomic.Uint64{}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			server := servers[next.Add(1)%uint64(len(servers))]
+			dialer := net.Dialer{Timeout: timeout}
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+func (r *dnsResolver) Scan(c context.Context, o *Options[*target.Space]) (*Resolution, error) {
+	r.logger.InfoContext(c, "Running dns resolution")
+	results, err := r.scan(c, o)
+	if err != nil {
+		return nil, err
+	}
+	r.logger.InfoContext(c, "DNS resolution completed")
+
+	r.doCallback(c)
+
+	return results, nil
+}
+
+func (r *dnsResolver) doCallback(c context.Context) error {
+	if r.callback != nil {
+		results := make([]*types.DNSResultItem, 0, len(r.records))
+		for domain, ips := range r.records {
+			results = append(results, &types.DNSResultItem{
+				EntryID:  r.entryID,
+				Domain:   domain,
+				IPs:      ips,
+				Resolved: len(ips) != 0,
+			})
+		}
+		err := r.callback(c, &types.DNSResult{EntryID: r.entryID, Items: results})
+		if err != nil {
+			return fmt.Errorf("dns resolution callback failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// scan 解析目标中的域名
+func (r *dnsResolver) scan(c context.Context, o *Options[*target.Space]) (*Resolution, error) {
+	defer r.exporter.Close()
+	defer r.pool.Release()
+	defer r.rl.Stop()
+
+	domains := o.Targets.Hostnames()
+
+	r.records = make(map[string][]string, len(domains))
+
+	r.bar = util.NewProgressbar(r.name, int64(len(domains)), r.silent || r.noBar)
+
+	ok := make(chan struct{})
+	defer close(ok)
+	go r.progress(c, ok)
+
+	wg := sync.WaitGroup{}
+	for _, domain := range domains {
+		select {
+		case <-c.Done():
+			return nil, context.Canceled
+		default:
+		}
+
+		// 暂停时等待继续
+		if err := r.pause.Wait(c); err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		r.rl.Take()
+		r.pool.Submit(func() {
+			defer wg.Done()
+			defer r.completed.Add(1)
+
+			ips := r.lookup(c, domain)
+
+			select {
+			case <-c.Done():
+				return
+			default:
+			}
+
+			r.m.Lock()
+			r.records[domain] = ips
+			r.m.Unlock()
+
+			if len(ips) == 0 {
+				r.exporter.Export(c, []any{domain, ""})
+			}
+			for _, ip := range ips {
+				r.exporter.Export(c, []any{domain, ip})
+			}
+		})
+	}
+
+	wg.Wait()
+
+	select {
+	case <-c.Done():
+		return nil, context.Canceled
+	default:
+	}
+
+	result := &Resolution{
+		Targets:   o.Targets.Resolve(r.records),
+		Hostnames: make(target.Hostnames),
+		Records:   r.records,
+	}
+	for domain, ips := range r.records {
+		for _, ip := range ips {
+			result.Hostnames.Add(ip, domain)
+		}
+	}
+
+	return result, nil
+}
+
+// lookup 解析域名对应的IPv4/IPv6地址
+func (r *dnsResolver) lookup(c context.Context, domain string) []string {
+	for range r.retries {
+		select {
+		case <-c.Done():
+			return nil
+		default:
+		}
+
+		ips, err := func() ([]net.IP, error) {
+			cc, cancel := context.WithTimeout(c, r.timeout)
+			defer cancel()
+			return r.resolver.LookupIP(cc, "ip", domain)
+		}()
+		if err == nil && len(ips) != 0 {
+			return lo.Uniq(lo.Map(ips, func(ip net.IP, _ int) string { return ip.String() }))
+		}
+	}
+	return nil
+}
+
+func (r *dnsResolver) progress(c context.Context, ok <-chan struct{}) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ok:
+			r.bar.Finish()
+			r.stageManager.Put(types.StageDNSResolution, 1)
+			return
+		case <-ticker.C:
+			r.bar.Set64(r.completed.Load())
+			r.stageManager.Put(types.StageDNSResolution, r.bar.State().CurrentPercent)
+		}
+	}
+}
+
+// Adjust 运行中调整速率与并发数
+func (r *dnsResolver) Adjust(rate, concurrency int) {
+	adjust(r.rl, r.pool, rate, concurrency)
+}
@@ -7,26 +7,28 @@ import (
 	"net"
 	"path/filepath"
 	"runtime"
-	"strings"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/EscapeBearSecond/falcon/internal/export"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
 	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/target"
 	"github.com/EscapeBearSecond/falcon/internal/util"
 	"github.com/EscapeBearSecond/falcon/internal/util/log"
+	icmppinger "github.com/EscapeBearSecond/falcon/internal/util/pinger"
 	"github.com/EscapeBearSecond/falcon/internal/util/privileges"
+	"github.com/EscapeBearSecond/falcon/internal/util/synscan"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
 	"github.com/panjf2000/ants/v2"
 	"github.com/projectdiscovery/ratelimit"
-	probing "github.com/prometheus-community/pro-bing"
 	"github.com/samber/lo"
 	"github.com/schollz/progressbar/v3"
-	"github.com/spf13/cast"
 )
 
-var _ Scanner[[]string, []string] = (*hostDiscoverer)(nil)
+var _ Scanner[target.Source, target.Source] = (*hostDiscoverer)(nil)
 
 // hostDiscoverer 在线检测
 type hostDiscoverer struct {
@@ -34,7 +36,13 @@ type hostDiscoverer struct {
 	entryID      string
 	timeout      time.Duration
 	count        int
-	targets      map[string][]string
+	methods      []string           // 探测方式
+	tcpPorts     []int              // TCP探测端口
+	udpPorts     []int              // UDP探测端口
+	pinger       *icmppinger.Pinger // 单socket ICMP收发引擎
+	syn          *synscan.Scanner   // SYN探测(与端口扫描共用raw socket实现，为nil时使用TCP连接)
+	source       target.Source
+	targets      map[string]*types.PingResultItem // 存活主机
 	exporter     export.Exporter
 	logger       *slog.Logger
 	ratelimit    int
@@ -44,13 +52,16 @@ type hostDiscoverer struct {
 	m            sync.Mutex
 	bar          *progressbar.ProgressBar
 	callback     types.PingResultCallback
+	itemCallback types.PingItemCallback
 	silent       bool
+	noBar        bool // 仅禁用进度条
 	stageManager *stage.Manager
+	pause        *pause.Controller
 	completed    *atomic.Int64
 }
 
 // NewHostDiscoverer 实例化在线检测
-func NewHostDiscoverer(cfg *HostDiscovererConfig) (Scanner[[]string, []string], error) {
+func NewHostDiscoverer(cfg *HostDiscovererConfig) (Scanner[target.Source, target.Source], error) {
 	duration, err := time.ParseDuration(cfg.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("invalid host discovery timeout: %w", err)
@@ -62,9 +73,12 @@ func NewHostDiscoverer(cfg *HostDiscovererConfig) (Scanner[[]string, []string],
 		concurrency:  cfg.Concurrency,
 		ratelimit:    cfg.RateLimit,
 		callback:     cfg.ResultCallback,
+		itemCallback: cfg.ItemCallback,
 		silent:       cfg.Silent,
+		noBar:        cfg.NoProgressbar,
 		entryID:      cfg.EntryID,
 		stageManager: cfg.StageManager,
+		pause:        cfg.Pause,
 		completed:    &atomic.Int64{},
 	}
 
@@ -76,6 +90,39 @@ func NewHostDiscoverer(cfg *HostDiscovererConfig) (Scanner[[]string, []string],
 
 	pinger.name = pingName
 
+	pinger.methods = lo.Uniq(cfg.Methods)
+	if len(pinger.methods) == 0 {
+		pinger.methods = []string{discoveryICMP}
+	}
+	for _, method := range pinger.methods {
+		if !lo.Contains(discoveryMethods, method) {
+			return nil, fmt.Errorf("%w: %s", ErrHostDiscoveryMethod, method)
+		}
+	}
+	if lo.Contains(pinger.methods, discoveryICMP) {
+		pinger.pinger = icmppinger.NewPinger(runtime.GOOS == "windows" || privileges.IsPrivileged)
+	}
+	if lo.Contains(pinger.methods, discoverySYN) {
+		// SYN探测需要raw socket，无权限或非Linux时回退到TCP连接
+		if privileges.IsPrivileged && runtime.GOOS == "linux" {
+			pinger.syn = synscan.NewScanner()
+		} else {
+			pinger.logger.Warn("SYN discovery requires privileges on linux, fallback to tcp discovery")
+		}
+	}
+	if lo.Contains(pinger.methods, discoveryTCP) || lo.Contains(pinger.methods, discoverySYN) {
+		pinger.tcpPorts, err = util.ParsePortsList(cfg.TCPPorts)
+		if err != nil || len(pinger.tcpPorts) == 0 {
+			return nil, fmt.Errorf("invalid host discovery tcp ports: %s", cfg.TCPPorts)
+		}
+	}
+	if lo.Contains(pinger.methods, discoveryUDP) {
+		pinger.udpPorts, err = util.ParsePortsList(cfg.UDPPorts)
+		if err != nil || len(pinger.udpPorts) == 0 {
+			return nil, fmt.Errorf("invalid host discovery udp ports: %s", cfg.UDPPorts)
+		}
+	}
+
 	switch cfg.Format {
 	case "csv":
 		exporter, err := export.NewCsvExporter(filepath.Join(cfg.Directory, cfg.EntryID, pinger.name), pingHeader...)
@@ -105,7 +152,7 @@ func NewHostDiscoverer(cfg *HostDiscovererConfig) (Scanner[[]string, []string],
 	return pinger, nil
 }
 
-func (p *hostDiscoverer) Scan(c context.Context, o *Options[[]string]) ([]string, error) {
+func (p *hostDiscoverer) Scan(c context.Context, o *Options[target.Source]) (target.Source, error) {
 	p.logger.InfoContext(c, "Running host discovery")
 	results, err := p.scan(c, o)
 	if err != nil {
@@ -120,25 +167,32 @@ func (p *hostDiscoverer) Scan(c context.Context, o *Options[[]string]) ([]string
 
 func (p *hostDiscoverer) doCallback(c context.Context) error {
 	if p.callback != nil {
-		results := make([]*types.PingResultItem, 0, len(p.targets))
-		for ip, infos := range p.targets {
-			result := &types.PingResultItem{
-				EntryID: p.entryID,
-				IP:      ip,
+		results := make([]*types.PingResultItem, 0, p.source.Size())
+		for _, result := range p.targets {
+			results = append(results, result)
+		}
+
+		// 未存活主机不驻留内存，重新遍历目标获取
+		seen := make(map[string]struct{})
+		it := p.source.Iterator()
+		for item, more := it.Next(); more; item, more = it.Next() {
+			t := item
+			if util.IsHostPort(item) {
+				t, _, _ = net.SplitHostPort(item)
+				if _, contained := seen[t]; contained {
+					continue
+				}
+				seen[t] = struct{}{}
 			}
-			if ok := infos != nil; ok {
-				result.Active = ok
-				os, _ := lo.Find(infos, func(info string) bool {
-					return strings.Split(info, ":")[0] == "os"
-				})
-				ttl, _ := lo.Find(infos, func(info string) bool {
-					return strings.Split(info, ":")[0] == "ttl"
-				})
-				result.OS = strings.Split(os, ":")[1]
-				result.TTL = cast.ToInt(strings.Split(ttl, ":")[1])
+			if _, alive := p.targets[t]; alive {
+				continue
 			}
-			results = append(results, result)
+			results = append(results, &types.PingResultItem{
+				EntryID: p.entryID,
+				IP:      t,
+			})
 		}
+
 		err := p.callback(c, &types.PingResult{EntryID: p.entryID, Items: results})
 		if err != nil {
 			return fmt.Errorf("host discovery callback failed: %w", err)
@@ -148,25 +202,68 @@ func (p *hostDiscoverer) doCallback(c context.Context) error {
 }
 
 // Scan 扫描方法
-func (p *hostDiscoverer) scan(c context.Context, o *Options[[]string]) ([]string, error) {
+func (p *hostDiscoverer) scan(c context.Context, o *Options[target.Source]) (target.Source, error) {
 	defer p.exporter.Close()
 	defer p.pool.Release()
 	defer p.rl.Stop()
 
-	p.targets = make(map[string][]string, len(o.Targets))
+	p.source = o.Targets
+	p.targets = make(map[string]*types.PingResultItem)
+	// ip:port形式的目标可能对应同一主机，仅对其去重
+	pinged := make(map[string]struct{})
 
-	total := int64(len(o.Targets))
-	p.bar = util.NewProgressbar(pingName, total, p.silent)
+	total := int64(o.Targets.Size())
+	p.bar = util.NewProgressbar(pingName, total, p.silent || p.noBar)
 
 	ok := make(chan struct{})
 	defer close(ok)
 	go p.progress(c, ok)
 
+	if p.pinger != nil {
+		pingingLoopErr := make(chan error, 1)
+		cc, stopPinger := context.WithCancel(c)
+		defer stopPinger()
+		go func() {
+			pingingLoopErr <- p.pinger.PingingLoop(cc)
+			close(pingingLoopErr)
+		}()
+
+		select {
+		case err := <-pingingLoopErr:
+			return nil, fmt.Errorf("host discoverer pinging loop failed: %w", err)
+		case <-p.pinger.WaitReady():
+		}
+	}
+
+	if p.syn != nil {
+		synLoopErr := make(chan error, 1)
+		cc, stopSyn := context.WithCancel(c)
+		defer stopSyn()
+		go func() {
+			synLoopErr <- p.syn.ScanningLoop(cc)
+			close(synLoopErr)
+		}()
+
+		select {
+		case err := <-synLoopErr:
+			// raw socket不可用时回退到TCP连接
+			p.logger.WarnContext(c, "SYN scanning loop failed, fallback to tcp discovery", "error", err)
+			p.syn = nil
+		case <-p.syn.WaitReady():
+		}
+	}
+
 	wg := sync.WaitGroup{}
-	for _, item := range o.Targets {
+	it := o.Targets.Iterator()
+	for item, more := it.Next(); more; item, more = it.Next() {
 		t := item
 		if util.IsHostPort(item) {
 			t, _, _ = net.SplitHostPort(item)
+			if _, contained := pinged[t]; contained {
+				p.completed.Add(1)
+				continue
+			}
+			pinged[t] = struct{}{}
 		}
 
 		select {
@@ -175,49 +272,18 @@ func (p *hostDiscoverer) scan(c context.Context, o *Options[[]string]) ([]string
 		default:
 		}
 
-		pinger, err := probing.NewPinger(t)
-		if err != nil {
+		// 暂停时等待继续
+		if err := p.pause.Wait(c); err != nil {
 			return nil, err
 		}
 
-		var (
-			addr  string
-			infos []string
-		)
-
-		if runtime.GOOS == "windows" {
-			pinger.SetPrivileged(true)
-		} else {
-			pinger.SetPrivileged(privileges.IsPrivileged)
-		}
-		pinger.Count = p.count
-		pinger.Interval = 100 * time.Millisecond
-		pinger.Timeout = p.timeout
-		pinger.OnRecv = func(packet *probing.Packet) {
-			addr = packet.Addr
-			switch packet.TTL {
-			case 128:
-				infos = append(infos, "ttl:128")
-				infos = append(infos, "os:windows")
-			case 64:
-				infos = append(infos, "ttl:64")
-				infos = append(infos, "os:linux")
-			default:
-				infos = append(infos, fmt.Sprintf("ttl:%d", packet.TTL))
-				infos = append(infos, "os:unknown")
-			}
-			pinger.Stop()
-		}
-
 		wg.Add(1)
 		p.rl.Take()
 		p.pool.Submit(func() {
 			defer wg.Done()
 			defer p.completed.Add(1)
 
-			defer pinger.Stop()
-
-			pinger.RunWithContext(c)
+			result, alive := p.discover(c, t)
 
 			select {
 			case <-c.Done():
@@ -225,35 +291,23 @@ func (p *hostDiscoverer) scan(c context.Context, o *Options[[]string]) ([]string
 			default:
 			}
 
-			if addr != "" {
+			if alive {
 				p.m.Lock()
-				_, contained := p.targets[addr]
+				_, contained := p.targets[result.IP]
 				if !contained {
-					p.targets[addr] = infos
+					p.targets[result.IP] = result
 				}
 				p.m.Unlock()
 
-				os, _ := lo.Find(infos, func(info string) bool {
-					return strings.Split(info, ":")[0] == "os"
-				})
-				ttl, _ := lo.Find(infos, func(info string) bool {
-					return strings.Split(info, ":")[0] == "ttl"
-				})
-
 				if !contained {
-					p.exporter.Export(c, []any{addr, "是", strings.Split(os, ":")[1], strings.Split(ttl, ":")[1]})
+					p.exporter.Export(c, pingRow(result))
+					if p.itemCallback != nil {
+						p.itemCallback(c, result)
+					}
+					emit(c, o.Output, target.Item{Target: result.IP})
 				}
 			} else {
-				p.m.Lock()
-				_, contained := p.targets[t]
-				if !contained {
-					p.targets[t] = nil
-				}
-				p.m.Unlock()
-
-				if !contained {
-					p.exporter.Export(c, []any{t, "否", "", ""})
-				}
+				p.exporter.Export(c, []any{t, "否", "", "", "", "", "", "", ""})
 			}
 		})
 	}
@@ -266,13 +320,20 @@ func (p *hostDiscoverer) scan(c context.Context, o *Options[[]string]) ([]string
 	default:
 	}
 
-	return lo.Uniq(
-		lo.Keys(
-			lo.PickBy(p.targets,
-				func(_ string, v []string) bool { return v != nil },
-			),
-		),
-	), nil
+	return target.Slice(lo.Keys(p.targets)), nil
+}
+
+// pingRow 存活主机的导出行(非ICMP方式无TTL与延迟信息)
+func pingRow(result *types.PingResultItem) []any {
+	if result.Method != discoveryICMP {
+		return []any{result.IP, "是", result.OS, "", result.Method, "", "", "", ""}
+	}
+	return []any{
+		result.IP, "是", result.OS, strconv.Itoa(result.TTL), result.Method, strconv.Itoa(result.InitialTTL),
+		strconv.FormatFloat(result.MinRTT, 'f', 2, 64),
+		strconv.FormatFloat(result.AvgRTT, 'f', 2, 64),
+		strconv.FormatFloat(result.Loss, 'f', 2, 64),
+	}
 }
 
 func (p *hostDiscoverer) progress(c context.Context, ok <-chan struct{}) {
@@ -292,3 +353,8 @@ func (p *hostDiscoverer) progress(c context.Context, ok <-chan struct{}) {
 		}
 	}
 }
+
+// Adjust 运行中调整速率与并发数
+func (p *hostDiscoverer) Adjust(rate, concurrency int) {
+	adjust(p.rl, p.pool, rate, concurrency)
+}
@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/internal/util/synscan"
+)
+
+var _ Scanner[target.Source, *PortScanning] = (*synPortScanner)(nil)
+
+// synPortScanner 半开放(SYN)端口扫描器
+//
+// TCP端口通过raw socket发送SYN检测(仅IPv4，其余地址使用全连接)，目标遍历、限速、服务识别及结果输出与全连接扫描一致
+type synPortScanner struct {
+	*portScannerV3
+	syn *synscan.Scanner
+}
+
+func newSynPortScanner(sc *portScannerV3) *synPortScanner {
+	scanner := &synPortScanner{portScannerV3: sc, syn: synscan.NewScanner()}
+	sc.checkTCP = scanner.check
+	return scanner
+}
+
+// Scan 扫描任务
+func (sc *synPortScanner) Scan(c context.Context, o *Options[target.Source]) (*PortScanning, error) {
+	cc, stop := context.WithCancel(c)
+	defer stop()
+
+	loopErr := make(chan error, 1)
+	go func() {
+		loopErr <- sc.syn.ScanningLoop(cc)
+		close(loopErr)
+	}()
+
+	select {
+	case err := <-loopErr:
+		// raw socket不可用时回退到全连接
+		sc.logger.WarnContext(c, "SYN scanning loop failed, fallback to connect scan", "error", err)
+		sc.checkTCP = sc.connect
+	case <-sc.syn.WaitReady():
+	}
+
+	return sc.portScannerV3.Scan(c, o)
+}
+
+// check SYN检测，收到SYN/ACK或RST说明主机有响应
+func (sc *synPortScanner) check(c context.Context, host string, port int, timeout time.Duration) (bool, bool) {
+	ip := net.ParseIP(host)
+	if ip.To4() == nil {
+		return sc.connect(c, host, port, timeout)
+	}
+	err := sc.syn.Check(c, ip, port, timeout)
+	return err == nil, err == nil || errors.Is(err, synscan.ErrPortClosed)
+}
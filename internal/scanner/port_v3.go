@@ -2,18 +2,27 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/EscapeBearSecond/falcon/internal/export"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
 	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/target"
 	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/internal/util/congestion"
+	"github.com/EscapeBearSecond/falcon/internal/util/cyclic"
 	"github.com/EscapeBearSecond/falcon/internal/util/log"
+	"github.com/EscapeBearSecond/falcon/internal/util/privileges"
 	"github.com/EscapeBearSecond/falcon/internal/util/shaker"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
 	"github.com/panjf2000/ants/v2"
@@ -23,7 +32,7 @@ import (
 	"github.com/spf13/cast"
 )
 
-var _ Scanner[[]string, []string] = (*portScannerV3)(nil)
+var _ Scanner[target.Source, *PortScanning] = (*portScannerV3)(nil)
 
 // portScanner 端口扫描器
 type portScannerV3 struct {
@@ -34,28 +43,46 @@ type portScannerV3 struct {
 	retries      int
 	bar          *progressbar.ProgressBar
 	callback     types.PortResultCallback
+	itemCallback types.PortItemCallback
 	silent       bool
+	noBar        bool // 仅禁用进度条
 	stageManager *stage.Manager
+	pause        *pause.Controller
 
-	ports      string
-	portsSlice []int
+	portsSlice       []util.Port
+	serviceDetection bool
 
 	rl   *ratelimit.Limiter
 	pool *ants.Pool
 
 	portSize  int64
-	total     int64
+	total     *atomic.Int64
 	completed *atomic.Int64
 	c         context.Context
 	m         sync.Mutex
-	targets   map[string]struct{}
+	targets   map[portKey]*types.PortResultItem
 	timeout   time.Duration
 
-	checker *shaker.Checker
+	checker  *shaker.Checker
+	checkTCP tcpChecker // TCP端口检测(默认全连接)
+
+	congestion *congestion.Controller // 按主机限速及自适应拥塞控制
+}
+
+// portKey 开放端口(区分协议)
+type portKey struct {
+	hostPort string
+	protocol string
+}
+
+// PortScanning 端口扫描结果
+type PortScanning struct {
+	Targets  target.Source   // 开放的TCP端口(ip:port)
+	Services target.Services // ip:port对应的服务(开启服务识别时)
 }
 
 // NewPortScanner 实例化扫描器
-func NewPortScannerV3(config *PortScannerConfig) (Scanner[[]string, []string], error) {
+func NewPortScannerV3(config *PortScannerConfig) (Scanner[target.Source, *PortScanning], error) {
 	duration, err := time.ParseDuration(config.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("invalid port scanner timeout: %w", err)
@@ -66,11 +93,17 @@ func NewPortScannerV3(config *PortScannerConfig) (Scanner[[]string, []string], e
 		entryID:      config.EntryID,
 		retries:      config.Count,
 		callback:     config.ResultCallback,
+		itemCallback: config.ItemCallback,
 		silent:       config.Silent,
+		noBar:        config.NoProgressbar,
 		stageManager: config.StageManager,
+		pause:        config.Pause,
 		timeout:      duration,
+		total:        &atomic.Int64{},
 		completed:    &atomic.Int64{},
-		rl:           ratelimit.New(context.Background(), uint(config.RateLimit), 1*time.Second),
+
+		serviceDetection: config.ServiceDetection,
+		rl:               ratelimit.New(context.Background(), uint(config.RateLimit), 1*time.Second),
 	}
 
 	if scanner.silent {
@@ -79,17 +112,6 @@ func NewPortScannerV3(config *PortScannerConfig) (Scanner[[]string, []string], e
 		scanner.logger = log.Must(log.NewLogger(log.WithStdout()))
 	}
 
-	switch config.Ports {
-	case "top100":
-		scanner.ports = top100
-	case "top1000":
-		scanner.ports = top1000
-	case "http":
-		scanner.ports = httpPort
-	default:
-		scanner.ports = config.Ports
-	}
-
 	switch config.Format {
 	case "csv":
 		exporter, err := export.NewCsvExporter(filepath.Join(config.Directory, scanner.entryID, scanner.name), portHeader...)
@@ -114,16 +136,67 @@ func NewPortScannerV3(config *PortScannerConfig) (Scanner[[]string, []string], e
 	scanner.pool = pool
 
 	scanner.checker = shaker.NewChecker()
+	scanner.checkTCP = scanner.connect
+
+	var syn bool
+	switch config.Mode {
+	case "", PortScanModeConnect:
+	case PortScanModeSYN:
+		// SYN扫描需要raw socket，无权限或非Linux时回退到全连接
+		if privileges.IsPrivileged && runtime.GOOS == "linux" {
+			syn = true
+		} else {
+			scanner.logger.Warn("SYN scan requires privileges on linux, fallback to connect scan")
+		}
+	default:
+		return nil, ErrPortScanMode
+	}
+
+	userSets, err := util.LoadPortSets(config.PortSets)
+	if err != nil {
+		return nil, err
+	}
+	sets := lo.Assign(BuiltinPortSets, userSets)
 
-	ports, _ := util.ParsePortsList(scanner.ports)
+	ports, err := util.ParsePortSpec(config.Ports, sets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port scanner ports: %w", err)
+	}
+	if config.ExcludePorts != "" {
+		excluded, err := util.ParsePortSpec(config.ExcludePorts, sets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port scanner exclude ports: %w", err)
+		}
+		skip := make(map[util.Port]struct{}, len(excluded))
+		for _, port := range excluded {
+			skip[port] = struct{}{}
+		}
+		ports = lo.Filter(ports, func(port util.Port, _ int) bool {
+			_, ok := skip[port]
+			return !ok
+		})
+	}
+	if len(ports) == 0 {
+		return nil, errors.New("no ports to scan after exclusion")
+	}
 	scanner.portsSlice = ports
 	scanner.portSize = int64(len(ports))
-
+	scanner.congestion = congestion.New(&congestion.Config{
+		HostRate: config.HostRateLimit,
+		Adaptive: config.Adaptive,
+		MaxRate:  config.RateLimit,
+		Timeout:  duration,
+		Probes:   len(ports),
+	})
+
+	if syn {
+		return newSynPortScanner(scanner), nil
+	}
 	return scanner, nil
 }
 
 // Scan 扫描任务
-func (sc *portScannerV3) Scan(c context.Context, o *Options[[]string]) ([]string, error) {
+func (sc *portScannerV3) Scan(c context.Context, o *Options[target.Source]) (*PortScanning, error) {
 
 	sc.logger.InfoContext(c, "Running port scan")
 
@@ -143,17 +216,7 @@ func (sc *portScannerV3) Scan(c context.Context, o *Options[[]string]) ([]string
 
 func (sc *portScannerV3) doCallback(c context.Context) error {
 	if sc.callback != nil {
-		results := make([]*types.PortResultItem, 0, len(sc.targets))
-		for target := range sc.targets {
-			ip, port, _ := net.SplitHostPort(target)
-			results = append(results, &types.PortResultItem{
-				EntryID:  sc.entryID,
-				IP:       ip,
-				Port:     cast.ToInt(port),
-				HostPort: target,
-			})
-		}
-		err := sc.callback(c, &types.PortResult{EntryID: sc.entryID, Items: results})
+		err := sc.callback(c, &types.PortResult{EntryID: sc.entryID, Items: lo.Values(sc.targets)})
 		if err != nil {
 			return fmt.Errorf("port scanning callback failed: %w", err)
 		}
@@ -162,29 +225,21 @@ func (sc *portScannerV3) doCallback(c context.Context) error {
 }
 
 // scan 核心scan方法
-func (sc *portScannerV3) scan(c context.Context, o *Options[[]string]) ([]string, error) {
+func (sc *portScannerV3) scan(c context.Context, o *Options[target.Source]) (*PortScanning, error) {
 	defer sc.exporter.Close()
 	defer sc.pool.Release()
 	defer sc.rl.Stop()
 
 	sc.c = c
-	sc.targets = make(map[string]struct{}, 0)
-
-	scanTargets := make([]string, 0, len(o.Targets))
-	// 添加扫描目标
-	for _, target := range o.Targets {
-		if util.IsHostPort(target) {
-			if _, contained := sc.targets[target]; !contained {
-				sc.targets[target] = struct{}{}
-			}
-			continue
-		}
-		scanTargets = append(scanTargets, target)
-	}
+	sc.targets = make(map[portKey]*types.PortResultItem, 0)
 
-	// 构建进度条
-	sc.total = sc.portSize * int64(len(scanTargets))
-	sc.bar = util.NewProgressbar(sc.name, int64(sc.total), sc.silent)
+	// 构建进度条(流水线模式下目标数量未知，随接收的主机增加)
+	if o.Input != nil {
+		sc.bar = util.NewProgressbar(sc.name, -1, sc.silent || sc.noBar)
+	} else {
+		sc.total.Store(sc.portSize * int64(o.Targets.Size()))
+		sc.bar = util.NewProgressbar(sc.name, sc.total.Load(), sc.silent || sc.noBar)
+	}
 
 	checkingLoopErr := make(chan error, 1)
 	cc, stopChecker := context.WithCancel(c)
@@ -205,76 +260,239 @@ func (sc *portScannerV3) scan(c context.Context, o *Options[[]string]) ([]string
 	}
 
 	wg := sync.WaitGroup{}
-	for _, target := range scanTargets {
+	var err error
+	if o.Input != nil {
+		err = sc.scanStream(c, o, &wg)
+	} else {
+		err = sc.scanTargets(c, o, &wg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wg.Wait()
+
+	select {
+	case <-c.Done():
+		return nil, context.Canceled
+	default:
+	}
 
+	// 后续任务模板基于TCP，仅传递TCP开放端口
+	hostPorts := make([]string, 0, len(sc.targets))
+	services := make(target.Services)
+	for key, result := range sc.targets {
+		if key.protocol != util.ProtocolTCP {
+			continue
+		}
+		hostPorts = append(hostPorts, key.hostPort)
+		if result.Service != "" {
+			services[key.hostPort] = target.Service{Name: result.Service, TLS: result.TLS}
+		}
+	}
+	return &PortScanning{Targets: target.Slice(hostPorts), Services: services}, nil
+}
+
+// scanTargets 按随机排列遍历 目标×端口，使负载分散到不同主机
+func (sc *portScannerV3) scanTargets(c context.Context, o *Options[target.Source], wg *sync.WaitGroup) error {
+	portSize := uint64(sc.portSize)
+	perm := cyclic.New(o.Targets.Size()*portSize, o.Seed)
+	for i, more := perm.Next(); more; i, more = perm.Next() {
+		host := o.Targets.At(i / portSize)
+
+		// ip:port形式的目标不扫描，直接添加
+		if util.IsHostPort(host) {
+			if i%portSize == 0 {
+				sc.pass(c, o.Output, host)
+			}
+			sc.completed.Add(1)
+			continue
+		}
+
+		if err := sc.submit(c, o.Output, wg, host, sc.portsSlice[i%portSize]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanStream 流水线模式：逐个接收上游发现的主机，按随机顺序扫描其所有端口
+func (sc *portScannerV3) scanStream(c context.Context, o *Options[target.Source], wg *sync.WaitGroup) error {
+	for n := int64(0); ; n++ {
+		var item target.Item
+		var more bool
 		select {
 		case <-c.Done():
-			return nil, context.Canceled
-		default:
+			return context.Canceled
+		case item, more = <-o.Input:
+		}
+		if !more {
+			return nil
+		}
+
+		sc.total.Add(sc.portSize)
+
+		if util.IsHostPort(item.Target) {
+			sc.pass(c, o.Output, item.Target)
+			sc.completed.Add(sc.portSize)
+			continue
+		}
+
+		perm := cyclic.New(uint64(sc.portSize), o.Seed+n)
+		for i, more := perm.Next(); more; i, more = perm.Next() {
+			if err := sc.submit(c, o.Output, wg, item.Target, sc.portsSlice[i]); err != nil {
+				return err
+			}
 		}
+	}
+}
+
+// pass 直接添加ip:port形式的目标
+func (sc *portScannerV3) pass(c context.Context, out chan<- target.Item, hostPort string) {
+	result := sc.newResult(hostPort, util.ProtocolTCP)
+	sc.m.Lock()
+	sc.targets[portKey{hostPort: hostPort, protocol: util.ProtocolTCP}] = result
+	sc.m.Unlock()
+
+	if sc.itemCallback != nil {
+		sc.itemCallback(c, result)
+	}
+
+	emit(c, out, target.Item{Target: hostPort})
+}
+
+// submit 提交单个端口的检测任务，开放的TCP端口实时输出到out
+func (sc *portScannerV3) submit(c context.Context, out chan<- target.Item, wg *sync.WaitGroup, host string, port util.Port) error {
+	select {
+	case <-c.Done():
+		return context.Canceled
+	default:
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port.Port))
+
+	// 暂停时等待继续
+	if err := sc.pause.Wait(c); err != nil {
+		return err
+	}
+
+	wg.Add(1)
+	sc.rl.Take()
+	sc.pool.Submit(func() {
+		defer wg.Done()
+		defer sc.completed.Add(1)
+		defer sc.congestion.Done(host)
 
-		for _, port := range sc.portsSlice {
+		open := false
+		for range sc.retries {
 
 			select {
 			case <-c.Done():
-				return nil, context.Canceled
+				return
 			default:
 			}
 
-			addr := fmt.Sprintf("%s:%d", target, port)
-
-			wg.Add(1)
-			sc.rl.Take()
-			sc.pool.Submit(func() {
-				defer wg.Done()
-				defer sc.completed.Add(1)
-
-				var err error
-				for range sc.retries {
-
-					select {
-					case <-c.Done():
-						return
-					default:
-					}
-
-					err = sc.checker.CheckAddr(addr, sc.timeout)
-					if err == nil {
-						break
-					}
-				}
-
-				select {
-				case <-c.Done():
-					return
-				default:
-				}
-
-				if err == nil {
-					sc.m.Lock()
-					_, contained := sc.targets[addr]
-					if !contained {
-						sc.targets[addr] = struct{}{}
-					}
-					sc.m.Unlock()
-
-					if !contained {
-						sc.exporter.Export(c, []any{target, port})
-					}
-				}
-			})
+			open = sc.check(c, host, port)
+			if open {
+				break
+			}
+		}
+
+		select {
+		case <-c.Done():
+			return
+		default:
+		}
+
+		if !open {
+			return
 		}
+
+		key := portKey{hostPort: addr, protocol: port.Protocol}
+		sc.m.Lock()
+		_, contained := sc.targets[key]
+		if !contained {
+			sc.targets[key] = nil
+		}
+		sc.m.Unlock()
+
+		if contained {
+			return
+		}
+
+		result := sc.newResult(addr, port.Protocol)
+		if sc.serviceDetection && port.Protocol == util.ProtocolTCP {
+			info := detectService(c, host, port.Port, sc.timeout)
+			result.Service = info.service
+			result.Product = info.product
+			result.Version = info.version
+			result.TLS = info.tls
+			result.Banner = info.banner
+		}
+
+		sc.m.Lock()
+		sc.targets[key] = result
+		sc.m.Unlock()
+
+		sc.exporter.Export(c, portRow(result))
+		if sc.itemCallback != nil {
+			sc.itemCallback(c, result)
+		}
+
+		// 后续任务模板基于TCP，仅输出TCP开放端口
+		if port.Protocol == util.ProtocolTCP {
+			emit(c, out, target.Item{Target: addr, Service: target.Service{Name: result.Service, TLS: result.TLS}})
+		}
+	})
+	return nil
+}
+
+func (sc *portScannerV3) newResult(hostPort, protocol string) *types.PortResultItem {
+	ip, port, _ := net.SplitHostPort(hostPort)
+	return &types.PortResultItem{
+		EntryID:  sc.entryID,
+		IP:       ip,
+		Port:     cast.ToInt(port),
+		HostPort: hostPort,
+		Protocol: protocol,
 	}
+}
 
-	wg.Wait()
+func portRow(result *types.PortResultItem) []any {
+	return []any{
+		result.IP, result.Port, result.Protocol,
+		result.Service, result.Product, result.Version, lo.If(result.TLS, "是").Else(""), result.Banner,
+	}
+}
 
-	select {
-	case <-c.Done():
-		return nil, context.Canceled
-	default:
+// check 检测端口是否开放
+//
+// TCP: 连接成功(SYN扫描收到SYN/ACK)即开放；UDP: 收到响应即开放，无响应(可能被过滤)或ICMP端口不可达均不记录
+func (sc *portScannerV3) check(c context.Context, host string, port util.Port) bool {
+	if err := sc.congestion.Wait(c, host); err != nil {
+		return false
 	}
 
-	return lo.Uniq(lo.Keys(sc.targets)), nil
+	// UDP无响应属于常态，不参与拥塞控制
+	if port.Protocol == util.ProtocolUDP {
+		return probeUDP(c, host, port.Port, sc.timeout) == udpOpen
+	}
+
+	timeout := sc.congestion.Timeout(host)
+	start := time.Now()
+
+	open, responded := sc.checkTCP(c, host, port.Port, timeout)
+	sc.congestion.Observe(host, port.Port, time.Since(start), responded)
+	return open
+}
+
+// tcpChecker 检测TCP端口，返回端口是否开放及主机是否响应(用于拥塞控制)
+type tcpChecker func(c context.Context, host string, port int, timeout time.Duration) (open, responded bool)
+
+// connect 全连接检测，连接被拒绝说明主机有响应，超时视为丢包
+func (sc *portScannerV3) connect(_ context.Context, host string, port int, timeout time.Duration) (bool, bool) {
+	err := sc.checker.CheckAddr(net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	return err == nil, err == nil || errors.Is(err, syscall.ECONNREFUSED)
 }
 
 func (sc *portScannerV3) progress(c context.Context, ok <-chan struct{}) {
@@ -290,7 +508,12 @@ func (sc *portScannerV3) progress(c context.Context, ok <-chan struct{}) {
 			return
 		case <-ticker.C:
 			sc.bar.Set64(sc.completed.Load())
-			sc.stageManager.Put(types.StagePortScanning, sc.bar.State().CurrentPercent)
+			sc.stageManager.Put(types.StagePortScanning, percent(sc.completed.Load(), sc.total.Load()))
 		}
 	}
 }
+
+// Adjust 运行中调整速率与并发数
+func (sc *portScannerV3) Adjust(rate, concurrency int) {
+	adjust(sc.rl, sc.pool, rate, concurrency)
+}
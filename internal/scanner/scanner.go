@@ -4,26 +4,56 @@ import (
 	"context"
 	"errors"
 
+	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
 	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/internal/util"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/panjf2000/ants/v2"
+	"github.com/projectdiscovery/ratelimit"
 )
 
 const (
 	top100   = "7,9,13,21-23,25-26,37,53,79-81,88,106,110-111,113,119,135,139,143-144,179,199,389,427,443-445,465,513-515,543-544,548,554,587,631,646,873,990,993,995,1025-1029,1110,1433,1720,1723,1755,1900,2000-2001,2049,2121,2717,3000,3128,3306,3389,3986,4899,5000,5009,5051,5060,5101,5190,5357,5432,5631,5666,5800,5900,6000-6001,6646,7070,8000,8008-8009,8080-8081,8443,8888,9100,9999-10000,32768,49152-49157"
 	top1000  = "1,3-4,6-7,9,13,17,19-26,30,32-33,37,42-43,49,53,70,79-85,88-90,99-100,106,109-111,113,119,125,135,139,143-144,146,161,163,179,199,211-212,222,254-256,259,264,280,301,306,311,340,366,389,406-407,416-417,425,427,443-445,458,464-465,481,497,500,512-515,524,541,543-545,548,554-555,563,587,593,616-617,625,631,636,646,648,666-668,683,687,691,700,705,711,714,720,722,726,749,765,777,783,787,800-801,808,843,873,880,888,898,900-903,911-912,981,987,990,992-993,995,999-1002,1007,1009-1011,1021-1100,1102,1104-1108,1110-1114,1117,1119,1121-1124,1126,1130-1132,1137-1138,1141,1145,1147-1149,1151-1152,1154,1163-1166,1169,1174-1175,1183,1185-1187,1192,1198-1199,1201,1213,1216-1218,1233-1234,1236,1244,1247-1248,1259,1271-1272,1277,1287,1296,1300-1301,1309-1311,1322,1328,1334,1352,1417,1433-1434,1443,1455,1461,1494,1500-1501,1503,1521,1524,1533,1556,1580,1583,1594,1600,1641,1658,1666,1687-1688,1700,1717-1721,1723,1755,1761,1782-1783,1801,1805,1812,1839-1840,1862-1864,1875,1900,1914,1935,1947,1971-1972,1974,1984,1998-2010,2013,2020-2022,2030,2033-2035,2038,2040-2043,2045-2049,2065,2068,2099-2100,2103,2105-2107,2111,2119,2121,2126,2135,2144,2160-2161,2170,2179,2190-2191,2196,2200,2222,2251,2260,2288,2301,2323,2366,2381-2383,2393-2394,2399,2401,2492,2500,2522,2525,2557,2601-2602,2604-2605,2607-2608,2638,2701-2702,2710,2717-2718,2725,2800,2809,2811,2869,2875,2909-2910,2920,2967-2968,2998,3000-3001,3003,3005-3007,3011,3013,3017,3030-3031,3052,3071,3077,3128,3168,3211,3221,3260-3261,3268-3269,3283,3300-3301,3306,3322-3325,3333,3351,3367,3369-3372,3389-3390,3404,3476,3493,3517,3527,3546,3551,3580,3659,3689-3690,3703,3737,3766,3784,3800-3801,3809,3814,3826-3828,3851,3869,3871,3878,3880,3889,3905,3914,3918,3920,3945,3971,3986,3995,3998,4000-4006,4045,4111,4125-4126,4129,4224,4242,4279,4321,4343,4443-4446,4449,4550,4567,4662,4848,4899-4900,4998,5000-5004,5009,5030,5033,5050-5051,5054,5060-5061,5080,5087,5100-5102,5120,5190,5200,5214,5221-5222,5225-5226,5269,5280,5298,5357,5405,5414,5431-5432,5440,5500,5510,5544,5550,5555,5560,5566,5631,5633,5666,5678-5679,5718,5730,5800-5802,5810-5811,5815,5822,5825,5850,5859,5862,5877,5900-5904,5906-5907,5910-5911,5915,5922,5925,5950,5952,5959-5963,5987-5989,5998-6007,6009,6025,6059,6100-6101,6106,6112,6123,6129,6156,6346,6389,6502,6510,6543,6547,6565-6567,6580,6646,6666-6669,6689,6692,6699,6779,6788-6789,6792,6839,6881,6901,6969,7000-7002,7004,7007,7019,7025,7070,7100,7103,7106,7200-7201,7402,7435,7443,7496,7512,7625,7627,7676,7741,7777-7778,7800,7911,7920-7921,7937-7938,7999-8002,8007-8011,8021-8022,8031,8042,8045,8080-8090,8093,8099-8100,8180-8181,8192-8194,8200,8222,8254,8290-8292,8300,8333,8383,8400,8402,8443,8500,8600,8649,8651-8652,8654,8701,8800,8873,8888,8899,8994,9000-9003,9009-9011,9040,9050,9071,9080-9081,9090-9091,9099-9103,9110-9111,9200,9207,9220,9290,9415,9418,9485,9500,9502-9503,9535,9575,9593-9595,9618,9666,9876-9878,9898,9900,9917,9929,9943-9944,9968,9998-10004,10009-10010,10012,10024-10025,10082,10180,10215,10243,10566,10616-10617,10621,10626,10628-10629,10778,11110-11111,11967,12000,12174,12265,12345,13456,13722,13782-13783,14000,14238,14441-14442,15000,15002-15004,15660,15742,16000-16001,16012,16016,16018,16080,16113,16992-16993,17877,17988,18040,18101,18988,19101,19283,19315,19350,19780,19801,19842,20000,20005,20031,20221-20222,20828,21571,22939,23502,24444,24800,25734-25735,26214,27000,27352-27353,27355-27356,27715,28201,30000,30718,30951,31038,31337,32768-32785,33354,33899,34571-34573,35500,38292,40193,40911,41511,42510,44176,44442-44443,44501,45100,48080,49152-49161,49163,49165,49167,49175-49176,49400,49999-50003,50006,50300,50389,50500,50636,50800,51103,51493,52673,52822,52848,52869,54045,54328,55055-55056,55555,55600,56737-56738,57294,57797,58080,60020,60443,61532,61900,62078,63331,64623,64680,65000,65129,65389"
 	httpPort = "80-99,443,888,1025-1030,2000,2001,2375,2379,4433,5000,5001,5190,5357,5432,5631,5666,5672,5800,5900,6000-6010,6060,6379,6443,6646,6666,7000-7010,7443,7070,7777,8000-8010,8080-8100,8443,8686,8800,8880-8888,9000-9010,9080,9090,9200,9443,9527,9999,10000-10010,10443,15805,18000-18010,18443,19000-19010,19999,22222,28000-28010,29000-29010,38000-38010,39000-39010,48000-48010,49155"
+	dnsName  = "域名解析"
 	pingName = "在线检测"
 	portName = "端口扫描"
+	certName = "证书采集"
+	webName  = "Web指纹"
 )
 
+// 端口扫描方式
+const (
+	PortScanModeConnect = "connect" // 全连接
+	PortScanModeSYN     = "syn"     // 半开放(SYN)，需要raw socket权限，仅Linux
+)
+
+// BuiltinPortSets 内置命名端口集合
+var BuiltinPortSets = util.PortSets{
+	"top100":  top100,
+	"top1000": top1000,
+	"http":    httpPort,
+}
+
 var (
-	pingHeader = []any{"主机", "存活", "系统", "TTL"}
-	portHeader = []any{"主机", "端口"}
+	dnsHeader  = []any{"域名", "IP"}
+	pingHeader = []any{"主机", "存活", "系统", "TTL", "方式", "初始TTL", "最小延迟(ms)", "平均延迟(ms)", "丢包率(%)"}
+	certHeader = []any{"主机", "端口", "SNI", "主题", "备用名称", "颁发者", "生效时间", "过期时间", "已过期", "密钥算法", "密钥长度", "弱密钥", "签名算法", "弱签名", "自签名", "TLS版本", "SHA256指纹"}
+	webHeader  = []any{"主机", "端口", "URL", "状态码", "标题", "Server", "跳转链", "Favicon Hash", "指纹", "关联漏洞"}
+	portHeader = []any{"主机", "端口", "协议", "服务", "产品", "版本", "TLS", "Banner"}
 )
 
 var (
-	ErrPortOuputSupport = errors.New("unsupport port scanning output format")
-	ErrHostOuputSupport = errors.New("unsupport host discovery output format")
+	ErrPortOuputSupport    = errors.New("unsupport port scanning output format")
+	ErrHostOuputSupport    = errors.New("unsupport host discovery output format")
+	ErrHostDiscoveryMethod = errors.New("unsupport host discovery method")
+	ErrDNSOuputSupport     = errors.New("unsupport dns resolution output format")
+	ErrCertOuputSupport    = errors.New("unsupport certificate collection output format")
+	ErrWebOuputSupport     = errors.New("unsupport web fingerprint output format")
+	ErrPortScanMode        = errors.New("unsupport port scanning mode")
 )
 
 // Scanner 扫描器接口
@@ -31,12 +61,75 @@ type Scanner[T, U any] interface {
 	Scan(c context.Context, opts *Options[T]) (U, error)
 }
 
+// adjust 运行中调整速率(每秒)与并发数，小于等于0的值不调整
+//
+// 限流器周期固定为1秒，从下个周期起按新速率发放令牌；goroutine池扩容立即生效，缩容在执行中的任务完成后生效
+func adjust(rl *ratelimit.Limiter, pool *ants.Pool, rate, concurrency int) {
+	if rate > 0 {
+		rl.SetLimit(uint(rate))
+	}
+	if concurrency > 0 {
+		pool.Tune(concurrency)
+	}
+}
+
 type Options[T any] struct {
 	Targets T
+	Seed    int64 // 扫描顺序随机种子
+
+	// 流水线模式
+	Input  <-chan target.Item // 上游阶段实时发现的目标(非nil时替代Targets)，上游结束后关闭
+	Output chan<- target.Item // 实时输出发现的目标，通道已满时阻塞(背压)，由调用方关闭
+}
+
+// emit 向下游输出目标，未开启流水线模式时忽略
+func emit(c context.Context, out chan<- target.Item, item target.Item) {
+	if out == nil {
+		return
+	}
+	select {
+	case <-c.Done():
+	case out <- item:
+	}
+}
+
+// percent 计算进度，总数未知(流水线模式尚未接收目标)时为0
+func percent(completed, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return min(float64(completed)/float64(total), 1)
+}
+
+// ServiceTargets 端口扫描后按服务处理的目标(证书采集、Web指纹识别)
+type ServiceTargets struct {
+	Targets   target.Source    // 目标(非ip:port目标使用服务默认端口)
+	Services  target.Services  // 端口扫描识别的服务
+	Hostnames target.Hostnames // IP对应的域名
+}
+
+// DNSResolverConfig 域名解析配置
+type DNSResolverConfig struct {
+	Resolvers      []string
+	Timeout        string
+	Count          int
+	Format         string
+	RateLimit      int
+	Concurrency    int
+	EntryID        string
+	ResultCallback types.DNSResultCallback
+	Silent         bool
+	NoProgressbar  bool // 仅禁用进度条，日志照常输出
+	Directory      string
+	StageManager   *stage.Manager
+	Pause          *pause.Controller // 暂停控制
 }
 
 // HostDiscovererConfig 在线检测配置
 type HostDiscovererConfig struct {
+	Methods        []string
+	TCPPorts       string
+	UDPPorts       string
 	Timeout        string
 	Count          int
 	Format         string
@@ -44,22 +137,69 @@ type HostDiscovererConfig struct {
 	Concurrency    int
 	EntryID        string
 	ResultCallback types.PingResultCallback
+	ItemCallback   types.PingItemCallback // 存活主机实时回调
 	Silent         bool
+	NoProgressbar  bool // 仅禁用进度条，日志照常输出
 	Directory      string
 	StageManager   *stage.Manager
+	Pause          *pause.Controller // 暂停控制
 }
 
 // PortScannerConfig 端口扫描配置
 type PortScannerConfig struct {
-	Ports          string
+	Ports            string
+	ExcludePorts     string // 排除端口
+	PortSets         string // 自定义命名端口集合文件
+	Mode             string // 扫描方式(connect,syn)
+	HostRateLimit    int    // 单主机最大速率(包/秒)
+	Adaptive         bool   // 按主机响应自适应调整速率与超时
+	ServiceDetection bool   // 开放端口服务识别
+	Timeout          string
+	Count            int
+	Format           string
+	RateLimit        int
+	Concurrency      int
+	EntryID          string
+	ResultCallback   types.PortResultCallback
+	ItemCallback     types.PortItemCallback // 开放端口实时回调
+	Silent           bool
+	NoProgressbar    bool // 仅禁用进度条，日志照常输出
+	Directory        string
+	StageManager     *stage.Manager
+	Pause            *pause.Controller // 暂停控制
+}
+
+// CertCollectorConfig 证书采集配置
+type CertCollectorConfig struct {
+	Timeout        string
+	Count          int
+	Format         string
+	RateLimit      int
+	Concurrency    int
+	EntryID        string
+	ResultCallback types.CertResultCallback
+	Silent         bool
+	NoProgressbar  bool // 仅禁用进度条，日志照常输出
+	Directory      string
+	StageManager   *stage.Manager
+	Pause          *pause.Controller // 暂停控制
+}
+
+// WebFingerprinterConfig Web指纹识别配置
+type WebFingerprinterConfig struct {
 	Timeout        string
 	Count          int
 	Format         string
 	RateLimit      int
 	Concurrency    int
+	Fingerprints   string
+	VulnMapper     *vuln.Mapper
 	EntryID        string
-	ResultCallback types.PortResultCallback
+	ResultCallback types.WebResultCallback
+	ItemCallback   types.JobItemCallback // 命中指纹及映射漏洞实时回调
 	Silent         bool
+	NoProgressbar  bool // 仅禁用进度条，日志照常输出
 	Directory      string
 	StageManager   *stage.Manager
+	Pause          *pause.Controller // 暂停控制
 }
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/panjf2000/ants/v2"
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjust(t *testing.T) {
+	assert := assert.New(t)
+
+	rl := ratelimit.New(context.Background(), 10, time.Second)
+	defer rl.Stop()
+	pool, err := ants.NewPool(5)
+	assert.NoError(err)
+	defer pool.Release()
+
+	// 小于等于0的值不调整
+	adjust(rl, pool, 0, 0)
+	assert.Equal(5, pool.Cap())
+	assert.EqualValues(10, rl.GetLimit())
+
+	adjust(rl, pool, 100, 0)
+	assert.Equal(5, pool.Cap())
+	assert.EqualValues(100, rl.GetLimit())
+
+	adjust(rl, pool, 0, 20)
+	assert.Equal(20, pool.Cap())
+	assert.EqualValues(100, rl.GetLimit())
+
+	// 扫描获取令牌时调整(配合-race检测)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 50 {
+			rl.Take()
+		}
+	}()
+	for i := range 50 {
+		adjust(rl, pool, 1000+i, 0)
+	}
+	<-done
+	assert.EqualValues(1049, rl.GetLimit())
+}
+
+func TestProbeAny(t *testing.T) {
+	assert := assert.New(t)
+
+	// 顺序探测，首个成功后不再继续
+	var probed []int
+	alive := probeAny(context.Background(), 5, func(_ context.Context, i int) bool {
+		probed = append(probed, i)
+		return i == 2
+	})
+	assert.True(alive)
+	assert.Equal([]int{0, 1, 2}, probed)
+
+	probed = nil
+	assert.False(probeAny(context.Background(), 3, func(_ context.Context, i int) bool {
+		probed = append(probed, i)
+		return false
+	}))
+	assert.Equal([]int{0, 1, 2}, probed)
+
+	assert.False(probeAny(context.Background(), 0, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(probeAny(ctx, 3, func(context.Context, int) bool { return true }))
+}
@@ -0,0 +1,247 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/samber/lo"
+)
+
+// 服务名称
+const (
+	serviceHTTP   = "http"
+	serviceTLS    = "ssl"
+	serviceMySQL  = "mysql"
+	serviceTelnet = "telnet"
+)
+
+// bannerMatcher 根据服务端主动发送的banner识别服务
+//
+// 正则中的product/version命名分组分别作为产品与版本
+type bannerMatcher struct {
+	service string
+	product string // 固定产品名称(正则未提取product时使用)
+	re      *regexp.Regexp
+}
+
+var bannerMatchers = []*bannerMatcher{
+	{service: "ssh", re: regexp.MustCompile(`^SSH-[\d.]+-(?P<product>[A-Za-z]+)[_-]?(?P<version>[\w.]*)`)},
+	{service: "ftp", re: regexp.MustCompile(`(?i)^220[ -].*?(?P<product>vsFTPd|ProFTPD|Pure-FTPd|FileZilla Server|Microsoft FTP Service|Serv-U)[ /]*(?:version )?v?(?P<version>[\d.]*)`)},
+	{service: "smtp", re: regexp.MustCompile(`(?i)^220[ -].*?(?P<product>Postfix|Exim|Sendmail|Microsoft ESMTP MAIL Service)[ /]*(?P<version>[\d.]*)`)},
+	{service: "smtp", re: regexp.MustCompile(`(?i)^220[ -].*SMTP`)},
+	{service: "ftp", re: regexp.MustCompile(`^220[ -]`)},
+	{service: "pop3", re: regexp.MustCompile(`^\+OK`)},
+	{service: "imap", re: regexp.MustCompile(`^\* OK`)},
+	{service: "vnc", product: "VNC", re: regexp.MustCompile(`^RFB (?P<version>\d+\.\d+)`)},
+}
+
+// serverHeaderRegexp 解析HTTP Server头中的产品与版本，如nginx/1.18.0
+var serverHeaderRegexp = regexp.MustCompile(`^(?P<product>[^/\s]+)(?:/(?P<version>[^\s]+))?`)
+
+// mysqlVersionRegexp MySQL握手包中的版本
+var mysqlVersionRegexp = regexp.MustCompile(`^\d+\.\d+\.\d+[\w.-]*`)
+
+// serviceInfo 端口服务识别结果
+type serviceInfo struct {
+	service string
+	product string
+	version string
+	tls     bool
+	banner  string
+}
+
+// detectService 识别TCP端口服务
+//
+// 依次尝试：读取服务端主动发送的banner、TLS握手(成功后在TLS内探测HTTP)、明文HTTP请求
+func detectService(c context.Context, host string, port int, timeout time.Duration) *serviceInfo {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+
+	banner, err := grab(c, addr, timeout, nil)
+	if err != nil {
+		return &serviceInfo{}
+	}
+	if len(banner) != 0 {
+		return matchBanner(banner)
+	}
+
+	if info := detectTLS(c, host, addr, timeout); info != nil {
+		return info
+	}
+
+	response, err := grab(c, addr, timeout, httpRequest(host))
+	if err != nil || len(response) == 0 {
+		return &serviceInfo{}
+	}
+	// 明文请求得到TLS alert，说明为TLS端口(如要求客户端证书)
+	if response[0] == 0x15 && len(response) > 1 && response[1] == 0x03 {
+		return &serviceInfo{service: serviceTLS, tls: true}
+	}
+	if info := matchHTTP(response); info != nil {
+		return info
+	}
+	return matchBanner(response)
+}
+
+// grab 建立连接，发送probe(可为空)并读取响应
+func grab(c context.Context, addr string, timeout time.Duration, probe []byte) ([]byte, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(c, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return exchange(conn, timeout, probe), nil
+}
+
+// exchange 在已建立的连接上发送probe并读取响应，超时或出错时返回已读取的内容
+func exchange(conn net.Conn, timeout time.Duration, probe []byte) []byte {
+	conn.SetDeadline(time.Now().Add(timeout))
+	if len(probe) != 0 {
+		if _, err := conn.Write(probe); err != nil {
+			return nil
+		}
+	}
+
+	buf := make([]byte, 2048)
+	n, _ := io.ReadAtLeast(conn, buf, 1)
+	return buf[:n]
+}
+
+// detectTLS TLS握手成功则在TLS连接内探测HTTP，非TLS端口返回nil
+func detectTLS(c context.Context, host, addr string, timeout time.Duration) *serviceInfo {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: timeout},
+		Config: &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         lo.If(util.IsIP(host), "").Else(host),
+		},
+	}
+	conn, err := dialer.DialContext(c, "tcp", addr)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	response := exchange(conn, timeout, httpRequest(host))
+	info := matchHTTP(response)
+	if info == nil {
+		info = &serviceInfo{service: serviceTLS}
+		if len(response) != 0 {
+			info = matchBanner(response)
+		}
+	}
+	info.tls = true
+	return info
+}
+
+// httpRequest 构造HTTP探测请求
+func httpRequest(host string) []byte {
+	return []byte(fmt.Sprintf("GET / HTTP/1.0\r\nHost: %s\r\nUser-Agent: Mozilla/5.0\r\nAccept: */*\r\n\r\n", util.FormatHost(host)))
+}
+
+// matchHTTP 解析HTTP响应，非HTTP响应返回nil
+func matchHTTP(response []byte) *serviceInfo {
+	if !bytes.HasPrefix(response, []byte("HTTP/")) {
+		return nil
+	}
+
+	info := &serviceInfo{service: serviceHTTP, banner: printable(firstLine(response))}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(response)), nil)
+	if err != nil {
+		return info
+	}
+	resp.Body.Close()
+
+	if server := resp.Header.Get("Server"); server != "" {
+		info.product, info.version = submatch(serverHeaderRegexp, server)
+	}
+	return info
+}
+
+// matchBanner 根据banner识别服务，无法识别时仅记录banner
+func matchBanner(banner []byte) *serviceInfo {
+	if version, ok := mysqlVersion(banner); ok {
+		return &serviceInfo{service: serviceMySQL, product: "MySQL", version: version, banner: version}
+	}
+
+	// telnet协商以IAC(0xff)加WILL/WONT/DO/DONT开头
+	if len(banner) > 1 && banner[0] == 0xff && banner[1] >= 0xfb && banner[1] <= 0xfe {
+		return &serviceInfo{service: serviceTelnet}
+	}
+
+	info := &serviceInfo{banner: printable(firstLine(banner))}
+	for _, m := range bannerMatchers {
+		if !m.re.Match(banner) {
+			continue
+		}
+		info.service = m.service
+		info.product, info.version = submatch(m.re, string(banner))
+		if info.product == "" {
+			info.product = m.product
+		}
+		break
+	}
+	return info
+}
+
+// mysqlVersion 解析MySQL握手包(3字节长度+1字节序号+协议版本10+以\0结尾的版本号)
+func mysqlVersion(packet []byte) (string, bool) {
+	if len(packet) < 6 || packet[4] != 0x0a {
+		return "", false
+	}
+	end := bytes.IndexByte(packet[5:], 0)
+	if end < 0 {
+		return "", false
+	}
+	version := mysqlVersionRegexp.Find(packet[5 : 5+end])
+	return string(version), version != nil
+}
+
+// submatch 提取product/version命名分组
+func submatch(re *regexp.Regexp, s string) (product, version string) {
+	match := re.FindStringSubmatch(s)
+	if match == nil {
+		return "", ""
+	}
+	if i := re.SubexpIndex("product"); i > 0 {
+		product = match[i]
+	}
+	if i := re.SubexpIndex("version"); i > 0 {
+		version = match[i]
+	}
+	return product, version
+}
+
+func firstLine(b []byte) []byte {
+	if i := bytes.IndexAny(b, "\r\n"); i >= 0 {
+		return b[:i]
+	}
+	return b
+}
+
+// printable 去除不可打印字符并截断
+func printable(b []byte) string {
+	runes := []rune(strings.Map(func(r rune) rune {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, string(b)))
+	if len(runes) > 256 {
+		runes = runes[:256]
+	}
+	return string(runes)
+}
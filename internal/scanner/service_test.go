@@ -0,0 +1,212 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchBanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		banner  []byte
+		service string
+		product string
+		version string
+	}{
+		{
+			name:    "ssh",
+			banner:  []byte("SSH-2.0-OpenSSH_8.9p1 Ubuntu-3\r\n"),
+			service: "ssh",
+			product: "OpenSSH",
+			version: "8.9p1",
+		},
+		{
+			name:    "vsftpd",
+			banner:  []byte("220 (vsFTPd 3.0.3)\r\n"),
+			service: "ftp",
+			product: "vsFTPd",
+			version: "3.0.3",
+		},
+		{
+			name:    "unknown ftp",
+			banner:  []byte("220 Welcome\r\n"),
+			service: "ftp",
+		},
+		{
+			name:    "postfix",
+			banner:  []byte("220 mail.example.com ESMTP Postfix\r\n"),
+			service: "smtp",
+			product: "Postfix",
+		},
+		{
+			name:    "unknown smtp",
+			banner:  []byte("220 mail.example.com ESMTP ready\r\n"),
+			service: "smtp",
+		},
+		{
+			name:    "pop3",
+			banner:  []byte("+OK POP3 ready\r\n"),
+			service: "pop3",
+		},
+		{
+			name:    "imap",
+			banner:  []byte("* OK IMAP4rev1 ready\r\n"),
+			service: "imap",
+		},
+		{
+			name:    "vnc",
+			banner:  []byte("RFB 003.008\n"),
+			service: "vnc",
+			product: "VNC",
+			version: "003.008",
+		},
+		{
+			name:    "mysql",
+			banner:  append([]byte{0x4a, 0x00, 0x00, 0x00, 0x0a}, []byte("8.0.36-0ubuntu0.22.04.1\x00rest")...),
+			service: serviceMySQL,
+			product: "MySQL",
+			version: "8.0.36-0ubuntu0.22.04.1",
+		},
+		{
+			name:    "telnet",
+			banner:  []byte{0xff, 0xfd, 0x18, 0xff, 0xfd, 0x20},
+			service: serviceTelnet,
+		},
+		{
+			name:   "unknown",
+			banner: []byte("hello\r\nworld"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			info := matchBanner(tt.banner)
+			assert.Equal(tt.service, info.service)
+			assert.Equal(tt.product, info.product)
+			assert.Equal(tt.version, info.version)
+			assert.False(info.tls)
+		})
+	}
+
+	// 无法识别时记录首行可打印内容
+	info := matchBanner([]byte("hello\x01\r\nworld"))
+	assert.Equal(t, "hello", info.banner)
+}
+
+func TestMatchHTTP(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(matchHTTP([]byte("SSH-2.0-OpenSSH_8.9p1\r\n")))
+
+	info := matchHTTP([]byte("HTTP/1.1 200 OK\r\nServer: nginx/1.18.0\r\nContent-Length: 0\r\n\r\n"))
+	if assert.NotNil(info) {
+		assert.Equal(serviceHTTP, info.service)
+		assert.Equal("nginx", info.product)
+		assert.Equal("1.18.0", info.version)
+		assert.Equal("HTTP/1.1 200 OK", info.banner)
+	}
+}
+
+// serve 监听本地端口，每个连接由handle处理
+func serve(t *testing.T, handle func(conn net.Conn)) (string, int) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return splitAddr(t, l.Addr().String())
+}
+
+func splitAddr(t *testing.T, addr string) (string, int) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+func TestDetectService(t *testing.T) {
+	const timeout = 500 * time.Millisecond
+	c := context.Background()
+
+	t.Run("banner", func(t *testing.T) {
+		assert := assert.New(t)
+
+		// 服务端主动发送banner时不再发送探测请求
+		host, port := serve(t, func(conn net.Conn) {
+			conn.Write([]byte("SSH-2.0-OpenSSH_8.9p1\r\n"))
+			time.Sleep(timeout)
+		})
+		info := detectService(c, host, port, timeout)
+		assert.Equal("ssh", info.service)
+		assert.Equal("OpenSSH", info.product)
+		assert.False(info.tls)
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		assert := assert.New(t)
+
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "nginx/1.25.0")
+		}))
+		defer server.Close()
+
+		host, port := splitAddr(t, server.Listener.Addr().String())
+		info := detectService(c, host, port, timeout)
+		assert.Equal(serviceHTTP, info.service)
+		assert.Equal("nginx", info.product)
+		assert.Equal("1.25.0", info.version)
+		assert.True(info.tls)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		assert := assert.New(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "Apache")
+		}))
+		defer server.Close()
+
+		host, port := splitAddr(t, server.Listener.Addr().String())
+		info := detectService(c, host, port, timeout)
+		assert.Equal(serviceHTTP, info.service)
+		assert.Equal("Apache", info.product)
+		assert.Empty(info.version)
+		assert.False(info.tls)
+	})
+
+	t.Run("silent", func(t *testing.T) {
+		assert := assert.New(t)
+
+		// 无任何响应的端口仅标记为开放
+		host, port := serve(t, func(conn net.Conn) {
+			time.Sleep(3 * timeout)
+		})
+		info := detectService(c, host, port, timeout)
+		assert.Empty(info.service)
+		assert.Empty(info.banner)
+	})
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// udpPayloads 常见UDP服务的探测载荷(其余端口发送空载荷)
+var udpPayloads = map[int][]byte{
+	// DNS: 查询根域NS记录
+	53: []byte("\x12\x34\x01\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x01"),
+	// NTP: v3 client请求
+	123: append([]byte{0x1b}, make([]byte, 47)...),
+	// NetBIOS: NBSTAT查询
+	137: []byte("\x80\xf0\x00\x10\x00\x01\x00\x00\x00\x00\x00\x00\x20CKAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA\x00\x00\x21\x00\x01"),
+	// SNMP: v1 public get sysDescr.0
+	161: []byte("\x30\x29\x02\x01\x00\x04\x06public\xa0\x1c\x02\x04\x12\x34\x56\x78\x02\x01\x00\x02\x01\x00\x30\x0e\x30\x0c\x06\x08\x2b\x06\x01\x02\x01\x01\x01\x00\x05\x00"),
+	// IKE: v1 main mode SA提议(3DES/SHA1/PSK/DH2)
+	500: []byte("\x11\x22\x33\x44\x55\x66\x77\x88\x00\x00\x00\x00\x00\x00\x00\x00\x01\x10\x02\x00\x00\x00\x00\x00\x00\x00\x00\x54" +
+		"\x00\x00\x00\x38\x00\x00\x00\x01\x00\x00\x00\x01\x00\x00\x00\x2c\x01\x01\x00\x01\x00\x00\x00\x24\x01\x01\x00\x00" +
+		"\x80\x01\x00\x05\x80\x02\x00\x02\x80\x03\x00\x01\x80\x04\x00\x02\x80\x0b\x00\x01\x00\x0c\x00\x04\x00\x00\x70\x80"),
+	// SSDP: M-SEARCH
+	1900: []byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 1\r\nST: ssdp:all\r\n\r\n"),
+}
+
+// UDP探测结果
+type udpState int
+
+const (
+	udpNoResponse  udpState = iota // 无响应(开放或被过滤)
+	udpOpen                        // 收到响应
+	udpUnreachable                 // ICMP端口不可达
+)
+
+// probeUDP 向host:port发送协议载荷并等待响应
+//
+// 已连接的UDP socket在收到ICMP端口不可达后，读写会返回ECONNREFUSED
+func probeUDP(c context.Context, host string, port int, timeout time.Duration) udpState {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(c, "udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return udpNoResponse
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	payload, ok := udpPayloads[port]
+	if !ok {
+		payload = []byte{0}
+	}
+	if _, err := conn.Write(payload); err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return udpUnreachable
+		}
+		return udpNoResponse
+	}
+
+	buf := make([]byte, 512)
+	_, err = conn.Read(buf)
+	switch {
+	case err == nil:
+		return udpOpen
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return udpUnreachable
+	default:
+		return udpNoResponse
+	}
+}
@@ -0,0 +1,511 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/export"
+	"github.com/EscapeBearSecond/falcon/internal/fingerprint"
+	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	"github.com/EscapeBearSecond/falcon/internal/mmh3"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
+	"github.com/EscapeBearSecond/falcon/internal/stage"
+	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/EscapeBearSecond/falcon/internal/util/log"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/panjf2000/ants/v2"
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/samber/lo"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cast"
+)
+
+var _ Scanner[*ServiceTargets, []*types.WebResultItem] = (*webFingerprinter)(nil)
+
+const (
+	maxRedirects = 5
+	maxBodySize  = 1 << 20
+)
+
+var (
+	titleRegexp = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	iconRegexp  = regexp.MustCompile(`(?is)<link[^>]+rel=["']?[^"'>]*icon[^>]*>`)
+	hrefRegexp  = regexp.MustCompile(`(?is)href=["']?([^"'\s>]+)`)
+)
+
+// webTarget 待识别的Web服务
+type webTarget struct {
+	hostPort string
+	schemes  []string // 依次尝试，取第一个有响应的scheme
+}
+
+// dialPin 请求域名时固定连接的IP
+type dialPin struct {
+	hostname string
+	ip       string
+}
+
+type (
+	dialPinKey   struct{}
+	redirectsKey struct{}
+)
+
+// webFingerprinter Web指纹识别
+type webFingerprinter struct {
+	name         string
+	entryID      string
+	timeout      time.Duration
+	retries      int
+	client       *http.Client
+	db           *fingerprint.Database
+	vulnMapper   *vuln.Mapper
+	exporter     export.Exporter
+	logger       *slog.Logger
+	rl           *ratelimit.Limiter
+	pool         *ants.Pool
+	m            sync.Mutex
+	results      []*types.WebResultItem
+	findings     []*types.JobResultItem
+	bar          *progressbar.ProgressBar
+	callback     types.WebResultCallback
+	itemCallback types.JobItemCallback
+	silent       bool
+	noBar        bool // 仅禁用进度条
+	stageManager *stage.Manager
+	pause        *pause.Controller
+	completed    *atomic.Int64
+}
+
+// NewWebFingerprinter 实例化Web指纹识别
+func NewWebFingerprinter(cfg *WebFingerprinterConfig) (Scanner[*ServiceTargets, []*types.WebResultItem], error) {
+	duration, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid web fingerprint timeout: %w", err)
+	}
+
+	db, err := fingerprint.New(cfg.Fingerprints)
+	if err != nil {
+		return nil, err
+	}
+
+	wf := &webFingerprinter{
+		name:         webName,
+		entryID:      cfg.EntryID,
+		timeout:      duration,
+		retries:      max(cfg.Count, 1),
+		db:           db,
+		vulnMapper:   cfg.VulnMapper,
+		callback:     cfg.ResultCallback,
+		itemCallback: cfg.ItemCallback,
+		silent:       cfg.Silent,
+		noBar:        cfg.NoProgressbar,
+		stageManager: cfg.StageManager,
+		pause:        cfg.Pause,
+		completed:    &atomic.Int64{},
+		rl:           ratelimit.New(context.Background(), uint(cfg.RateLimit), 1*time.Second),
+	}
+	wf.client = &http.Client{
+		Timeout: duration,
+		Transport: &http.Transport{
+			DialContext: wf.dial,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+				MinVersion:         tls.VersionTLS10,
+			},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if redirects, ok := req.Context().Value(redirectsKey{}).(*[]string); ok {
+				*redirects = append(*redirects, req.URL.String())
+			}
+			if len(via) >= maxRedirects {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	if wf.silent {
+		wf.logger = log.Must(log.NewLogger(log.WithSilent(true)))
+	} else {
+		wf.logger = log.Must(log.NewLogger(log.WithStdout()))
+	}
+
+	switch cfg.Format {
+	case "csv":
+		exporter, err := export.NewCsvExporter(filepath.Join(cfg.Directory, cfg.EntryID, wf.name), webHeader...)
+		if err != nil {
+			return nil, err
+		}
+		wf.exporter = exporter
+	case "excel":
+		exporter, err := export.NewExcelExporter(filepath.Join(cfg.Directory, cfg.EntryID, wf.name), webHeader...)
+		if err != nil {
+			return nil, err
+		}
+		wf.exporter = exporter
+	default:
+		return nil, ErrWebOuputSupport
+	}
+
+	pool, err := ants.NewPool(cfg.Concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("create web fingerprinter routine pool failed: %w", err)
+	}
+	wf.pool = pool
+
+	return wf, nil
+}
+
+func (wf *webFingerprinter) Scan(c context.Context, o *Options[*ServiceTargets]) ([]*types.WebResultItem, error) {
+	wf.logger.InfoContext(c, "Running web fingerprint", "fingerprints", wf.db.Len())
+	results, err := wf.scan(c, o)
+	if err != nil {
+		return nil, err
+	}
+	wf.logger.InfoContext(c, "Web fingerprint completed")
+
+	wf.doCallback(c)
+
+	return results, nil
+}
+
+func (wf *webFingerprinter) doCallback(c context.Context) error {
+	if wf.callback != nil {
+		err := wf.callback(c, &types.WebResult{EntryID: wf.entryID, Items: wf.results, Findings: wf.findings})
+		if err != nil {
+			return fmt.Errorf("web fingerprint callback failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// scan 请求目标的Web服务并匹配指纹
+func (wf *webFingerprinter) scan(c context.Context, o *Options[*ServiceTargets]) ([]*types.WebResultItem, error) {
+	defer wf.exporter.Close()
+	defer wf.pool.Release()
+	defer wf.rl.Stop()
+
+	wf.results = make([]*types.WebResultItem, 0)
+	wf.findings = make([]*types.JobResultItem, 0)
+
+	wf.bar = util.NewProgressbar(wf.name, int64(o.Targets.Targets.Size()), wf.silent || wf.noBar)
+
+	ok := make(chan struct{})
+	defer close(ok)
+	go wf.progress(c, ok)
+
+	wg := sync.WaitGroup{}
+	it := o.Targets.Targets.Iterator()
+	for t, more := it.Next(); more; t, more = it.Next() {
+		select {
+		case <-c.Done():
+			return nil, context.Canceled
+		default:
+		}
+
+		webTargets := webTargetsOf(t, o.Targets)
+		if len(webTargets) == 0 {
+			wf.completed.Add(1)
+			continue
+		}
+
+		// 暂停时等待继续
+		if err := wf.pause.Wait(c); err != nil {
+			return nil, err
+		}
+
+		wg.Add(1)
+		wf.rl.Take()
+		wf.pool.Submit(func() {
+			defer wg.Done()
+			defer wf.completed.Add(1)
+
+			for _, wt := range webTargets {
+				host, _, _ := net.SplitHostPort(wt.hostPort)
+				hostnames := o.Targets.Hostnames.Lookup(host)
+				if len(hostnames) == 0 {
+					hostnames = []string{""}
+				}
+
+				for _, hostname := range hostnames {
+					result := wf.identify(c, wt, hostname)
+
+					select {
+					case <-c.Done():
+						return
+					default:
+					}
+
+					if result == nil {
+						continue
+					}
+
+					wf.exporter.Export(c, webRow(result))
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+
+	select {
+	case <-c.Done():
+		return nil, context.Canceled
+	default:
+	}
+
+	return wf.results, nil
+}
+
+// webTargetsOf 获取目标需要识别的Web服务
+//
+// ip:port目标按端口扫描识别的服务确定scheme，未识别时依次尝试https、http；
+// 非ip:port目标使用80、443端口；已识别的非HTTP服务跳过
+func webTargetsOf(t string, st *ServiceTargets) []*webTarget {
+	var candidates []*webTarget
+	if util.IsHostPort(t) {
+		candidates = []*webTarget{{hostPort: t, schemes: []string{"https", "http"}}}
+	} else {
+		candidates = []*webTarget{
+			{hostPort: net.JoinHostPort(t, "80"), schemes: []string{"http"}},
+			{hostPort: net.JoinHostPort(t, "443"), schemes: []string{"https"}},
+		}
+	}
+
+	webTargets := make([]*webTarget, 0, len(candidates))
+	for _, candidate := range candidates {
+		if schemes := st.Services.Schemes(candidate.hostPort); schemes != nil {
+			candidate.schemes = schemes
+		}
+		if len(candidate.schemes) != 0 {
+			webTargets = append(webTargets, candidate)
+		}
+	}
+	return webTargets
+}
+
+// identify 请求Web服务并匹配指纹，无响应返回nil
+func (wf *webFingerprinter) identify(c context.Context, wt *webTarget, hostname string) *types.WebResultItem {
+	ip, port, _ := net.SplitHostPort(wt.hostPort)
+	if hostname != "" {
+		c = context.WithValue(c, dialPinKey{}, &dialPin{hostname: hostname, ip: ip})
+	}
+
+	for range wf.retries {
+		for _, scheme := range wt.schemes {
+			select {
+			case <-c.Done():
+				return nil
+			default:
+			}
+
+			rawURL := fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(lo.If(hostname != "", hostname).Else(ip), port))
+			result, resp := wf.fetch(c, rawURL)
+			if result == nil {
+				continue
+			}
+
+			result.EntryID = wf.entryID
+			result.IP = ip
+			result.Port = cast.ToInt(port)
+			result.HostPort = wt.hostPort
+			wf.match(c, result, resp, scheme)
+			return result
+		}
+	}
+	return nil
+}
+
+// fetch 请求首页及favicon
+func (wf *webFingerprinter) fetch(c context.Context, rawURL string) (*types.WebResultItem, *fingerprint.Response) {
+	redirects := make([]string, 0)
+	resp, body, err := wf.get(context.WithValue(c, redirectsKey{}, &redirects), rawURL)
+	if err != nil {
+		return nil, nil
+	}
+
+	header := &bytes.Buffer{}
+	resp.Header.Write(header)
+
+	fr := &fingerprint.Response{
+		StatusCode: resp.StatusCode,
+		Title:      title(body),
+		Server:     resp.Header.Get("Server"),
+		Header:     header.String(),
+		Body:       string(body),
+	}
+	result := &types.WebResultItem{
+		URL:        rawURL,
+		StatusCode: fr.StatusCode,
+		Title:      fr.Title,
+		Server:     fr.Server,
+		Redirects:  redirects,
+	}
+
+	iconURL := resp.Request.URL.ResolveReference(&url.URL{Path: "/favicon.ico"})
+	if href := iconHref(body); href != "" {
+		if ref, err := url.Parse(href); err == nil {
+			iconURL = resp.Request.URL.ResolveReference(ref)
+		}
+	}
+	if iconResp, icon, err := wf.get(c, iconURL.String()); err == nil && iconResp.StatusCode == http.StatusOK && len(icon) != 0 {
+		// 与fofa/shodan一致，使用每76个字符换行的base64
+		hash := mmh3.Sum(icon, mmh3.Base64PyEncode)
+		result.FaviconHash = &hash
+		fr.FaviconHash, fr.HasFavicon = hash, true
+	}
+
+	return result, fr
+}
+
+func (wf *webFingerprinter) get(c context.Context, rawURL string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(c, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("Accept", "*/*")
+
+	resp, err := wf.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	return resp, body, nil
+}
+
+// dial 请求域名时连接解析得到的IP，跳转到其他域名时正常解析
+func (wf *webFingerprinter) dial(c context.Context, network, addr string) (net.Conn, error) {
+	if pin, ok := c.Value(dialPinKey{}).(*dialPin); ok {
+		if host, port, err := net.SplitHostPort(addr); err == nil && strings.EqualFold(host, pin.hostname) {
+			addr = net.JoinHostPort(pin.ip, port)
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: wf.timeout}
+	return dialer.DialContext(c, network, addr)
+}
+
+// match 匹配指纹，并按指纹ID与版本映射漏洞
+func (wf *webFingerprinter) match(c context.Context, result *types.WebResultItem, resp *fingerprint.Response, scheme string) {
+	findings := make([]*types.JobResultItem, 0)
+	for _, m := range wf.db.Match(resp) {
+		result.Fingerprints = append(result.Fingerprints, m.String())
+
+		finding := types.NewJobResultItem().WithEntryID(wf.entryID)
+		finding.TemplateID = m.ID
+		finding.TemplateName = m.Name
+		finding.Type = "http"
+		finding.Severity = "info"
+		finding.Host = result.IP
+		finding.Port = cast.ToString(result.Port)
+		finding.Scheme = scheme
+		finding.URL = result.URL
+		finding.Matched = result.URL
+		finding.Tags = "fingerprint"
+		if m.Version != "" {
+			finding.ExtractedResults = []string{m.Version}
+		}
+		findings = append(findings, finding)
+
+		if wf.vulnMapper == nil || m.Version == "" {
+			continue
+		}
+		dests, err := wf.vulnMapper.Get(m.ID).By(m.Version)
+		if err != nil {
+			wf.logger.WarnContext(c, "Get Vulnerability Mappings Failed", "fingerprint", m.ID, "version", m.Version, "reason", err.Error())
+			wf.stageManager.Warn(types.StageWebFingerprint, fmt.Sprintf("get vulnerability mappings of fingerprint [%s] failed: %s", m.ID, err))
+			continue
+		}
+		for _, dest := range dests {
+			mapped := *finding
+			findings = append(findings, dest.Assign(&mapped))
+			result.Vulnerabilities = append(result.Vulnerabilities, dest.ID)
+		}
+	}
+
+	wf.m.Lock()
+	wf.results = append(wf.results, result)
+	wf.findings = append(wf.findings, findings...)
+	wf.m.Unlock()
+
+	for _, finding := range findings {
+		if wf.itemCallback != nil {
+			wf.itemCallback(c, finding)
+		}
+		wf.stageManager.Finding(types.StageWebFingerprint, finding)
+	}
+}
+
+// title 提取页面标题
+func title(body []byte) string {
+	match := titleRegexp.FindSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	return printable([]byte(strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")))
+}
+
+// iconHref 提取页面声明的favicon地址
+func iconHref(body []byte) string {
+	link := iconRegexp.Find(body)
+	if link == nil {
+		return ""
+	}
+	match := hrefRegexp.FindSubmatch(link)
+	if match == nil {
+		return ""
+	}
+	return html.UnescapeString(string(match[1]))
+}
+
+func webRow(result *types.WebResultItem) []any {
+	var faviconHash string
+	if result.FaviconHash != nil {
+		faviconHash = cast.ToString(*result.FaviconHash)
+	}
+	return []any{
+		result.IP, result.Port, result.URL, result.StatusCode, result.Title, result.Server,
+		strings.Join(result.Redirects, ","), faviconHash, strings.Join(result.Fingerprints, ","), strings.Join(result.Vulnerabilities, ","),
+	}
+}
+
+func (wf *webFingerprinter) progress(c context.Context, ok <-chan struct{}) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-ok:
+			wf.bar.Finish()
+			wf.stageManager.Put(types.StageWebFingerprint, 1)
+			return
+		case <-ticker.C:
+			wf.bar.Set64(wf.completed.Load())
+			wf.stageManager.Put(types.StageWebFingerprint, wf.bar.State().CurrentPercent)
+		}
+	}
+}
+
+// Adjust 运行中调整速率与并发数
+func (wf *webFingerprinter) Adjust(rate, concurrency int) {
+	adjust(wf.rl, wf.pool, rate, concurrency)
+}
@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/mmh3"
+	"github.com/EscapeBearSecond/falcon/internal/target"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTitle(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Demo & Test", title([]byte("<html><head><TITLE lang=\"en\">\n  Demo &amp;\n Test </TITLE></head></html>")))
+	assert.Equal("", title([]byte("<html><body>no title</body></html>")))
+}
+
+func TestIconHref(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("/static/icon.png?v=1&t=2", iconHref([]byte(`<link rel="shortcut icon" href="/static/icon.png?v=1&amp;t=2">`)))
+	assert.Equal("favicon.svg", iconHref([]byte(`<link type=image/svg+xml rel=icon href=favicon.svg>`)))
+	assert.Equal("", iconHref([]byte(`<link rel="stylesheet" href="/main.css">`)))
+}
+
+func TestWebTargetsOf(t *testing.T) {
+	assert := assert.New(t)
+
+	st := &ServiceTargets{Services: target.Services{
+		"192.168.1.2:8443": {Name: "http", TLS: true},
+		"192.168.1.2:22":   {Name: "ssh"},
+		"192.168.1.3:443":  {Name: "ssh"},
+	}}
+
+	// 未识别的端口依次尝试https、http
+	webTargets := webTargetsOf("192.168.1.2:8080", st)
+	if assert.Len(webTargets, 1) {
+		assert.Equal([]string{"https", "http"}, webTargets[0].schemes)
+	}
+	webTargets = webTargetsOf("192.168.1.2:8443", st)
+	if assert.Len(webTargets, 1) {
+		assert.Equal([]string{"https"}, webTargets[0].schemes)
+	}
+	// 已识别的非HTTP服务跳过
+	assert.Empty(webTargetsOf("192.168.1.2:22", st))
+	// 非ip:port目标使用80、443端口
+	webTargets = webTargetsOf("192.168.1.3", st)
+	if assert.Len(webTargets, 1) {
+		assert.Equal("192.168.1.3:80", webTargets[0].hostPort)
+		assert.Equal([]string{"http"}, webTargets[0].schemes)
+	}
+}
+
+func TestWebFingerprint(t *testing.T) {
+	assert := assert.New(t)
+
+	icon := []byte("icon")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.18.0")
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/home", http.StatusFound)
+		case "/home":
+			w.Write([]byte(`<html><head><title>Demo</title><link rel="icon" href="/static/icon.png"></head></html>`))
+		case "/static/icon.png":
+			w.Write(icon)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	hostPort := strings.TrimPrefix(server.URL, "http://")
+
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "entry"), 0755))
+	fingerprints := filepath.Join(dir, "fingerprints.yaml")
+	assert.NoError(os.WriteFile(fingerprints, []byte(`- id: nginx
+  name: Nginx
+  matchers:
+    - server: nginx
+  versions:
+    - part: server
+      regex: 'nginx/([\d.]+)'
+
+- id: demo
+  name: Demo
+  matchers:
+    - title: Demo
+      favicon: [`+strconv.Itoa(int(mmh3.Sum(icon, mmh3.Base64PyEncode)))+`]
+`), 0644))
+
+	var result *types.WebResult
+	wf, err := NewWebFingerprinter(&WebFingerprinterConfig{
+		Timeout:      "2s",
+		Count:        1,
+		Format:       "csv",
+		RateLimit:    10,
+		Concurrency:  1,
+		Fingerprints: fingerprints,
+		EntryID:      "entry",
+		Silent:       true,
+		Directory:    dir,
+		ResultCallback: func(_ context.Context, wr *types.WebResult) error {
+			result = wr
+			return nil
+		},
+	})
+	assert.NoError(err)
+
+	items, err := wf.Scan(context.Background(), &Options[*ServiceTargets]{Targets: &ServiceTargets{Targets: target.Slice{hostPort}}})
+	assert.NoError(err)
+	if assert.Len(items, 1) {
+		item := items[0]
+		// https失败后使用http
+		assert.Equal(server.URL+"/", item.URL)
+		assert.Equal(http.StatusOK, item.StatusCode)
+		assert.Equal("Demo", item.Title)
+		assert.Equal("nginx/1.18.0", item.Server)
+		assert.Equal([]string{server.URL + "/home"}, item.Redirects)
+		if assert.NotNil(item.FaviconHash) {
+			assert.Equal(mmh3.Sum(icon, mmh3.Base64PyEncode), *item.FaviconHash)
+		}
+		assert.ElementsMatch([]string{"Nginx/1.18.0", "Demo"}, item.Fingerprints)
+	}
+
+	// 命中的指纹作为结果回调
+	if assert.NotNil(result) && assert.Len(result.Findings, 2) {
+		for _, finding := range result.Findings {
+			assert.Equal("entry", finding.EntryID)
+			assert.Equal("fingerprint", finding.Tags)
+			assert.Equal("http", finding.Scheme)
+		}
+	}
+}
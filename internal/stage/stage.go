@@ -1,14 +1,23 @@
 package stage
 
 import (
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/EscapeBearSecond/falcon/pkg/types"
 )
 
 type Manager struct {
-	m    sync.RWMutex
-	core types.Stage
+	m      sync.RWMutex
+	core   types.Stage
+	stages map[string]*state // 各阶段(任务按序号区分)的发布状态
+
+	sm       sync.RWMutex
+	subs     map[*subscription]struct{}
+	closed   bool
+	stopped  chan struct{} // 停止后发布不再阻塞(仅阻塞订阅)
+	stopOnce sync.Once
 }
 
 type Entry struct {
@@ -16,6 +25,20 @@ type Entry struct {
 	Value any
 }
 
+// state 阶段的发布状态
+type state struct {
+	percent  int // 已发布的进度(%)
+	finished bool
+}
+
+// subscription 事件订阅
+type subscription struct {
+	ch    chan types.Event
+	block bool // 缓冲已满时阻塞发布(背压)，否则丢弃
+	done  chan struct{}
+	once  sync.Once
+}
+
 func NewEntry(key types.StageEntryName, value any) Entry {
 	return Entry{
 		Key:   key,
@@ -24,16 +47,20 @@ func NewEntry(key types.StageEntryName, value any) Entry {
 }
 
 func NewManager() *Manager {
-	return &Manager{}
+	return &Manager{
+		stages:  make(map[string]*state),
+		subs:    make(map[*subscription]struct{}),
+		stopped: make(chan struct{}),
+	}
 }
 
+// Put 更新阶段进度，并发布阶段开始、进度及完成(进度为1)事件
 func (p *Manager) Put(name types.StageName, percent float64, entries ...Entry) {
 	if p == nil {
 		return
 	}
 
 	p.m.Lock()
-	defer p.m.Unlock()
 	var core types.Stage
 	core.Name = name
 	core.Entries = make(map[types.StageEntryName]any)
@@ -42,6 +69,31 @@ func (p *Manager) Put(name types.StageName, percent float64, entries ...Entry) {
 	}
 	core.Percent = percent
 	p.core = core
+
+	// 流水线模式下多个阶段交替更新，按阶段分别记录
+	var events []types.EventType
+	key := fmt.Sprintf("%s/%v", name, core.Entries[types.StageEntryJobIndex])
+	st, ok := p.stages[key]
+	if !ok {
+		st = &state{percent: -1}
+		p.stages[key] = st
+		events = append(events, types.EventStageStarted)
+	}
+	if !st.finished {
+		if current := int(percent * 100); current > st.percent {
+			st.percent = current
+			events = append(events, types.EventProgress)
+		}
+		if percent >= 1 {
+			st.finished = true
+			events = append(events, types.EventStageFinished)
+		}
+	}
+	p.m.Unlock()
+
+	for _, typ := range events {
+		p.Publish(types.Event{Type: typ, Stage: name, Percent: percent, Entries: core.Entries})
+	}
 }
 
 func (p *Manager) Get() types.Stage {
@@ -53,3 +105,138 @@ func (p *Manager) Get() types.Stage {
 	defer p.m.RUnlock()
 	return p.core
 }
+
+// Warn 发布警告事件
+func (p *Manager) Warn(name types.StageName, message string, entries ...Entry) {
+	p.publish(types.EventWarning, name, message, nil, entries...)
+}
+
+// Error 发布执行失败事件
+func (p *Manager) Error(name types.StageName, message string, entries ...Entry) {
+	p.publish(types.EventError, name, message, nil, entries...)
+}
+
+// Finding 发布命中结果事件
+func (p *Manager) Finding(name types.StageName, finding *types.JobResultItem, entries ...Entry) {
+	p.publish(types.EventFinding, name, "", finding, entries...)
+}
+
+func (p *Manager) publish(typ types.EventType, name types.StageName, message string, finding *types.JobResultItem, entries ...Entry) {
+	if p == nil {
+		return
+	}
+
+	event := types.Event{Type: typ, Stage: name, Message: message, Finding: finding}
+	if len(entries) != 0 {
+		event.Entries = make(map[types.StageEntryName]any)
+		for _, entry := range entries {
+			event.Entries[entry.Key] = entry.Value
+		}
+	}
+	p.Publish(event)
+}
+
+// Publish 向订阅者发布事件
+//
+// 订阅者缓冲已满时丢弃事件；阻塞订阅(SubscribeBlocking)的非进度与非执行失败事件阻塞直到被读取、取消订阅或停止
+func (p *Manager) Publish(event types.Event) {
+	if p == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	p.sm.RLock()
+	defer p.sm.RUnlock()
+
+	for sub := range p.subs {
+		if !sub.block || event.Type == types.EventProgress || event.Type == types.EventError {
+			select {
+			case sub.ch <- event:
+			default:
+			}
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		case <-sub.done:
+		case <-p.stopped:
+			select {
+			case sub.ch <- event:
+			default:
+			}
+		}
+	}
+}
+
+// Stop 停止阻塞发布(条目停止时调用)，之后的事件在订阅者缓冲已满时丢弃
+func (p *Manager) Stop() {
+	if p == nil {
+		return
+	}
+	p.stopOnce.Do(func() { close(p.stopped) })
+}
+
+// Subscribe 订阅事件，buffer为通道缓冲大小，缓冲已满时丢弃事件，不影响扫描；返回的函数取消订阅并关闭通道，Close后通道同样关闭
+func (p *Manager) Subscribe(buffer int) (<-chan types.Event, func()) {
+	return p.subscribe(buffer, false)
+}
+
+// SubscribeBlocking 同Subscribe，但缓冲已满时阻塞发布直到被读取(进度与执行失败事件仍丢弃)，需持续读取
+func (p *Manager) SubscribeBlocking(buffer int) (<-chan types.Event, func()) {
+	return p.subscribe(buffer, true)
+}
+
+func (p *Manager) subscribe(buffer int, block bool) (<-chan types.Event, func()) {
+	sub := &subscription{
+		ch:    make(chan types.Event, max(buffer, 0)),
+		block: block,
+		done:  make(chan struct{}),
+	}
+
+	if p == nil {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+
+	p.sm.Lock()
+	defer p.sm.Unlock()
+
+	if p.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	p.subs[sub] = struct{}{}
+
+	return sub.ch, func() {
+		// 先结束阻塞中的发布，再移除订阅
+		sub.once.Do(func() { close(sub.done) })
+
+		p.sm.Lock()
+		defer p.sm.Unlock()
+		if _, ok := p.subs[sub]; ok {
+			delete(p.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close 关闭所有订阅(条目执行结束时调用)
+func (p *Manager) Close() {
+	if p == nil {
+		return
+	}
+
+	// 先结束阻塞中的发布
+	p.Stop()
+
+	p.sm.Lock()
+	defer p.sm.Unlock()
+
+	p.closed = true
+	for sub := range p.subs {
+		delete(p.subs, sub)
+		close(sub.ch)
+	}
+}
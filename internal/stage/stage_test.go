@@ -17,3 +17,73 @@ func TestStage(t *testing.T) {
 	assert.Equal(types.StagePreExecute, stage.Name)
 	assert.Equal(float64(0), stage.Percent)
 }
+
+func TestSubscribe(t *testing.T) {
+	assert := assert.New(t)
+
+	manager := NewManager()
+	events, cancel := manager.Subscribe(16)
+	defer cancel()
+
+	manager.Put(types.StageHostDiscovery, 0)
+	manager.Put(types.StageHostDiscovery, 0.001)
+	manager.Put(types.StageJob, 0, NewEntry(types.StageEntryJobIndex, 0))
+	manager.Put(types.StageHostDiscovery, 0.5)
+	manager.Put(types.StageHostDiscovery, 1)
+	manager.Put(types.StageHostDiscovery, 1)
+	manager.Warn(types.StageJob, "skip headless templates", NewEntry(types.StageEntryJobIndex, 0))
+	manager.Close()
+
+	var got []types.EventType
+	for event := range events {
+		got = append(got, event.Type)
+	}
+	// 进度每增加1%发布一次，流水线模式下交替更新的阶段分别记录
+	assert.Equal([]types.EventType{
+		types.EventStageStarted, types.EventProgress,
+		types.EventStageStarted, types.EventProgress,
+		types.EventProgress,
+		types.EventProgress, types.EventStageFinished,
+		types.EventWarning,
+	}, got)
+
+	// 关闭后订阅的通道直接关闭
+	events, _ = manager.Subscribe(1)
+	_, ok := <-events
+	assert.False(ok)
+}
+
+func TestStop(t *testing.T) {
+	assert := assert.New(t)
+
+	manager := NewManager()
+	events, cancel := manager.SubscribeBlocking(1)
+	defer cancel()
+
+	// 停止后缓冲已满时丢弃，不阻塞发布
+	manager.Finding(types.StageJob, &types.JobResultItem{TemplateID: "t1"})
+	manager.Stop()
+	manager.Finding(types.StageJob, &types.JobResultItem{TemplateID: "t2"})
+
+	event := <-events
+	assert.Equal(types.EventFinding, event.Type)
+	assert.Equal("t1", event.Finding.TemplateID)
+	assert.False(event.Time.IsZero())
+	assert.Empty(events)
+}
+
+func TestSubscribeDrop(t *testing.T) {
+	assert := assert.New(t)
+
+	manager := NewManager()
+	events, cancel := manager.Subscribe(1)
+	defer cancel()
+
+	// 默认订阅缓冲已满时丢弃，不阻塞发布
+	manager.Finding(types.StageJob, &types.JobResultItem{TemplateID: "t1"})
+	manager.Finding(types.StageJob, &types.JobResultItem{TemplateID: "t2"})
+
+	event := <-events
+	assert.Equal("t1", event.Finding.TemplateID)
+	assert.Empty(events)
+}
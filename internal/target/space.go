@@ -0,0 +1,426 @@
+package target
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/EscapeBearSecond/falcon/internal/util"
+	"github.com/samber/lo"
+)
+
+// Source 可重复遍历的目标集合
+type Source interface {
+	// Size 目标数量
+	Size() uint64
+	// Iterator 创建新的迭代器(各迭代器互不影响)
+	Iterator() Iterator
+	// At 获取第i个目标(0 <= i < Size)，顺序与Iterator一致
+	At(i uint64) string
+}
+
+// Iterator 目标迭代器(非并发安全)
+type Iterator interface {
+	// Next 获取下一个目标，遍历结束返回false
+	Next() (string, bool)
+}
+
+var (
+	_ Source = Slice(nil)
+	_ Source = (*Space)(nil)
+)
+
+// Slice 已展开的目标列表(通常为各阶段的输出)
+type Slice []string
+
+func (s Slice) Size() uint64 {
+	return uint64(len(s))
+}
+
+func (s Slice) Iterator() Iterator {
+	return &sliceIterator{s: s}
+}
+
+func (s Slice) At(i uint64) string {
+	return s[i]
+}
+
+type sliceIterator struct {
+	s []string
+	i int
+}
+
+func (it *sliceIterator) Next() (string, bool) {
+	if it.i >= len(it.s) {
+		return "", false
+	}
+	it.i++
+	return it.s[it.i-1], true
+}
+
+// Space 惰性展开的目标空间
+//
+// CIDR/IPRange/IP以地址段保存，遍历时才计算具体地址；排除目标在构建时通过地址段相减完成
+type Space struct {
+	ranges    []addrRange // 合并并排除后的地址段(升序)
+	offsets   []uint64    // 各地址段第一个地址的序号
+	rangeSize uint64
+	hostPorts []string // ip:port
+	hostnames []string // domain或domain:port
+	size      uint64
+	exclude   *collection
+}
+
+// NewSpace 解析targets(优先作为目标处理，失败则作为文件处理)并排除excludeTargets
+func NewSpace(targets []string, excludeTargets ...string) (*Space, error) {
+	include := newCollection()
+	for _, t := range targets {
+		if err := include.add(t); err != nil {
+			return nil, err
+		}
+	}
+
+	exclude := newCollection()
+	for _, t := range excludeTargets {
+		if err := exclude.add(t); err != nil {
+			return nil, fmt.Errorf("process exclude targets failed: %w", err)
+		}
+	}
+	exclude.ranges = mergeRanges(exclude.ranges)
+
+	return newSpace(include, exclude), nil
+}
+
+func newSpace(include, exclude *collection) *Space {
+	s := &Space{
+		ranges: subtractRanges(mergeRanges(include.ranges), exclude.ranges),
+		hostPorts: lo.Filter(lo.Uniq(include.hostPorts), func(hostPort string, _ int) bool {
+			_, excluded := exclude.strs[hostPort]
+			return !excluded
+		}),
+		hostnames: lo.Filter(lo.Uniq(include.hostnames), func(hostname string, _ int) bool {
+			_, excluded := exclude.strs[hostname]
+			return !excluded
+		}),
+		exclude: exclude,
+	}
+
+	s.offsets = make([]uint64, 0, len(s.ranges))
+	for _, r := range s.ranges {
+		s.offsets = append(s.offsets, s.rangeSize)
+		s.rangeSize += r.size()
+	}
+	s.size = s.rangeSize + uint64(len(s.hostPorts)) + uint64(len(s.hostnames))
+
+	return s
+}
+
+// Size 目标数量
+func (s *Space) Size() uint64 {
+	return s.size
+}
+
+// Iterator 遍历顺序：地址段(升序)，ip:port，域名
+func (s *Space) Iterator() Iterator {
+	return &spaceIterator{s: s}
+}
+
+// At 获取第i个目标
+func (s *Space) At(i uint64) string {
+	if i >= s.rangeSize {
+		i -= s.rangeSize
+		if i < uint64(len(s.hostPorts)) {
+			return s.hostPorts[i]
+		}
+		return s.hostnames[i-uint64(len(s.hostPorts))]
+	}
+
+	// 查找包含第i个地址的地址段
+	k, found := slices.BinarySearch(s.offsets, i)
+	if !found {
+		k--
+	}
+	return s.ranges[k].start.add(i - s.offsets[k]).String()
+}
+
+// Hostnames 获取目标中的域名(去除端口并去重)
+func (s *Space) Hostnames() []string {
+	return lo.Uniq(lo.Map(s.hostnames, func(hostname string, _ int) string {
+		if host, _, err := net.SplitHostPort(hostname); err == nil {
+			return host
+		}
+		return hostname
+	}))
+}
+
+// Resolve 使用域名解析结果替换目标中的域名，未解析的域名将被丢弃
+func (s *Space) Resolve(records map[string][]string) *Space {
+	include := newCollection()
+	include.ranges = slices.Clone(s.ranges)
+	include.hostPorts = slices.Clone(s.hostPorts)
+
+	for _, hostname := range s.hostnames {
+		host, port, err := net.SplitHostPort(hostname)
+		if err != nil {
+			host = hostname
+		}
+		for _, ip := range records[host] {
+			if port != "" {
+				include.hostPorts = append(include.hostPorts, net.JoinHostPort(ip, port))
+				continue
+			}
+			a := toAddr(net.ParseIP(ip))
+			include.ranges = append(include.ranges, addrRange{start: a, end: a})
+		}
+	}
+
+	return newSpace(include, s.exclude)
+}
+
+type spaceIterator struct {
+	s        *Space
+	rangeIdx int
+	cur      addr128
+	started  bool
+	strIdx   int
+}
+
+func (it *spaceIterator) Next() (string, bool) {
+	for it.rangeIdx < len(it.s.ranges) {
+		r := it.s.ranges[it.rangeIdx]
+		if !it.started {
+			it.cur, it.started = r.start, true
+			return it.cur.String(), true
+		}
+		if it.cur.cmp(r.end) < 0 {
+			it.cur = it.cur.add(1)
+			return it.cur.String(), true
+		}
+		it.rangeIdx++
+		it.started = false
+	}
+
+	if it.strIdx < len(it.s.hostPorts) {
+		it.strIdx++
+		return it.s.hostPorts[it.strIdx-1], true
+	}
+
+	if idx := it.strIdx - len(it.s.hostPorts); idx < len(it.s.hostnames) {
+		it.strIdx++
+		return it.s.hostnames[idx], true
+	}
+
+	return "", false
+}
+
+// collection 解析后的目标
+type collection struct {
+	ranges    []addrRange
+	hostPorts []string
+	hostnames []string
+	strs      map[string]struct{}
+}
+
+func newCollection() *collection {
+	return &collection{strs: make(map[string]struct{})}
+}
+
+// add 添加目标，带FilePrefix前缀、为已存在的文件或无法作为目标解析时作为文件处理
+func (col *collection) add(target string) error {
+	target, isFile := strings.CutPrefix(target, FilePrefix)
+	if !isFile && !isHostnameFile(target) {
+		if err := col.addTarget(target); err == nil {
+			return nil
+		}
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("get target file stat failed: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("unsupported target file type: %s", target)
+	}
+	tf, err := os.Open(target)
+	if err != nil {
+		return fmt.Errorf("open target file failed: %w", err)
+	}
+	defer tf.Close()
+
+	scanner := bufio.NewScanner(tf)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		if err := col.addTarget(scanner.Text()); err != nil {
+			return fmt.Errorf("expand [%s] from target file failed: %w", scanner.Text(), err)
+		}
+	}
+	return scanner.Err()
+}
+
+// addTarget 添加单个目标(格式同Expand)
+func (col *collection) addTarget(target string) error {
+	switch {
+	case util.IsCIDR(target):
+		if !util.IsBoundedIPv6(target) {
+			return fmt.Errorf("ipv6 cidr too large: %s", target)
+		}
+		_, ipnet, _ := net.ParseCIDR(target)
+		start := toAddr(ipnet.IP)
+		ones, bits := ipnet.Mask.Size()
+		col.ranges = append(col.ranges, addrRange{start: start, end: start.add(1<<(bits-ones) - 1)})
+	case util.IsIPRange(target):
+		if !util.IsBoundedIPv6(target) {
+			return fmt.Errorf("ipv6 range too large: %s", target)
+		}
+		ipRange := strings.Split(target, "-")
+		col.ranges = append(col.ranges, addrRange{
+			start: toAddr(net.ParseIP(ipRange[0])),
+			end:   toAddr(net.ParseIP(ipRange[1])),
+		})
+	case util.IsIP(target):
+		a := toAddr(net.ParseIP(target))
+		col.ranges = append(col.ranges, addrRange{start: a, end: a})
+	default:
+		expands, err := Expand(target)
+		if err != nil {
+			return err
+		}
+		for _, t := range expands {
+			col.strs[t] = struct{}{}
+			if util.IsHostPort(t) {
+				col.hostPorts = append(col.hostPorts, t)
+			} else {
+				col.hostnames = append(col.hostnames, t)
+			}
+		}
+	}
+	return nil
+}
+
+// addr128 128位地址(IPv4使用IPv4-mapped IPv6表示)
+type addr128 struct {
+	hi, lo uint64
+}
+
+func toAddr(ip net.IP) addr128 {
+	ip16 := ip.To16()
+	return addr128{
+		hi: binary.BigEndian.Uint64(ip16[:8]),
+		lo: binary.BigEndian.Uint64(ip16[8:]),
+	}
+}
+
+func (a addr128) cmp(b addr128) int {
+	switch {
+	case a.hi < b.hi:
+		return -1
+	case a.hi > b.hi:
+		return 1
+	case a.lo < b.lo:
+		return -1
+	case a.lo > b.lo:
+		return 1
+	}
+	return 0
+}
+
+func (a addr128) add(n uint64) addr128 {
+	lo, carry := bits.Add64(a.lo, n, 0)
+	return addr128{hi: a.hi + carry, lo: lo}
+}
+
+func (a addr128) sub(n uint64) addr128 {
+	lo, borrow := bits.Sub64(a.lo, n, 0)
+	return addr128{hi: a.hi - borrow, lo: lo}
+}
+
+// isV4 是否为IPv4-mapped地址
+func (a addr128) isV4() bool {
+	return a.hi == 0 && a.lo>>32 == 0xffff
+}
+
+func (a addr128) String() string {
+	ip := make(net.IP, net.IPv6len)
+	binary.BigEndian.PutUint64(ip[:8], a.hi)
+	binary.BigEndian.PutUint64(ip[8:], a.lo)
+	return ip.String()
+}
+
+// addrRange 地址段[start, end]
+type addrRange struct {
+	start, end addr128
+}
+
+func (r addrRange) size() uint64 {
+	lo, borrow := bits.Sub64(r.end.lo, r.start.lo, 0)
+	if r.end.hi-r.start.hi-borrow != 0 || lo == math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return lo + 1
+}
+
+// mergeRanges 排序并合并重叠或相邻的地址段(不跨越IPv4/IPv6)
+func mergeRanges(ranges []addrRange) []addrRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b addrRange) int {
+		return a.start.cmp(b.start)
+	})
+
+	merged := make([]addrRange, 0, len(sorted))
+	cur := sorted[0]
+	for _, r := range sorted[1:] {
+		if cur.end.isV4() == r.start.isV4() && r.start.cmp(cur.end.add(1)) <= 0 {
+			if r.end.cmp(cur.end) > 0 {
+				cur.end = r.end
+			}
+			continue
+		}
+		merged = append(merged, cur)
+		cur = r
+	}
+	return append(merged, cur)
+}
+
+// subtractRanges 从已合并的地址段中减去已合并的排除地址段
+func subtractRanges(ranges, excludes []addrRange) []addrRange {
+	if len(excludes) == 0 {
+		return ranges
+	}
+
+	result := make([]addrRange, 0, len(ranges))
+	j := 0
+	for _, r := range ranges {
+		// 跳过在当前地址段之前的排除地址段
+		for j < len(excludes) && excludes[j].end.cmp(r.start) < 0 {
+			j++
+		}
+
+		cur := r
+		empty := false
+		for k := j; k < len(excludes) && excludes[k].start.cmp(cur.end) <= 0; k++ {
+			ex := excludes[k]
+			if ex.start.cmp(cur.start) > 0 {
+				result = append(result, addrRange{start: cur.start, end: ex.start.sub(1)})
+			}
+			if ex.end.cmp(cur.end) >= 0 {
+				empty = true
+				break
+			}
+			cur.start = ex.end.add(1)
+		}
+		if !empty {
+			result = append(result, cur)
+		}
+	}
+	return result
+}
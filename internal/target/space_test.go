@@ -0,0 +1,84 @@
+package target
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collect(s Source) []string {
+	results := make([]string, 0, s.Size())
+	it := s.Iterator()
+	for t, more := it.Next(); more; t, more = it.Next() {
+		results = append(results, t)
+	}
+	return results
+}
+
+func TestSpace(t *testing.T) {
+	assert := assert.New(t)
+
+	{
+		space, err := NewSpace([]string{
+			"192.168.1.1-192.168.1.5",
+			"192.168.2.0/24",
+			"192.168.1.4",
+			"192.168.4.234:8080",
+			"2001:db8::1-2001:db8::3",
+			"Example.com",
+		})
+		assert.NoError(err)
+		assert.Equal(uint64(5+256+1+3+1), space.Size())
+
+		targets := collect(space)
+		assert.Len(targets, int(space.Size()))
+		assert.Equal("192.168.1.1", targets[0])
+		assert.Contains(targets, "192.168.2.255")
+		assert.Contains(targets, "2001:db8::3")
+		assert.Contains(targets, "192.168.4.234:8080")
+		assert.Contains(targets, "example.com")
+
+		// 迭代器可重复创建
+		assert.Equal(targets, collect(space))
+
+		for i, target := range targets {
+			assert.Equal(target, space.At(uint64(i)))
+		}
+	}
+
+	{
+		space, err := NewSpace([]string{"10.0.0.0/8"}, "10.1.0.0/16", "10.0.0.0", "10.255.255.255")
+		assert.NoError(err)
+		assert.Equal(uint64(1<<24-1<<16-2), space.Size())
+
+		it := space.Iterator()
+		first, _ := it.Next()
+		assert.Equal("10.0.0.1", first)
+	}
+
+	{
+		space, err := NewSpace([]string{"192.168.1.0/30", "192.168.1.1:80"}, "192.168.1.1-192.168.1.2", "192.168.1.1:80")
+		assert.NoError(err)
+		assert.Equal([]string{"192.168.1.0", "192.168.1.3"}, collect(space))
+	}
+
+	{
+		_, err := NewSpace([]string{"2001:db8::/64"})
+		assert.Error(err)
+	}
+}
+
+func TestSpaceResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	space, err := NewSpace([]string{"192.168.1.1", "example.com", "api.example.com:8443", "none.example.com"}, "10.0.0.2")
+	assert.NoError(err)
+	assert.ElementsMatch([]string{"example.com", "api.example.com", "none.example.com"}, space.Hostnames())
+
+	resolved := space.Resolve(map[string][]string{
+		"example.com":     {"10.0.0.1", "10.0.0.2", "192.168.1.1"},
+		"api.example.com": {"2001:db8::1"},
+	})
+	assert.Equal([]string{"10.0.0.1", "192.168.1.1", "[2001:db8::1]:8443"}, collect(resolved))
+	assert.Empty(resolved.Hostnames())
+}
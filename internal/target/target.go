@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,16 +17,23 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-// Deprecated: use ProcessV2
+// FilePrefix 目标文件前缀，带前缀的目标(如@targets)始终作为文件处理；
+// 不带前缀时，已存在的文件(如targets.txt)或无法作为目标解析的目标同样作为文件处理
+const FilePrefix = "@"
+
+// ProcessSync 处理输入的targets（优先作为文件处理）
 //
-//	ProcessSync 处理输入的targets（优先作为文件处理）
+// Deprecated: use NewSpace
 func ProcessSync(targets []string, excludeTargets ...string) ([]string, error) {
 	resultTargets := make([]string, 0, 65536*len(targets))
 	for _, target := range targets {
-		expands, err := Expand(target)
-		if err == nil {
-			resultTargets = append(resultTargets, expands...)
-			continue
+		target, isFile := strings.CutPrefix(target, FilePrefix)
+		if !isFile && !isHostnameFile(target) {
+			expands, err := Expand(target)
+			if err == nil {
+				resultTargets = append(resultTargets, expands...)
+				continue
+			}
 		}
 
 		info, err := os.Stat(target)
@@ -71,6 +79,8 @@ func ProcessSync(targets []string, excludeTargets ...string) ([]string, error) {
 }
 
 // ProcessAsync 处理输入的targets（优化效率）
+//
+// Deprecated: use NewSpace
 func ProcessAsync(targets []string, excludeTargets ...string) ([]string, error) {
 	resultTargets := make([]string, 0, 65536*len(targets))
 
@@ -83,8 +93,8 @@ func ProcessAsync(targets []string, excludeTargets ...string) ([]string, error)
 				return nil
 			default:
 			}
-			expands, err := Expand(target)
-			if err == nil {
+			target, isFile := strings.CutPrefix(target, FilePrefix)
+			if expands, err := Expand(target); err == nil && !isFile && !isHostnameFile(target) {
 				select {
 				case <-ctx.Done():
 					return nil
@@ -168,31 +178,117 @@ func ProcessAsync(targets []string, excludeTargets ...string) ([]string, error)
 
 // Expand 扩展目标
 //
-// CIDR: 192.168.1.0/24
+// CIDR: 192.168.1.0/24, 2001:db8::/120
+//
+// IPRange: 192.168.1.1-192.168.2.3, 2001:db8::1-2001:db8::ff
 //
-// IPRange: 192.168.1.1-192.168.2.3
+// IP: 192.168.1.123, 2001:db8::1
 //
-// IP: 192.168.1.123
+// HostPort: 192.168.1.156:8090, [2001:db8::1]:8090
 //
-// HostPort: 192.168.1.156:8090
+// Domain: example.com
+//
+// DomainPort: app.example.com:8443
+//
+// IPv6的CIDR/IPRange最多展开util.MaxIPv6ExpandSize个地址
 func Expand(target string) ([]string, error) {
 	switch {
 	case util.IsCIDR(target):
+		if !util.IsBoundedIPv6(target) {
+			return nil, fmt.Errorf("ipv6 cidr too large: %s", target)
+		}
 		return util.ExpandCIDR(target), nil
 	case util.IsIPRange(target):
+		if !util.IsBoundedIPv6(target) {
+			return nil, fmt.Errorf("ipv6 range too large: %s", target)
+		}
 		return util.ExpandIPRange(target), nil
 	case util.IsIP(target):
-		return []string{target}, nil
+		return []string{net.ParseIP(target).String()}, nil
 	case util.IsHostPort(target):
-		return []string{target}, nil
+		host, port, _ := net.SplitHostPort(target)
+		return []string{net.JoinHostPort(net.ParseIP(host).String(), port)}, nil
+	case util.IsDomain(target):
+		return []string{strings.ToLower(strings.TrimSuffix(target, "."))}, nil
+	case util.IsDomainPort(target):
+		host, port, _ := net.SplitHostPort(target)
+		return []string{net.JoinHostPort(strings.ToLower(strings.TrimSuffix(host, ".")), port)}, nil
 	default:
 		return nil, fmt.Errorf("invalid target: %s", target)
 	}
 }
 
+// isHostnameFile 判断形如域名的目标是否为目标文件(如targets.txt)
+func isHostnameFile(target string) bool {
+	if !util.IsDomain(target) {
+		return false
+	}
+	info, err := os.Stat(target)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// IsHostname 判断目标是否为域名或domain:port
+func IsHostname(target string) bool {
+	return util.IsDomain(target) || util.IsDomainPort(target)
+}
+
+// Hostnames IP与域名的对应关系(由域名解析阶段生成)
+type Hostnames map[string][]string
+
+// Lookup 获取IP对应的域名
+func (h Hostnames) Lookup(ip string) []string {
+	if h == nil {
+		return nil
+	}
+	return h[ip]
+}
+
+// Add 添加IP对应的域名
+func (h Hostnames) Add(ip string, hostname string) {
+	if !lo.Contains(h[ip], hostname) {
+		h[ip] = append(h[ip], hostname)
+	}
+}
+
+// Service 端口服务(由端口扫描阶段识别)
+type Service struct {
+	Name string // 服务名称(http,ssh,ssl等)，为空表示未识别
+	TLS  bool
+}
+
+// Services ip:port与服务的对应关系
+type Services map[string]Service
+
+// Schemes 获取ip:port在http模板中使用的scheme
+//
+// 未识别的端口返回nil，由调用方使用默认规则；已识别的非HTTP服务返回空切片
+func (s Services) Schemes(hostPort string) []string {
+	service, ok := s[hostPort]
+	if !ok || service.Name == "" {
+		return nil
+	}
+
+	switch {
+	case service.Name == "http" && service.TLS:
+		return []string{"https"}
+	case service.Name == "http":
+		return []string{"http"}
+	case service.Name == "ssl":
+		// TLS内未识别出具体协议，仍尝试https
+		return []string{"https"}
+	}
+	return []string{}
+}
+
+// Item 流水线模式下在阶段之间传递的目标
+type Item struct {
+	Target  string
+	Service Service // 端口扫描识别的服务(未识别时为空)
+}
+
 func ShouldSkip(target string, ports ...string) bool {
-	// 如果ports不为空，并且target为ip:port格式
-	if util.IsHostPort(target) && len(ports) != 0 {
+	// 如果ports不为空，并且target为ip:port或domain:port格式
+	if (util.IsHostPort(target) || util.IsDomainPort(target)) && len(ports) != 0 {
 		// 获取target中的port值
 		_, port, _ := net.SplitHostPort(target)
 
@@ -226,6 +322,8 @@ func SplitBySize(targets []string, size uint32) ([][]string, error) {
 			sizeSum++
 		case util.IsHostPort(target):
 			sizeSum++
+		case IsHostname(target):
+			sizeSum++
 		default:
 		}
 	}
@@ -302,6 +400,8 @@ func SplitN(targets []string, n int) ([][]string, error) {
 				chs[r.Intn(n)] <- target
 			case util.IsHostPort(target):
 				chs[r.Intn(n)] <- target
+			case IsHostname(target):
+				chs[r.Intn(n)] <- target
 			default:
 			}
 		}
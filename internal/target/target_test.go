@@ -291,3 +291,116 @@ func TestSplitBySize(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal(1, len(results))
 }
+
+func TestExpandIPv6(t *testing.T) {
+	assert := assert.New(t)
+
+	targets, err := Expand("2001:DB8::1")
+	assert.NoError(err)
+	assert.Equal([]string{"2001:db8::1"}, targets)
+
+	targets, err = Expand("[2001:db8::1]:8080")
+	assert.NoError(err)
+	assert.Equal([]string{"[2001:db8::1]:8080"}, targets)
+
+	targets, err = Expand("2001:db8::/120")
+	assert.NoError(err)
+	assert.Len(targets, 256)
+
+	targets, err = Expand("2001:db8::1-2001:db8::10")
+	assert.NoError(err)
+	assert.Len(targets, 16)
+
+	_, err = Expand("2001:db8::/64")
+	assert.Error(err)
+
+	targets, err = ProcessAsync([]string{"2001:db8::/126", "[2001:db8::1]:80"}, "2001:db8::1")
+	assert.NoError(err)
+	assert.ElementsMatch([]string{"2001:db8::", "2001:db8::2", "2001:db8::3", "[2001:db8::1]:80"}, targets)
+
+	results, err := SplitN([]string{"2001:db8::/120"}, 2)
+	assert.NoError(err)
+	assert.ElementsMatch(results, [][]string{{"2001:db8::-2001:db8::7f"}, {"2001:db8::80-2001:db8::ff"}})
+}
+
+func TestExpandHostname(t *testing.T) {
+	assert := assert.New(t)
+
+	targets, err := Expand("Example.COM")
+	assert.NoError(err)
+	assert.Equal([]string{"example.com"}, targets)
+
+	targets, err = Expand("app.example.com:8443")
+	assert.NoError(err)
+	assert.Equal([]string{"app.example.com:8443"}, targets)
+
+	assert.False(ShouldSkip("app.example.com:8443", "8443"))
+	assert.True(ShouldSkip("app.example.com:8443", "80"))
+
+	hostnames := make(Hostnames)
+	hostnames.Add("1.1.1.1", "example.com")
+	hostnames.Add("1.1.1.1", "example.com")
+	hostnames.Add("1.1.1.1", "www.example.com")
+	assert.Equal([]string{"example.com", "www.example.com"}, hostnames.Lookup("1.1.1.1"))
+	assert.Nil(Hostnames(nil).Lookup("1.1.1.1"))
+
+	services := Services{
+		"1.1.1.1:8080": {Name: "http"},
+		"1.1.1.1:8443": {Name: "http", TLS: true},
+		"1.1.1.1:9443": {Name: "ssl", TLS: true},
+		"1.1.1.1:22":   {Name: "ssh"},
+		"1.1.1.1:9000": {},
+	}
+	assert.Equal([]string{"http"}, services.Schemes("1.1.1.1:8080"))
+	assert.Equal([]string{"https"}, services.Schemes("1.1.1.1:8443"))
+	assert.Equal([]string{"https"}, services.Schemes("1.1.1.1:9443"))
+	assert.Equal([]string{}, services.Schemes("1.1.1.1:22"))
+	assert.Nil(services.Schemes("1.1.1.1:9000"))
+	assert.Nil(services.Schemes("1.1.1.1:80"))
+	assert.Nil(Services(nil).Schemes("1.1.1.1:80"))
+}
+
+func TestProcessHostnameFile(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := os.MkdirTemp("", "test")
+	assert.NoError(err)
+	defer os.RemoveAll(tempDir)
+
+	wd, err := os.Getwd()
+	assert.NoError(err)
+	assert.NoError(os.Chdir(tempDir))
+	defer os.Chdir(wd)
+
+	err = os.WriteFile("targets.txt", []byte("example.com\n192.168.1.1"), 0644)
+	assert.NoError(err)
+
+	// 已存在的文件优先作为文件处理
+	targets, err := ProcessSync([]string{"targets.txt", "app.example.com:8443"})
+	assert.NoError(err)
+	assert.ElementsMatch([]string{"example.com", "192.168.1.1", "app.example.com:8443"}, targets)
+
+	// 前缀强制作为文件处理
+	targets, err = ProcessSync([]string{FilePrefix + "targets.txt"})
+	assert.NoError(err)
+	assert.ElementsMatch([]string{"example.com", "192.168.1.1"}, targets)
+
+	targets, err = ProcessAsync([]string{"targets.txt"})
+	assert.NoError(err)
+	assert.ElementsMatch([]string{"example.com", "192.168.1.1"}, targets)
+
+	for _, input := range []string{"targets.txt", FilePrefix + "targets.txt"} {
+		space, err := NewSpace([]string{input})
+		assert.NoError(err)
+		assert.EqualValues(2, space.Size())
+		assert.Equal([]string{"example.com"}, space.Hostnames())
+	}
+
+	// 不存在的文件名作为域名处理
+	targets, err = ProcessSync([]string{"none.txt"})
+	assert.NoError(err)
+	assert.Equal([]string{"none.txt"}, targets)
+
+	_, err = ProcessSync([]string{FilePrefix + "none.txt"})
+	assert.Error(err)
+}
@@ -0,0 +1,77 @@
+package tpl
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/projectdiscovery/nuclei/v3/pkg/templates"
+	"github.com/samber/lo"
+)
+
+// filter 模板过滤
+type filter struct {
+	types.TemplateFilterOptions
+}
+
+// matchPath 按路径过滤，path为相对模板目录的路径(函数加载时为模板ID)
+func (f *filter) matchPath(name string) bool {
+	name = filepath.ToSlash(name)
+	if matchGlobs(f.ExcludePaths, name) {
+		return false
+	}
+	return len(f.Paths) == 0 || matchGlobs(f.Paths, name)
+}
+
+// match 按模板信息过滤
+func (f *filter) match(template *templates.Template) bool {
+	tags := SplitTags(template.Info.Tags.ToSlice()...)
+	authors := SplitTags(template.Info.Authors.ToSlice()...)
+	severity := template.Info.SeverityHolder.Severity.String()
+	protocol := template.Type().String()
+
+	checks := []struct {
+		include []string
+		exclude []string
+		values  []string
+	}{
+		{f.Tags, f.ExcludeTags, tags},
+		{f.Severities, f.ExcludeSeverities, []string{severity}},
+		{f.IDs, f.ExcludeIDs, []string{strings.ToLower(template.ID)}},
+		{f.Authors, f.ExcludeAuthors, authors},
+		{f.Protocols, f.ExcludeProtocols, []string{protocol}},
+	}
+	for _, check := range checks {
+		if containsAny(check.exclude, check.values) {
+			return false
+		}
+		if len(check.include) != 0 && !containsAny(check.include, check.values) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny 过滤条件(忽略大小写)是否包含任一值
+func containsAny(conditions []string, values []string) bool {
+	return lo.SomeBy(SplitTags(conditions...), func(condition string) bool {
+		return lo.Contains(values, condition)
+	})
+}
+
+// matchGlobs 路径或其所在的任一目录匹配任一glob
+func matchGlobs(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		for p := name; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
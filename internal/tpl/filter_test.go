@@ -0,0 +1,81 @@
+package tpl
+
+import (
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/projectdiscovery/nuclei/v3/pkg/model"
+	"github.com/projectdiscovery/nuclei/v3/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v3/pkg/model/types/stringslice"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/http"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/network"
+	"github.com/projectdiscovery/nuclei/v3/pkg/templates"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	cve := &templates.Template{
+		ID: "CVE-2021-33044",
+		Info: model.Info{
+			Authors:        stringslice.StringSlice{Value: "gy741"},
+			Tags:           stringslice.StringSlice{Value: []string{"cve", "dahua", "auth-bypass"}},
+			SeverityHolder: severity.Holder{Severity: severity.Critical},
+		},
+		RequestsHTTP: []*http.Request{{}},
+	}
+	weak := &templates.Template{
+		ID: "ssh-weak-password",
+		Info: model.Info{
+			Authors:        stringslice.StringSlice{Value: "falcon"},
+			Tags:           stringslice.StringSlice{Value: "ssh,brute"},
+			SeverityHolder: severity.Holder{Severity: severity.High},
+		},
+		RequestsNetwork: []*network.Request{{}},
+	}
+
+	cases := []struct {
+		filter types.TemplateFilterOptions
+		cve    bool
+		weak   bool
+	}{
+		{types.TemplateFilterOptions{}, true, true},
+		{types.TemplateFilterOptions{Tags: []string{"CVE"}}, true, false},
+		{types.TemplateFilterOptions{ExcludeTags: []string{"brute"}}, true, false},
+		{types.TemplateFilterOptions{Severities: []string{"high"}}, false, true},
+		{types.TemplateFilterOptions{ExcludeSeverities: []string{"critical,high"}}, false, false},
+		{types.TemplateFilterOptions{IDs: []string{"cve-2021-33044"}}, true, false},
+		{types.TemplateFilterOptions{ExcludeIDs: []string{"ssh-weak-password"}}, true, false},
+		{types.TemplateFilterOptions{Authors: []string{"falcon"}}, false, true},
+		{types.TemplateFilterOptions{ExcludeAuthors: []string{"gy741"}}, false, true},
+		{types.TemplateFilterOptions{Protocols: []string{"tcp"}}, false, true},
+		{types.TemplateFilterOptions{ExcludeProtocols: []string{"tcp"}}, true, false},
+		// 排除优先
+		{types.TemplateFilterOptions{Tags: []string{"cve", "ssh"}, ExcludeSeverities: []string{"critical"}}, false, true},
+	}
+	for _, c := range cases {
+		f := &filter{c.filter}
+		assert.Equal(c.cve, f.match(cve), "%+v", c.filter)
+		assert.Equal(c.weak, f.match(weak), "%+v", c.filter)
+	}
+}
+
+func TestFilterMatchPath(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &filter{types.TemplateFilterOptions{}}
+	assert.True(f.matchPath("cves/2021/CVE-2021-33044.yaml"))
+
+	f = &filter{types.TemplateFilterOptions{Paths: []string{"cves/2021"}}}
+	assert.True(f.matchPath("cves/2021/CVE-2021-33044.yaml"))
+	assert.False(f.matchPath("cves/2022/CVE-2022-0001.yaml"))
+
+	f = &filter{types.TemplateFilterOptions{Paths: []string{"cves/*/"}, ExcludePaths: []string{"*-dos.yaml"}}}
+	assert.True(f.matchPath("cves/2021/CVE-2021-33044.yaml"))
+	assert.False(f.matchPath("cves/2021/CVE-2021-0001-dos.yaml"))
+	assert.False(f.matchPath("default-logins/ssh.yaml"))
+
+	f = &filter{types.TemplateFilterOptions{Paths: []string{"CVE-2021-*"}}}
+	assert.True(f.matchPath("CVE-2021-33044"))
+}
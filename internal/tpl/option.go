@@ -1,8 +1,11 @@
 package tpl
 
+import "github.com/EscapeBearSecond/falcon/pkg/types"
+
 // options 选项
 type options struct {
 	headless bool
+	filter   filter
 }
 
 // Option 选项函数
@@ -14,3 +17,10 @@ func WithEnableHeadless(headless bool) Option {
 		o.headless = headless
 	}
 }
+
+// WithFilter 配置模板过滤
+func WithFilter(f types.TemplateFilterOptions) Option {
+	return func(o *options) {
+		o.filter = filter{f}
+	}
+}
@@ -3,19 +3,27 @@ package tpl
 import (
 	"strings"
 
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/interactsh"
 	"github.com/projectdiscovery/nuclei/v3/pkg/templates"
+	ttypes "github.com/projectdiscovery/nuclei/v3/pkg/templates/types"
 	"github.com/projectdiscovery/nuclei/v3/pkg/types"
+	"github.com/projectdiscovery/nuclei/v3/pkg/workflows"
 )
 
 // POC nuclei的template
 type POC struct {
 	*templates.Template
+
+	interactsh bool // 工作流引用的子模板是否需要带外交互
 }
 
 type Result struct {
 	Pocs               []*POC
 	SkipHeadlessSize   int
 	SkipHeadlessReason string
+
+	SkipInteractshSize   int
+	SkipInteractshReason string
 }
 
 func (poc *POC) GetPorts() []string {
@@ -38,6 +46,25 @@ func (poc *POC) GetPorts() []string {
 	return ports
 }
 
+// GetTags 获取模板标签(小写)
+func (poc *POC) GetTags() []string {
+	return SplitTags(poc.Info.Tags.ToSlice()...)
+}
+
+// SplitTags 拆分逗号分隔的标签并转为小写
+func SplitTags(tags ...string) []string {
+	var result []string
+	for _, tag := range tags {
+		for _, t := range strings.Split(tag, ",") {
+			t = strings.ToLower(strings.TrimSpace(t))
+			if t != "" {
+				result = append(result, t)
+			}
+		}
+	}
+	return result
+}
+
 func (poc *POC) GetSchemes() []string {
 	var schemes []string
 
@@ -62,3 +89,42 @@ func (poc *POC) GetSchemes() []string {
 
 	return schemes
 }
+
+// IsWorkflow 是否为工作流模板
+func (poc *POC) IsWorkflow() bool {
+	return poc.CompiledWorkflow != nil
+}
+
+// UsesHTTP 模板(工作流模板为任一子模板)是否包含http/headless请求
+func (poc *POC) UsesHTTP() bool {
+	if len(poc.RequestsHTTP) > 0 || len(poc.RequestsHeadless) > 0 {
+		return true
+	}
+
+	var uses bool
+	if poc.IsWorkflow() {
+		walkWorkflow(poc.CompiledWorkflow.Workflows, func(executer *workflows.ProtocolExecuterPair) {
+			uses = uses || executer.TemplateType == ttypes.HTTPProtocol || executer.TemplateType == ttypes.HeadlessProtocol
+		})
+	}
+	return uses
+}
+
+// UsesInteractsh 模板是否需要带外交互(包含{{interactsh-url}})，工作流模板按引用的子模板判断
+func (poc *POC) UsesInteractsh() bool {
+	if poc.IsWorkflow() {
+		return poc.interactsh
+	}
+	return poc.Options != nil && interactsh.HasMarkers(string(poc.Options.RawTemplate))
+}
+
+// WorkflowSize 工作流中的子模板数量，非工作流模板为0
+func (poc *POC) WorkflowSize() int {
+	var size int
+	if poc.IsWorkflow() {
+		walkWorkflow(poc.CompiledWorkflow.Workflows, func(*workflows.ProtocolExecuterPair) {
+			size++
+		})
+	}
+	return size
+}
@@ -3,12 +3,13 @@ package tpl
 import (
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/EscapeBearSecond/falcon/pkg/types"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols"
 	"github.com/projectdiscovery/nuclei/v3/pkg/templates"
+	"github.com/samber/lo"
 )
 
 // LoadWithLoader 通过遍历template文件加载template
@@ -18,6 +19,11 @@ func LoadWithFileWalk(template string, eOptions *protocols.ExecutorOptions, opts
 		opt(&o)
 	}
 
+	// 工作流的子模板相对模板目录加载
+	loader := newFileWorkflowLoader(template)
+	wOptions := *eOptions
+	wOptions.WorkflowLoader = loader
+
 	result := &Result{}
 	err := filepath.WalkDir(template, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -28,26 +34,50 @@ func LoadWithFileWalk(template string, eOptions *protocols.ExecutorOptions, opts
 			return nil
 		}
 
-		template, err := templates.Parse(path, nil, *eOptions)
+		// 路径相对模板目录，模板为单个文件时使用文件名
+		rel, err := filepath.Rel(template, path)
+		if err != nil || rel == "." {
+			rel = filepath.Base(path)
+		}
+		if !o.filter.matchPath(rel) {
+			return nil
+		}
+
+		template, err := templates.Parse(path, nil, wOptions)
 		if err != nil {
 			return fmt.Errorf("template [%s] parse failed: %w", path, err)
 		}
+		// 工作流按引用的子模板判断是否需要带外交互
+		interactsh := loader.usesInteractsh()
+		if len(template.Options.RawTemplate) == 0 {
+			template.Options.RawTemplate, _ = os.ReadFile(path)
+		}
+		if isEmptyWorkflow(template) {
+			return fmt.Errorf("template [%s] parse failed: workflow references no templates", path)
+		}
+
+		if !o.filter.match(template) {
+			return nil
+		}
 
 		// 如果浏览器为nil或者不是headless模式，则不加载headless模版
 		if shouldSkipBrowser(template, eOptions, &o) {
 			result.SkipHeadlessSize++
 			return nil
 		}
-		// if shouldSkipInteractsh(template, eOptions, &o) {
-		// 	return nil
-		// }
+		// 未配置interactsh客户端时，不加载需要带外交互的模版
+		if shouldSkipInteractsh(template, eOptions) {
+			result.SkipInteractshSize++
+			return nil
+		}
 
-		result.Pocs = append(result.Pocs, &POC{template})
+		result.Pocs = append(result.Pocs, &POC{Template: template, interactsh: interactsh})
 		return nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create engine failed: %w", err)
 	}
+	result.Pocs = withoutReferenced(result.Pocs, loader)
 
 	if result.SkipHeadlessSize > 0 {
 		if eOptions.Browser == nil {
@@ -56,6 +86,9 @@ func LoadWithFileWalk(template string, eOptions *protocols.ExecutorOptions, opts
 			result.SkipHeadlessReason = "headless disabled"
 		}
 	}
+	if result.SkipInteractshSize > 0 {
+		result.SkipInteractshReason = "interactsh disabled"
+	}
 
 	return result, nil
 }
@@ -67,23 +100,53 @@ func LoadWithFunc(fn types.GetTemplates, eOptions *protocols.ExecutorOptions, op
 		opt(&o)
 	}
 
+	rts := fn()
+	raws := make(map[string]string, len(rts))
+	for _, rt := range rts {
+		raws[rt.ID] = rt.Original
+	}
+
+	// 工作流的子模板按模板ID引用，通过catalog读取原始模板
+	loader := newRawWorkflowLoader(raws)
+	wOptions := *eOptions
+	wOptions.WorkflowLoader = loader
+	wOptions.Catalog = &rawCatalog{Catalog: eOptions.Catalog, raws: raws}
+
 	result := &Result{}
-	for _, rt := range fn() {
-		template, err := templates.ParseTemplateFromReader(strings.NewReader(rt.Original), nil, *eOptions)
+	for _, rt := range rts {
+		if !o.filter.matchPath(rt.ID) {
+			continue
+		}
+
+		template, err := templates.Parse(rt.ID, nil, wOptions)
 		if err != nil {
 			return nil, fmt.Errorf("template [%s] parse failed: %w", rt.ID, err)
 		}
+		// 工作流按引用的子模板判断是否需要带外交互
+		interactsh := loader.usesInteractsh()
+		if len(template.Options.RawTemplate) == 0 {
+			template.Options.RawTemplate = []byte(rt.Original)
+		}
+		if isEmptyWorkflow(template) {
+			return nil, fmt.Errorf("template [%s] parse failed: workflow references no templates", rt.ID)
+		}
+
+		if !o.filter.match(template) {
+			continue
+		}
 
 		if shouldSkipBrowser(template, eOptions, &o) {
 			result.SkipHeadlessSize++
 			continue
 		}
-		// if shouldSkipInteractsh(template, eOptions, &o) {
-		// 	continue
-		// }
+		if shouldSkipInteractsh(template, eOptions) {
+			result.SkipInteractshSize++
+			continue
+		}
 
-		result.Pocs = append(result.Pocs, &POC{template})
+		result.Pocs = append(result.Pocs, &POC{Template: template, interactsh: interactsh})
 	}
+	result.Pocs = withoutReferenced(result.Pocs, loader)
 
 	if result.SkipHeadlessSize > 0 {
 		if eOptions.Browser == nil {
@@ -92,21 +155,25 @@ func LoadWithFunc(fn types.GetTemplates, eOptions *protocols.ExecutorOptions, op
 			result.SkipHeadlessReason = "headless disabled"
 		}
 	}
+	if result.SkipInteractshSize > 0 {
+		result.SkipInteractshReason = "interactsh disabled"
+	}
 
 	return result, nil
 }
 
+// withoutReferenced 移除被工作流引用的模板，仅在工作流中按条件执行
+func withoutReferenced(pocs []*POC, loader *workflowLoader) []*POC {
+	return lo.Reject(pocs, func(poc *POC, _ int) bool {
+		return loader.referenced(poc.Path)
+	})
+}
+
 func shouldSkipBrowser(template *templates.Template, eOptions *protocols.ExecutorOptions, o *options) bool {
 	return (eOptions.Browser == nil || !o.headless) && len(template.RequestsHeadless) > 0
 }
 
-// func shouldSkipInteractsh(template *templates.Template, _ *protocols.ExecutorOptions, _ *options) bool {
-// 	var skip bool
-// 	template.Variables.ForEach(func(key string, data interface{}) {
-// 		if strings.Contains(ntypes.ToString(data), "interactsh-url") {
-// 			skip = true
-// 			return
-// 		}
-// 	})
-// 	return skip
-// }
+// shouldSkipInteractsh 未配置interactsh客户端时跳过包含{{interactsh-url}}的模板(工作流按子模板执行，不跳过)
+func shouldSkipInteractsh(template *templates.Template, eOptions *protocols.ExecutorOptions) bool {
+	return eOptions.Interactsh == nil && template.CompiledWorkflow == nil && (&POC{Template: template}).UsesInteractsh()
+}
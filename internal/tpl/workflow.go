@@ -0,0 +1,221 @@
+package tpl
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v3/pkg/catalog"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/nuclei/v3/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v3/pkg/workflows"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowLoader 解析工作流引用的子模板(实现nuclei的model.WorkflowLoader)
+//
+// 子模板路径相对模板目录(函数加载时为模板ID)，也可为目录；按标签引用时在全部非工作流模板中查找。
+// 被工作流引用的模板只在工作流中按条件执行，不再单独执行
+type workflowLoader struct {
+	root string            // 模板目录，函数加载时为空
+	raws map[string]string // 函数加载的原始模板(ID -> 内容)
+
+	tags       map[string][]string // 非工作流模板的标签(按标签引用时加载)
+	references map[string]struct{} // 被工作流引用的模板
+	pending    []string            // 当前解析的工作流引用的模板
+}
+
+func newFileWorkflowLoader(template string) *workflowLoader {
+	root := template
+	if info, err := os.Stat(template); err == nil && !info.IsDir() {
+		root = filepath.Dir(template)
+	}
+	return &workflowLoader{root: root, references: make(map[string]struct{})}
+}
+
+func newRawWorkflowLoader(raws map[string]string) *workflowLoader {
+	return &workflowLoader{raws: raws, references: make(map[string]struct{})}
+}
+
+// GetTemplatePaths 获取子模板路径
+func (l *workflowLoader) GetTemplatePaths(templatesList []string, _ bool) []string {
+	var paths []string
+	for _, name := range templatesList {
+		paths = append(paths, l.resolve(name)...)
+	}
+	for _, path := range paths {
+		l.references[path] = struct{}{}
+	}
+	l.pending = append(l.pending, paths...)
+	return paths
+}
+
+// GetTemplatePathsByTags 获取包含任一标签的子模板路径
+func (l *workflowLoader) GetTemplatePathsByTags(tags []string) []string {
+	if l.tags == nil {
+		l.tags = l.loadTags()
+	}
+
+	tags = SplitTags(tags...)
+	var paths []string
+	for _, path := range l.all() {
+		if containsAny(tags, l.tags[path]) {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range paths {
+		l.references[path] = struct{}{}
+	}
+	l.pending = append(l.pending, paths...)
+	return paths
+}
+
+// referenced 模板是否被工作流引用
+func (l *workflowLoader) referenced(path string) bool {
+	_, ok := l.references[path]
+	return ok
+}
+
+// usesInteractsh 上一个解析的工作流引用的子模板是否需要带外交互，每次解析模板后调用
+func (l *workflowLoader) usesInteractsh() bool {
+	paths := l.pending
+	l.pending = nil
+
+	for _, path := range paths {
+		if interactsh.HasMarkers(string(l.read(path))) {
+			return true
+		}
+	}
+	return false
+}
+
+// read 读取模板内容
+func (l *workflowLoader) read(path string) []byte {
+	if l.raws != nil {
+		return []byte(l.raws[path])
+	}
+	data, _ := os.ReadFile(path)
+	return data
+}
+
+// resolve 解析单个子模板引用
+func (l *workflowLoader) resolve(name string) []string {
+	if l.raws != nil {
+		for _, id := range []string{name, strings.TrimSuffix(name, filepath.Ext(name))} {
+			if _, ok := l.raws[id]; ok {
+				return []string{id}
+			}
+		}
+		return nil
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.root, name)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if !info.IsDir() {
+		return []string{path}
+	}
+
+	var paths []string
+	filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && isTemplateFile(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths
+}
+
+// all 全部模板路径(按路径排序)
+func (l *workflowLoader) all() []string {
+	var paths []string
+	if l.raws != nil {
+		for id := range l.raws {
+			paths = append(paths, id)
+		}
+		slices.Sort(paths)
+		return paths
+	}
+
+	filepath.WalkDir(l.root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && isTemplateFile(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths
+}
+
+// loadTags 读取全部非工作流模板的标签
+func (l *workflowLoader) loadTags() map[string][]string {
+	tags := make(map[string][]string)
+	for _, path := range l.all() {
+		data := l.read(path)
+
+		var header struct {
+			Info struct {
+				Tags any `yaml:"tags"`
+			} `yaml:"info"`
+			Workflows []any `yaml:"workflows"`
+		}
+		if err := yaml.Unmarshal(data, &header); err != nil || len(header.Workflows) != 0 {
+			continue
+		}
+
+		switch v := header.Info.Tags.(type) {
+		case string:
+			tags[path] = SplitTags(v)
+		case []any:
+			for _, tag := range v {
+				tags[path] = append(tags[path], SplitTags(fmt.Sprint(tag))...)
+			}
+		}
+	}
+	return tags
+}
+
+func isTemplateFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// rawCatalog 按模板ID读取函数加载的原始模板，用于解析工作流及其子模板
+type rawCatalog struct {
+	catalog.Catalog
+	raws map[string]string
+}
+
+// OpenFile 打开原始模板
+func (c *rawCatalog) OpenFile(filename string) (io.ReadCloser, error) {
+	if raw, ok := c.raws[filename]; ok {
+		return io.NopCloser(strings.NewReader(raw)), nil
+	}
+	return c.Catalog.OpenFile(filename)
+}
+
+// walkWorkflow 遍历工作流中的全部子模板
+func walkWorkflow(steps []*workflows.WorkflowTemplate, fn func(*workflows.ProtocolExecuterPair)) {
+	for _, step := range steps {
+		for _, executer := range step.Executers {
+			fn(executer)
+		}
+		walkWorkflow(step.Subtemplates, fn)
+		for _, matcher := range step.Matchers {
+			walkWorkflow(matcher.Subtemplates, fn)
+		}
+	}
+}
+
+// isEmptyWorkflow 模板是否为未引用任何子模板的工作流
+func isEmptyWorkflow(template *templates.Template) bool {
+	return template.CompiledWorkflow != nil && (&POC{Template: template}).WorkflowSize() == 0
+}
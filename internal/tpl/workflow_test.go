@@ -0,0 +1,160 @@
+package tpl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EscapeBearSecond/falcon/internal/global"
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/projectdiscovery/nuclei/v3/pkg/catalog/disk"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+var workflowTemplates = map[string]string{
+	"technologies/tech-detect.yaml": `id: tech-detect
+
+info:
+  name: Tech Detect
+  author: falcon
+  severity: info
+  tags: tech
+
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: word
+        name: wordpress
+        words:
+          - "WordPress"
+`,
+	"vulnerabilities/wp-cve.yaml": `id: wp-cve
+
+info:
+  name: WordPress CVE
+  author: falcon
+  severity: high
+  tags: wordpress,cve
+
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: word
+        words:
+          - "WordPress"
+`,
+	"vulnerabilities/standalone.yaml": `id: standalone
+
+info:
+  name: Standalone
+  author: falcon
+  severity: low
+  tags: misc
+
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: status
+        status:
+          - 200
+`,
+	"workflows/wordpress-workflow.yaml": `id: wordpress-workflow
+
+info:
+  name: WordPress Workflow
+  author: falcon
+
+workflows:
+  - template: technologies/tech-detect.yaml
+    matchers:
+      - name: wordpress
+        subtemplates:
+          - tags: wordpress
+`,
+}
+
+func TestLoadWorkflow(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := os.MkdirTemp("", "test")
+	assert.NoError(err)
+	defer os.RemoveAll(tempDir)
+
+	for name, content := range workflowTemplates {
+		path := filepath.Join(tempDir, name)
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+
+	global.Init()
+
+	check := func(result *Result) {
+		// 被工作流引用的模板不单独执行
+		ids := lo.Map(result.Pocs, func(poc *POC, _ int) string { return poc.ID })
+		assert.ElementsMatch([]string{"wordpress-workflow", "standalone"}, ids)
+
+		workflow, _ := lo.Find(result.Pocs, func(poc *POC) bool { return poc.IsWorkflow() })
+		assert.Equal(2, workflow.WorkflowSize())
+		assert.True(workflow.UsesHTTP())
+		assert.False(workflow.UsesInteractsh())
+	}
+
+	{
+		eOptions := global.ExecutorOptions()
+		eOptions.Catalog = disk.NewCatalog(tempDir)
+		result, err := LoadWithFileWalk(tempDir, eOptions)
+		assert.NoError(err)
+		check(result)
+	}
+
+	{
+		eOptions := global.ExecutorOptions()
+		eOptions.Catalog = disk.NewCatalog("")
+		result, err := LoadWithFunc(func() []*types.RawTemplate {
+			var rts []*types.RawTemplate
+			for name, content := range workflowTemplates {
+				rts = append(rts, &types.RawTemplate{ID: name, Original: content})
+			}
+			return rts
+		}, eOptions)
+		assert.NoError(err)
+		check(result)
+	}
+
+	{
+		eOptions := global.ExecutorOptions()
+		eOptions.Catalog = disk.NewCatalog("")
+		_, err := LoadWithFunc(func() []*types.RawTemplate {
+			return []*types.RawTemplate{{ID: "workflow", Original: workflowTemplates["workflows/wordpress-workflow.yaml"]}}
+		}, eOptions)
+		assert.Error(err)
+	}
+
+	{
+		// 引用的子模板包含{{interactsh-url}}时工作流需要带外交互
+		eOptions := global.ExecutorOptions()
+		eOptions.Catalog = disk.NewCatalog("")
+		result, err := LoadWithFunc(func() []*types.RawTemplate {
+			var rts []*types.RawTemplate
+			for name, content := range workflowTemplates {
+				if name == "vulnerabilities/wp-cve.yaml" {
+					content = strings.Replace(content, `"{{BaseURL}}"`, `"{{BaseURL}}/?callback={{interactsh-url}}"`, 1)
+				}
+				rts = append(rts, &types.RawTemplate{ID: name, Original: content})
+			}
+			return rts
+		}, eOptions)
+		assert.NoError(err)
+
+		workflow, _ := lo.Find(result.Pocs, func(poc *POC) bool { return poc.IsWorkflow() })
+		assert.True(workflow.UsesInteractsh())
+	}
+}
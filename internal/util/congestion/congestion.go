@@ -0,0 +1,227 @@
+package congestion
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// minRate 单主机最低速率(包/秒)
+	minRate = 1
+	// minTimeout 自适应超时下限
+	minTimeout = 100 * time.Millisecond
+	// rttAlpha/rttBeta RFC 6298中平滑RTT与RTT偏差的权重
+	rttAlpha = 0.125
+	rttBeta  = 0.25
+)
+
+// Config 拥塞控制配置
+type Config struct {
+	HostRate int           // 单主机最大速率(包/秒)，0为不限制
+	Adaptive bool          // 根据主机响应调整速率与超时
+	MaxRate  int           // 自适应模式下HostRate为0时使用的速率上限(通常为全局速率)
+	Timeout  time.Duration // 超时上限(自适应模式下未测得RTT时使用)
+	Probes   int           // 单主机的探测数量，全部完成(Done)后释放主机状态，0为不释放
+}
+
+// Controller 按主机限速及自适应拥塞控制
+//
+// 每个主机维护独立的发送间隔：收到响应时速率加1(加性增)，曾响应过的端口超时时速率减半(乘性减)，
+// 从未响应的端口超时视为被防火墙过滤，不作为拥塞信号，避免过滤大部分端口的主机被降至最低速率。
+// 按RFC 6298估算RTT得到主机超时时间。同网段(IPv4 /24，IPv6 /64)的新主机以该网段最近的速率开始，
+// 因此响应良好的网段会逐步提速，丢包的网段保持低速，避免脆弱设备(OT/IoT)因探测过多而异常
+type Controller struct {
+	hostRate float64
+	adaptive bool
+	maxRate  float64
+	timeout  time.Duration
+	probes   int
+
+	hosts    sync.Map // host -> *hostState
+	segments sync.Map // 网段 -> *segmentState
+}
+
+type hostState struct {
+	m       sync.Mutex
+	rate    float64   // 当前速率(包/秒)，0为不限制
+	next    time.Time // 下一次允许发送的时间
+	srtt    time.Duration
+	rttvar  time.Duration
+	sampled bool
+	done    int              // 已完成的探测数量
+	ports   map[int]struct{} // 曾响应的端口
+	segment *segmentState
+}
+
+type segmentState struct {
+	m    sync.Mutex
+	rate float64
+}
+
+// New 实例化
+func New(cfg *Config) *Controller {
+	c := &Controller{
+		hostRate: float64(cfg.HostRate),
+		adaptive: cfg.Adaptive,
+		maxRate:  float64(cfg.HostRate),
+		timeout:  cfg.Timeout,
+		probes:   cfg.Probes,
+	}
+	if c.maxRate == 0 {
+		c.maxRate = float64(cfg.MaxRate)
+	}
+	return c
+}
+
+// Wait 等待主机允许发送下一个探测
+func (c *Controller) Wait(ctx context.Context, host string) error {
+	if !c.adaptive && c.hostRate == 0 {
+		return nil
+	}
+
+	delay := c.host(host).reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Timeout 获取主机的探测超时时间
+func (c *Controller) Timeout(host string) time.Duration {
+	if !c.adaptive {
+		return c.timeout
+	}
+
+	hs := c.host(host)
+	hs.m.Lock()
+	defer hs.m.Unlock()
+
+	if !hs.sampled {
+		return c.timeout
+	}
+	return min(max(hs.srtt+4*hs.rttvar, minTimeout), c.timeout)
+}
+
+// Observe 记录一次端口探测结果，responded为false表示超时，仅曾响应过的端口超时视为丢包
+func (c *Controller) Observe(host string, port int, rtt time.Duration, responded bool) {
+	if !c.adaptive {
+		return
+	}
+
+	hs := c.host(host)
+	hs.m.Lock()
+	if _, answered := hs.ports[port]; !responded && !answered {
+		hs.m.Unlock()
+		return
+	}
+	if responded {
+		if hs.ports == nil {
+			hs.ports = make(map[int]struct{})
+		}
+		hs.ports[port] = struct{}{}
+		if hs.sampled {
+			delta := hs.srtt - rtt
+			hs.rttvar = time.Duration((1-rttBeta)*float64(hs.rttvar) + rttBeta*float64(max(delta, -delta)))
+			hs.srtt = time.Duration((1-rttAlpha)*float64(hs.srtt) + rttAlpha*float64(rtt))
+		} else {
+			hs.srtt, hs.rttvar, hs.sampled = rtt, rtt/2, true
+		}
+		hs.rate = min(hs.rate+1, c.maxRate)
+	} else {
+		hs.rate = max(hs.rate/2, minRate)
+	}
+	rate := hs.rate
+	hs.m.Unlock()
+
+	hs.segment.m.Lock()
+	hs.segment.rate = rate
+	hs.segment.m.Unlock()
+}
+
+// Rate 获取主机当前速率(包/秒)，0为不限制
+func (c *Controller) Rate(host string) float64 {
+	hs := c.host(host)
+	hs.m.Lock()
+	defer hs.m.Unlock()
+	return hs.rate
+}
+
+// Done 记录主机的一次探测完成，主机的探测全部完成后释放其状态(网段状态保留，供同网段新主机使用)
+func (c *Controller) Done(host string) {
+	if c.probes <= 0 {
+		return
+	}
+	v, ok := c.hosts.Load(host)
+	if !ok {
+		return
+	}
+	hs := v.(*hostState)
+	hs.m.Lock()
+	hs.done++
+	finished := hs.done >= c.probes
+	hs.m.Unlock()
+	if finished {
+		c.hosts.CompareAndDelete(host, hs)
+	}
+}
+
+func (c *Controller) host(host string) *hostState {
+	if hs, ok := c.hosts.Load(host); ok {
+		return hs.(*hostState)
+	}
+
+	segment := c.segment(host)
+	hs := &hostState{rate: c.hostRate, segment: segment}
+	if c.adaptive {
+		segment.m.Lock()
+		hs.rate = segment.rate
+		segment.m.Unlock()
+	}
+
+	actual, _ := c.hosts.LoadOrStore(host, hs)
+	return actual.(*hostState)
+}
+
+func (c *Controller) segment(host string) *segmentState {
+	key := host
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			key = ip4.Mask(net.CIDRMask(24, 32)).String()
+		} else {
+			key = ip.Mask(net.CIDRMask(64, 128)).String()
+		}
+	}
+
+	// 新网段以上限的1/4开始
+	segment := &segmentState{rate: max(c.maxRate/4, minRate)}
+	actual, _ := c.segments.LoadOrStore(key, segment)
+	return actual.(*segmentState)
+}
+
+// reserve 预留一次发送，返回需要等待的时间
+func (hs *hostState) reserve(now time.Time) time.Duration {
+	hs.m.Lock()
+	defer hs.m.Unlock()
+
+	if hs.rate <= 0 {
+		return 0
+	}
+
+	if hs.next.Before(now) {
+		hs.next = now
+	}
+	delay := hs.next.Sub(now)
+	hs.next = hs.next.Add(time.Duration(float64(time.Second) / hs.rate))
+	return delay
+}
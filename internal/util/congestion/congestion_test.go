@@ -0,0 +1,126 @@
+package congestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostRate(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New(&Config{HostRate: 10, Timeout: time.Second})
+
+	now := time.Now()
+	hs := c.host("192.168.1.1")
+	assert.Zero(hs.reserve(now))
+	assert.Equal(100*time.Millisecond, hs.reserve(now))
+	assert.Equal(200*time.Millisecond, hs.reserve(now))
+
+	// 不同主机互不影响
+	assert.Zero(c.host("192.168.1.2").reserve(now))
+
+	// 非自适应模式不调整速率与超时
+	c.Observe("192.168.1.1", 80, 10*time.Millisecond, false)
+	assert.EqualValues(10, c.Rate("192.168.1.1"))
+	assert.Equal(time.Second, c.Timeout("192.168.1.1"))
+
+	assert.NoError(New(&Config{Timeout: time.Second}).Wait(context.Background(), "192.168.1.1"))
+}
+
+func TestAdaptive(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New(&Config{Adaptive: true, HostRate: 100, Timeout: 2 * time.Second})
+
+	host := "10.0.0.1"
+	assert.EqualValues(25, c.Rate(host))
+	assert.Equal(2*time.Second, c.Timeout(host))
+
+	// 加性增
+	for range 10 {
+		c.Observe(host, 80, 20*time.Millisecond, true)
+	}
+	assert.EqualValues(35, c.Rate(host))
+	assert.Equal(100*time.Millisecond, c.Timeout(host))
+
+	// 同网段新主机继承网段速率
+	assert.EqualValues(35, c.Rate("10.0.0.2"))
+	assert.EqualValues(25, c.Rate("10.0.1.1"))
+
+	// 乘性减(曾响应的端口超时)，不低于下限
+	c.Observe(host, 80, 0, false)
+	assert.EqualValues(17.5, c.Rate(host))
+	for range 10 {
+		c.Observe(host, 80, 0, false)
+	}
+	assert.EqualValues(minRate, c.Rate(host))
+
+	// 不超过上限
+	for range 200 {
+		c.Observe(host, 80, 500*time.Millisecond, true)
+	}
+	assert.EqualValues(100, c.Rate(host))
+	assert.Greater(c.Timeout(host), 500*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := New(&Config{HostRate: 1, Timeout: time.Second})
+	assert.NoError(slow.Wait(ctx, host))
+	assert.ErrorIs(slow.Wait(ctx, host), context.Canceled)
+}
+
+func TestFiltered(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New(&Config{Adaptive: true, HostRate: 100, Timeout: time.Second})
+	host := "10.0.0.1"
+
+	// 仅少数端口响应(开放或RST)，其余端口被过滤(超时)，主机仍在响应，不降速
+	for port := 1; port <= 1000; port++ {
+		responded := port == 22 || port == 80 || port == 443
+		c.Observe(host, port, 20*time.Millisecond, responded)
+	}
+	assert.EqualValues(28, c.Rate(host))
+
+	// 曾响应的端口超时视为丢包
+	c.Observe(host, 80, 0, false)
+	assert.EqualValues(14, c.Rate(host))
+
+	// 未响应过的端口重试超时仍不降速
+	c.Observe(host, 8080, 0, false)
+	assert.EqualValues(14, c.Rate(host))
+}
+
+func TestDone(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New(&Config{Adaptive: true, HostRate: 100, Timeout: time.Second, Probes: 2})
+	host := "10.0.0.1"
+
+	// 未记录状态的主机不受影响
+	c.Done("10.0.0.9")
+
+	for range 10 {
+		c.Observe(host, 80, 20*time.Millisecond, true)
+	}
+	c.Done(host)
+	_, ok := c.hosts.Load(host)
+	assert.True(ok)
+
+	// 探测全部完成后释放主机状态，网段速率保留
+	c.Done(host)
+	_, ok = c.hosts.Load(host)
+	assert.False(ok)
+	assert.EqualValues(35, c.Rate(host))
+	assert.Equal(time.Second, c.Timeout(host))
+
+	// 未配置探测数量时不释放
+	keep := New(&Config{Adaptive: true, HostRate: 100, Timeout: time.Second})
+	keep.Observe(host, 80, 20*time.Millisecond, true)
+	keep.Done(host)
+	_, ok = keep.hosts.Load(host)
+	assert.True(ok)
+}
@@ -0,0 +1,134 @@
+package cyclic
+
+import (
+	"math/big"
+	"math/bits"
+	"math/rand"
+)
+
+// Permutation 基于乘法循环群的伪随机排列(参考zmap)
+//
+// 选取大于n的素数p及模p的原根g，从随机起点x0开始依次计算x = x*g mod p，
+// 遍历完整个循环群，跳过大于n的元素即可不重复地得到[0, n)的一个排列。
+// 相同的n与seed得到相同的排列
+type Permutation struct {
+	n       uint64
+	p       uint64
+	g       uint64
+	start   uint64
+	cur     uint64
+	started bool
+}
+
+// New 创建[0, n)的排列
+func New(n uint64, seed int64) *Permutation {
+	perm := &Permutation{n: n}
+	if n == 0 {
+		return perm
+	}
+
+	perm.p = nextPrime(n + 1)
+	r := rand.New(rand.NewSource(seed))
+	perm.g = primitiveRoot(perm.p, r)
+	perm.start = uint64(r.Int63n(int64(perm.p-1))) + 1
+	perm.cur = perm.start
+
+	return perm
+}
+
+// Size 排列长度
+func (perm *Permutation) Size() uint64 {
+	return perm.n
+}
+
+// Next 获取下一个元素，遍历结束返回false
+func (perm *Permutation) Next() (uint64, bool) {
+	if perm.n == 0 {
+		return 0, false
+	}
+
+	for {
+		if perm.started && perm.cur == perm.start {
+			return 0, false
+		}
+		x := perm.cur
+		perm.cur = mulMod(perm.cur, perm.g, perm.p)
+		perm.started = true
+
+		// 循环群元素为[1, p-1]
+		if x <= perm.n {
+			return x - 1, true
+		}
+	}
+}
+
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, rem := bits.Div64(hi, lo, m)
+	return rem
+}
+
+func powMod(a, e, m uint64) uint64 {
+	result := uint64(1) % m
+	for a %= m; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = mulMod(result, a, m)
+		}
+		a = mulMod(a, a, m)
+	}
+	return result
+}
+
+// nextPrime 获取不小于n的最小素数
+func nextPrime(n uint64) uint64 {
+	if n <= 2 {
+		return 2
+	}
+	for p := n | 1; ; p += 2 {
+		if new(big.Int).SetUint64(p).ProbablyPrime(0) {
+			return p
+		}
+	}
+}
+
+// primitiveRoot 随机选取模素数p的原根
+func primitiveRoot(p uint64, r *rand.Rand) uint64 {
+	if p == 2 {
+		return 1
+	}
+
+	factors := primeFactors(p - 1)
+	for {
+		g := uint64(r.Int63n(int64(p-2))) + 2
+		if isPrimitiveRoot(g, p, factors) {
+			return g
+		}
+	}
+}
+
+func isPrimitiveRoot(g, p uint64, factors []uint64) bool {
+	for _, q := range factors {
+		if powMod(g, (p-1)/q, p) == 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// primeFactors 分解n的素因子(去重)
+func primeFactors(n uint64) []uint64 {
+	factors := make([]uint64, 0)
+	for q := uint64(2); q*q <= n; q++ {
+		if n%q != 0 {
+			continue
+		}
+		factors = append(factors, q)
+		for n%q == 0 {
+			n /= q
+		}
+	}
+	if n > 1 {
+		factors = append(factors, n)
+	}
+	return factors
+}
@@ -0,0 +1,35 @@
+package cyclic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func drain(perm *Permutation) []uint64 {
+	results := make([]uint64, 0, perm.Size())
+	for x, more := perm.Next(); more; x, more = perm.Next() {
+		results = append(results, x)
+	}
+	return results
+}
+
+func TestPermutation(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, n := range []uint64{0, 1, 2, 3, 10, 256, 1000, 65536} {
+		results := drain(New(n, 42))
+		assert.Len(results, int(n))
+
+		seen := make(map[uint64]struct{}, n)
+		for _, x := range results {
+			assert.Less(x, n)
+			seen[x] = struct{}{}
+		}
+		assert.Len(seen, int(n))
+	}
+
+	// 相同种子得到相同排列，不同种子得到不同排列
+	assert.Equal(drain(New(1000, 7)), drain(New(1000, 7)))
+	assert.NotEqual(drain(New(1000, 7)), drain(New(1000, 8)))
+}
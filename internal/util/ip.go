@@ -1,9 +1,13 @@
 package util
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
 	"math"
+	"math/bits"
 	"net"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -27,6 +31,12 @@ func IsIPv4(str string) bool {
 	return parsedIP != nil && parsedIP.To4() != nil && strings.Contains(str, ".")
 }
 
+// IsIPv6 判断输入是否为IPv6
+func IsIPv6(str string) bool {
+	parsedIP := net.ParseIP(str)
+	return parsedIP != nil && parsedIP.To4() == nil
+}
+
 // IsPort 判断输入是否为Port
 func IsPort(str string) bool {
 	if i, err := strconv.Atoi(str); err == nil && i > 0 && i < 65536 {
@@ -45,7 +55,32 @@ func IsHostPort(str string) bool {
 	return IsIP(host) && IsPort(port)
 }
 
-// IsIPRange 判断输入是否为IPRange
+// domainRegexp 域名(主机名)格式
+var domainRegexp = regexp.MustCompile(`^(?i)([a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.?$`)
+
+// IsDomain 判断输入是否为域名(主机名)
+func IsDomain(str string) bool {
+	if len(str) == 0 || len(str) > 253 || IsIP(str) {
+		return false
+	}
+	// 纯数字与点组成的输入不作为域名(如非法IP)
+	if strings.Trim(str, "0123456789.") == "" {
+		return false
+	}
+	return domainRegexp.MatchString(str)
+}
+
+// IsDomainPort 判断输入是否为domain:port
+func IsDomainPort(str string) bool {
+	host, port, err := net.SplitHostPort(str)
+	if err != nil {
+		return false
+	}
+
+	return IsDomain(host) && IsPort(port)
+}
+
+// IsIPRange 判断输入是否为IPRange(起止地址需为同一地址族)
 func IsIPRange(input string) bool {
 	ipRange := strings.Split(input, "-")
 	if len(ipRange) != 2 {
@@ -59,40 +94,68 @@ func IsIPRange(input string) bool {
 		return false
 	}
 
-	startIPUint := binary.BigEndian.Uint32(startIP.To4())
-	endIPUint := binary.BigEndian.Uint32(endIP.To4())
+	if (startIP.To4() == nil) != (endIP.To4() == nil) {
+		return false
+	}
 
-	return endIPUint > startIPUint
+	return bytes.Compare(endIP.To16(), startIP.To16()) > 0
 }
 
-func OffsetIP(ip string, size uint32) string {
-	ipUint := binary.BigEndian.Uint32(net.ParseIP(ip).To4())
-	ipUint += size
+// MaxIPv6ExpandSize IPv6 CIDR/IPRange允许展开的最大地址数
+//
+// IPv6地址空间过大，只允许展开不超过/112(65536个地址)的网段
+const MaxIPv6ExpandSize uint32 = 1 << 16
+
+// IsBoundedIPv6 判断IPv6的CIDR/IPRange是否在可展开的范围内(IPv4始终返回true)
+func IsBoundedIPv6(input string) bool {
+	switch {
+	case IsCIDR(input):
+		_, ipnet, _ := net.ParseCIDR(input)
+		if ipnet.IP.To4() != nil {
+			return true
+		}
+		ones, bits := ipnet.Mask.Size()
+		return bits-ones <= 16
+	case IsIPRange(input):
+		ipRange := strings.Split(input, "-")
+		if net.ParseIP(ipRange[0]).To4() != nil {
+			return true
+		}
+		size, ok := rangeSize(net.ParseIP(ipRange[0]), net.ParseIP(ipRange[1]))
+		return ok && size <= uint64(MaxIPv6ExpandSize)
+	default:
+		return true
+	}
+}
 
-	return net.IPv4(
-		byte(ipUint>>24),
-		byte(ipUint>>16),
-		byte(ipUint>>8),
-		byte(ipUint),
-	).String()
+// OffsetIP 计算ip偏移size后的地址(支持IPv4/IPv6)
+func OffsetIP(ip string, size uint32) string {
+	return addIP(net.ParseIP(ip), uint64(size)).String()
 }
 
+// IPRangeSize 获取IPRange的起始地址与地址数(超出uint32时截断为math.MaxUint32)
 func IPRangeSize(ipRange string) (string, uint32) {
 	ipRangeSplit := strings.Split(ipRange, "-")
 
 	startIP := net.ParseIP(ipRangeSplit[0])
 	endIP := net.ParseIP(ipRangeSplit[1])
 
-	startIPUint := binary.BigEndian.Uint32(startIP.To4())
-	endIPUint := binary.BigEndian.Uint32(endIP.To4())
+	size, ok := rangeSize(startIP, endIP)
+	if !ok || size > math.MaxUint32 {
+		return ipRangeSplit[0], math.MaxUint32
+	}
 
-	return ipRangeSplit[0], endIPUint - startIPUint + 1
+	return ipRangeSplit[0], uint32(size)
 }
 
+// CIDRSize 获取CIDR的网络地址与地址数(超出uint32时截断为math.MaxUint32)
 func CIDRSize(cidr string) (string, uint32) {
 	_, ipnet, _ := net.ParseCIDR(cidr)
 	ones, bits := ipnet.Mask.Size()
-	return ipnet.IP.String(), uint32(math.Pow(2, float64(bits-ones)))
+	if bits-ones >= 32 {
+		return ipnet.IP.String(), math.MaxUint32
+	}
+	return ipnet.IP.String(), uint32(1) << (bits - ones)
 }
 
 // ExpandIPRange 扩展IPRange为IP列表
@@ -102,29 +165,92 @@ func ExpandIPRange(input string) []string {
 	startIP := net.ParseIP(ipRange[0])
 	endIP := net.ParseIP(ipRange[1])
 
-	startIPUint := binary.BigEndian.Uint32(startIP.To4())
-	endIPUint := binary.BigEndian.Uint32(endIP.To4())
+	size, ok := rangeSize(startIP, endIP)
+	if !ok {
+		return nil
+	}
 
-	var ret []string
-	for i := startIPUint; i <= endIPUint; i++ {
-		ip := make([]byte, 4)
-		binary.BigEndian.PutUint32(ip, i)
-		ret = append(ret, net.IPv4(ip[0], ip[1], ip[2], ip[3]).String())
+	ret := make([]string, 0, size)
+	for i := uint64(0); i < size; i++ {
+		ret = append(ret, addIP(startIP, i).String())
 	}
 	return ret
 }
 
 // ExpandCIDR 扩展CIDR为IP列表
-var ExpandCIDR = expand.CIDR
+func ExpandCIDR(cidr string) []string {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil
+	}
+
+	if ipnet.IP.To4() != nil {
+		return expand.CIDR(cidr)
+	}
+
+	if !IsBoundedIPv6(cidr) {
+		return nil
+	}
+
+	first, size := CIDRSize(cidr)
+	return ExpandIPRange(fmt.Sprintf("%s-%s", first, OffsetIP(first, size-1)))
+}
+
+// rangeSize 计算[start, end]的地址数，超出uint64时返回false
+func rangeSize(start, end net.IP) (uint64, bool) {
+	if start4, end4 := start.To4(), end.To4(); start4 != nil && end4 != nil {
+		s, e := binary.BigEndian.Uint32(start4), binary.BigEndian.Uint32(end4)
+		if e < s {
+			return 0, false
+		}
+		return uint64(e-s) + 1, true
+	}
+
+	start16, end16 := start.To16(), end.To16()
+	if start16 == nil || end16 == nil || bytes.Compare(end16, start16) < 0 {
+		return 0, false
+	}
+
+	sHi, sLo := binary.BigEndian.Uint64(start16[:8]), binary.BigEndian.Uint64(start16[8:])
+	eHi, eLo := binary.BigEndian.Uint64(end16[:8]), binary.BigEndian.Uint64(end16[8:])
+
+	lo, borrow := bits.Sub64(eLo, sLo, 0)
+	hi, _ := bits.Sub64(eHi, sHi, borrow)
+	if hi != 0 || lo == math.MaxUint64 {
+		return 0, false
+	}
+	return lo + 1, true
+}
+
+// addIP 计算ip偏移n后的地址，IPv4在32位内回绕，IPv6在128位内回绕
+func addIP(ip net.IP, n uint64) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		v := binary.BigEndian.Uint32(ip4) + uint32(n)
+		return net.IPv4(byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+
+	ip16 := ip.To16()
+	hi, lo := binary.BigEndian.Uint64(ip16[:8]), binary.BigEndian.Uint64(ip16[8:])
+	lo, carry := bits.Add64(lo, n, 0)
+	hi, _ = bits.Add64(hi, 0, carry)
+
+	ret := make(net.IP, net.IPv6len)
+	binary.BigEndian.PutUint64(ret[:8], hi)
+	binary.BigEndian.PutUint64(ret[8:], lo)
+	return ret
+}
 
-// ToBytes 将ip或者ip:port转换为[]byte
+// ToBytesAddr 将ip或者ip:port转换为[]byte
+//
+// IPv4: 4字节ip(+2字节port)，IPv6: 16字节ip(+2字节port)
 func ToBytesAddr(input string) []byte {
 	if IsIP(input) {
-		return net.ParseIP(input).To4()
+		return toBytesIP(net.ParseIP(input))
 	} else if IsHostPort(input) {
-		ip := net.ParseIP(strings.Split(input, ":")[0]).To4()
+		host, portStr, _ := net.SplitHostPort(input)
+		ip := toBytesIP(net.ParseIP(host))
 
-		port, _ := strconv.Atoi(strings.Split(input, ":")[1])
+		port, _ := strconv.Atoi(portStr)
 		portBytes := make([]byte, 2)
 		binary.BigEndian.PutUint16(portBytes, uint16(port))
 
@@ -133,13 +259,30 @@ func ToBytesAddr(input string) []byte {
 	return nil
 }
 
+func toBytesIP(ip net.IP) []byte {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip.To16()
+}
+
 // ToStringAddr 将[]byte转换为ip或者ip:port
 func ToStringAddr(input []byte) string {
-	if len(input) == 4 {
+	switch len(input) {
+	case net.IPv4len, net.IPv6len:
 		return net.IP(input).String()
-	} else if len(input) == 6 {
-		return net.IP(input[:4]).String() + ":" +
-			strconv.Itoa(int(binary.BigEndian.Uint16(input[4:])))
+	case net.IPv4len + 2, net.IPv6len + 2:
+		ipLen := len(input) - 2
+		return net.JoinHostPort(net.IP(input[:ipLen]).String(),
+			strconv.Itoa(int(binary.BigEndian.Uint16(input[ipLen:]))))
 	}
 	return ""
 }
+
+// FormatHost 格式化用于URL的主机(IPv6地址添加方括号)
+func FormatHost(host string) string {
+	if IsIPv6(host) {
+		return "[" + host + "]"
+	}
+	return host
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -141,3 +142,56 @@ func TestToString(t *testing.T) {
 		assert.Equal(ret, "192.168.1.1:8080")
 	}
 }
+
+func TestIPv6(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsIPv6("2001:db8::1"))
+	assert.False(IsIPv6("192.168.1.1"))
+	assert.True(IsHostPort("[2001:db8::1]:8080"))
+	assert.True(IsCIDR("2001:db8::/120"))
+
+	assert.True(IsIPRange("2001:db8::1-2001:db8::3"))
+	assert.False(IsIPRange("2001:db8::3-2001:db8::1"))
+	assert.False(IsIPRange("192.168.1.1-2001:db8::1"))
+
+	assert.Equal([]string{"2001:db8::ffff", "2001:db8::1:0", "2001:db8::1:1"},
+		ExpandIPRange("2001:db8::ffff-2001:db8::1:1"))
+	assert.Len(ExpandCIDR("2001:db8::/120"), 256)
+	assert.Nil(ExpandCIDR("2001:db8::/64"))
+
+	assert.True(IsBoundedIPv6("2001:db8::/112"))
+	assert.False(IsBoundedIPv6("2001:db8::/111"))
+	assert.False(IsBoundedIPv6("2001:db8::-2001:db8::1:0"))
+	assert.True(IsBoundedIPv6("10.0.0.0/8"))
+
+	assert.Equal("2001:db8::1:0", OffsetIP("2001:db8::ffff", 1))
+	_, size := CIDRSize("2001:db8::/64")
+	assert.Equal(uint32(math.MaxUint32), size)
+	_, size = IPRangeSize("2001:db8::1-2001:db8::100")
+	assert.Equal(uint32(256), size)
+
+	bs := ToBytesAddr("[2001:db8::1]:8080")
+	assert.Len(bs, 18)
+	assert.Equal("[2001:db8::1]:8080", ToStringAddr(bs))
+	assert.Equal("2001:db8::1", ToStringAddr(ToBytesAddr("2001:db8::1")))
+
+	assert.Equal("[2001:db8::1]", FormatHost("2001:db8::1"))
+	assert.Equal("192.168.1.1", FormatHost("192.168.1.1"))
+}
+
+func TestIsDomain(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsDomain("example.com"))
+	assert.True(IsDomain("app.example.com"))
+	assert.True(IsDomain("localhost"))
+	assert.False(IsDomain("192.168.1.1"))
+	assert.False(IsDomain("192.168.1.256"))
+	assert.False(IsDomain("-example.com"))
+	assert.False(IsDomain("example.com:8443"))
+
+	assert.True(IsDomainPort("app.example.com:8443"))
+	assert.False(IsDomainPort("app.example.com:0"))
+	assert.False(IsDomainPort("192.168.1.1:80"))
+}
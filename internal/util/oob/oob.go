@@ -0,0 +1,424 @@
+package oob
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/projectdiscovery/interactsh/pkg/settings"
+	"github.com/projectdiscovery/interactsh/pkg/storage"
+)
+
+// uniqueIDLength 交互子域名中唯一ID的长度(关联ID+随机数)
+const uniqueIDLength = settings.CorrelationIdLengthDefault + settings.CorrelationIdNonceLengthDefault
+
+var (
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	ErrUnauthorized     = errors.New("unauthorized")
+)
+
+// Config 交互服务配置
+type Config struct {
+	Listen    string // HTTP监听地址
+	DNSListen string // DNS监听地址(UDP)，为空不启动
+	Domain    string // 交互域名(需由目标解析到本服务)，为空时使用HTTP监听地址
+	IP        string // DNS查询应答的地址，为空不应答
+	Token     string // 客户端认证令牌，为空不认证
+}
+
+// Server 内置的交互(OOB)服务，兼容interactsh客户端协议
+//
+// 注册、轮询与注销接口与interactsh服务一致，其余HTTP请求及DNS查询按主机名/路径中的唯一ID关联到已注册的客户端，
+// 交互数据使用客户端公钥加密后在轮询时返回。仅支持HTTP/DNS，用于隔离网络及测试
+type Server struct {
+	cfg *Config
+
+	m        sync.Mutex
+	sessions map[string]*session // 关联ID -> 会话
+
+	listener   net.Listener
+	httpServer *http.Server
+	dnsServer  *dns.Server
+}
+
+type session struct {
+	secret       string
+	publicKey    *rsa.PublicKey
+	interactions [][]byte
+}
+
+// New 实例化
+func New(cfg *Config) *Server {
+	return &Server{
+		cfg:      cfg,
+		sessions: make(map[string]*session),
+	}
+}
+
+// Start 启动监听
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("oob server listen failed: %w", err)
+	}
+	s.listener = listener
+	s.httpServer = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go s.httpServer.Serve(listener)
+
+	if s.cfg.DNSListen != "" {
+		started := make(chan error, 1)
+		s.dnsServer = &dns.Server{
+			Addr:              s.cfg.DNSListen,
+			Net:               "udp",
+			Handler:           dns.HandlerFunc(s.serveDNS),
+			NotifyStartedFunc: func() { started <- nil },
+		}
+		go func() {
+			if err := s.dnsServer.ListenAndServe(); err != nil {
+				started <- err
+			}
+		}()
+		if err := <-started; err != nil {
+			s.httpServer.Close()
+			return fmt.Errorf("oob dns server listen failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close 停止监听
+func (s *Server) Close() error {
+	var errs []error
+	if s.httpServer != nil {
+		errs = append(errs, s.httpServer.Close())
+	}
+	if s.dnsServer != nil {
+		errs = append(errs, s.dnsServer.Shutdown())
+	}
+	return errors.Join(errs...)
+}
+
+// URL interactsh客户端使用的服务地址，交互域名未指定端口时使用HTTP监听端口(80除外)
+func (s *Server) URL() string {
+	addr := s.cfg.Listen
+	if s.listener != nil {
+		addr = s.listener.Addr().String()
+	}
+	if s.cfg.Domain == "" {
+		return "http://" + addr
+	}
+
+	if _, _, err := net.SplitHostPort(s.cfg.Domain); err == nil {
+		return "http://" + s.cfg.Domain
+	}
+	if _, port, err := net.SplitHostPort(addr); err == nil && port != "" && port != "80" {
+		return "http://" + net.JoinHostPort(s.cfg.Domain, port)
+	}
+	return "http://" + s.cfg.Domain
+}
+
+// domain 交互域名(不含端口)，未指定时为HTTP监听主机
+func (s *Server) domain() string {
+	domain := strings.TrimPrefix(s.URL(), "http://")
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		return host
+	}
+	return domain
+}
+
+// ServeHTTP 处理客户端接口及HTTP交互
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/register":
+		s.register(w, r)
+	case "/deregister":
+		s.deregister(w, r)
+	case "/poll":
+		s.poll(w, r)
+	default:
+		s.serveInteraction(w, r)
+	}
+}
+
+func (s *Server) register(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		jsonError(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var req server.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, fmt.Sprintf("could not decode json body: %s", err), http.StatusBadRequest)
+		return
+	}
+	publicKey, err := decodePublicKey(req.PublicKey)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("could not decode public key: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// 客户端会定期重新注册，已存在的会话需使用相同的密钥
+	if sess, ok := s.sessions[req.CorrelationID]; ok {
+		if sess.secret != req.SecretKey {
+			jsonError(w, "correlation-id provided already exists", http.StatusBadRequest)
+			return
+		}
+	} else {
+		s.sessions[req.CorrelationID] = &session{secret: req.SecretKey, publicKey: publicKey}
+	}
+	jsonMessage(w, "registration successful")
+}
+
+func (s *Server) deregister(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		jsonError(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var req server.DeregisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, fmt.Sprintf("could not decode json body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if sess, ok := s.sessions[req.CorrelationID]; ok && sess.secret == req.SecretKey {
+		delete(s.sessions, req.CorrelationID)
+	}
+	jsonMessage(w, "deregistration successful")
+}
+
+func (s *Server) poll(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		jsonError(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id, secret := r.URL.Query().Get("id"), r.URL.Query().Get("secret")
+
+	s.m.Lock()
+	sess, ok := s.sessions[id]
+	if !ok || sess.secret != secret {
+		s.m.Unlock()
+		jsonError(w, storage.ErrCorrelationIdNotFound.Error(), http.StatusBadRequest)
+		return
+	}
+	interactions := sess.interactions
+	sess.interactions = nil
+	s.m.Unlock()
+
+	resp := &server.PollResponse{Data: []string{}}
+	if len(interactions) != 0 {
+		key, data, err := encrypt(sess.publicKey, interactions)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("could not encrypt interactions: %s", err), http.StatusInternalServerError)
+			return
+		}
+		resp.AESKey, resp.Data = key, data
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveInteraction 记录HTTP交互，响应中包含反转的唯一ID(与interactsh一致)
+func (s *Server) serveInteraction(w http.ResponseWriter, r *http.Request) {
+	uniqueID := s.lookup(r.Host, r.URL.Path, r.URL.RawQuery)
+
+	body := ""
+	if uniqueID != "" {
+		reversed := []byte(uniqueID)
+		slices.Reverse(reversed)
+		body = fmt.Sprintf("<html><head></head><body>%s</body></html>", reversed)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body))
+
+	if uniqueID == "" {
+		return
+	}
+	rawRequest, _ := httputil.DumpRequest(r, true)
+	s.record(&server.Interaction{
+		Protocol:      "http",
+		UniqueID:      uniqueID,
+		FullId:        s.fullID(r.Host, uniqueID),
+		RawRequest:    string(rawRequest),
+		RawResponse:   fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: text/html; charset=utf-8\r\n\r\n%s", body),
+		RemoteAddress: remoteHost(r.RemoteAddr),
+		Timestamp:     time.Now(),
+	})
+}
+
+// serveDNS 记录DNS交互
+func (s *Server) serveDNS(w dns.ResponseWriter, req *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+	msg.Authoritative = true
+
+	for _, q := range req.Question {
+		if ip := net.ParseIP(s.cfg.IP).To4(); ip != nil && q.Qtype == dns.TypeA {
+			msg.Answer = append(msg.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   ip,
+			})
+		}
+	}
+	w.WriteMsg(msg)
+
+	for _, q := range req.Question {
+		name := strings.TrimSuffix(q.Name, ".")
+		uniqueID := s.lookup(name)
+		if uniqueID == "" {
+			continue
+		}
+		s.record(&server.Interaction{
+			Protocol:      "dns",
+			UniqueID:      uniqueID,
+			FullId:        s.fullID(name, uniqueID),
+			QType:         dns.TypeToString[q.Qtype],
+			RawRequest:    req.String(),
+			RawResponse:   msg.String(),
+			RemoteAddress: remoteHost(w.RemoteAddr().String()),
+			Timestamp:     time.Now(),
+		})
+	}
+}
+
+// lookup 在主机名/路径的各部分中查找已注册客户端的唯一ID
+func (s *Server) lookup(values ...string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, value := range values {
+		parts := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+			return r == '.' || r == '/' || r == '?' || r == '&' || r == '=' || r == ':'
+		})
+		for _, part := range parts {
+			for i := 0; i+uniqueIDLength <= len(part); i++ {
+				if _, ok := s.sessions[part[i:i+settings.CorrelationIdLengthDefault]]; ok {
+					return part[i : i+uniqueIDLength]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// fullID 主机名中交互域名之前的部分，主机名不属于交互域名时为唯一ID
+func (s *Server) fullID(host, uniqueID string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if prefix, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(s.domain())); ok && strings.Contains(prefix, uniqueID) {
+		return prefix
+	}
+	return uniqueID
+}
+
+func (s *Server) record(interaction *server.Interaction) {
+	data, err := json.Marshal(interaction)
+	if err != nil {
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if sess, ok := s.sessions[interaction.UniqueID[:settings.CorrelationIdLengthDefault]]; ok {
+		sess.interactions = append(sess.interactions, data)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	return s.cfg.Token == "" || r.Header.Get("Authorization") == s.cfg.Token
+}
+
+// encrypt 使用随机AES密钥(AES-256-CFB)加密交互数据，AES密钥使用客户端公钥(RSA-OAEP)加密
+func encrypt(publicKey *rsa.PublicKey, interactions [][]byte) (string, []string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, key, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := make([]string, 0, len(interactions))
+	for _, plaintext := range interactions {
+		ciphertext := make([]byte, aes.BlockSize+len(plaintext))
+		iv := ciphertext[:aes.BlockSize]
+		if _, err := rand.Read(iv); err != nil {
+			return "", nil, err
+		}
+		cipher.NewCFBEncrypter(block, iv).XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
+		data = append(data, base64.StdEncoding.EncodeToString(ciphertext))
+	}
+	return base64.StdEncoding.EncodeToString(encryptedKey), data, nil
+}
+
+func decodePublicKey(data string) (*rsa.PublicKey, error) {
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(decoded)
+	if block == nil {
+		return nil, ErrInvalidPublicKey
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrInvalidPublicKey
+	}
+	return publicKey, nil
+}
+
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func jsonMessage(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func jsonError(w http.ResponseWriter, err string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err})
+}
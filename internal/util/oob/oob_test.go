@@ -0,0 +1,127 @@
+package oob
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/interactsh/pkg/client"
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	assert := assert.New(t)
+
+	s := New(&Config{Listen: "127.0.0.1:0", Token: "secret"})
+	assert.NoError(s.Start())
+	defer s.Close()
+
+	// 令牌错误时注册失败
+	_, err := client.New(&client.Options{ServerURL: s.URL(), Token: "invalid", CorrelationIdLength: 20, CorrelationIdNonceLength: 13})
+	assert.Error(err)
+
+	c, err := client.New(&client.Options{ServerURL: s.URL(), Token: "secret", CorrelationIdLength: 20, CorrelationIdNonceLength: 13})
+	assert.NoError(err)
+	defer c.Close()
+
+	var m sync.Mutex
+	var interactions []*server.Interaction
+	assert.NoError(c.StartPolling(100*time.Millisecond, func(interaction *server.Interaction) {
+		m.Lock()
+		defer m.Unlock()
+		interactions = append(interactions, interaction)
+	}))
+
+	// 模拟目标解析交互域名后回连
+	url := c.URL()
+	req, err := http.NewRequest(http.MethodGet, s.URL()+"/callback", nil)
+	assert.NoError(err)
+	req.Host = url
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(err)
+	resp.Body.Close()
+
+	// 未注册的ID不记录
+	req, err = http.NewRequest(http.MethodGet, s.URL()+"/"+strings.Repeat("a", uniqueIDLength), nil)
+	assert.NoError(err)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(err)
+	resp.Body.Close()
+
+	assert.Eventually(func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return len(interactions) != 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Len(interactions, 1)
+	assert.Equal("http", interactions[0].Protocol)
+	assert.Equal(url[:uniqueIDLength], interactions[0].UniqueID)
+	assert.Equal("127.0.0.1", interactions[0].RemoteAddress)
+}
+
+func TestURL(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{Listen: ":8085"}, "http://:8085"},
+		{Config{Listen: ":8085", Domain: "oob.example.com"}, "http://oob.example.com:8085"},
+		{Config{Listen: ":80", Domain: "oob.example.com"}, "http://oob.example.com"},
+		{Config{Listen: ":8085", Domain: "oob.example.com:80"}, "http://oob.example.com:80"},
+	}
+	for _, test := range tests {
+		assert.Equal(test.want, New(&test.cfg).URL(), test.cfg)
+	}
+}
+
+func TestServerDomain(t *testing.T) {
+	assert := assert.New(t)
+
+	s := New(&Config{Listen: "127.0.0.1:0", Domain: "localhost"})
+	assert.NoError(s.Start())
+	defer s.Close()
+
+	// 未指定端口时使用实际监听端口
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	assert.Equal("http://localhost:"+port, s.URL())
+
+	c, err := client.New(&client.Options{ServerURL: s.URL(), CorrelationIdLength: 20, CorrelationIdNonceLength: 13})
+	assert.NoError(err)
+	defer c.Close()
+
+	var m sync.Mutex
+	var interactions []*server.Interaction
+	assert.NoError(c.StartPolling(100*time.Millisecond, func(interaction *server.Interaction) {
+		m.Lock()
+		defer m.Unlock()
+		interactions = append(interactions, interaction)
+	}))
+
+	url := "abc." + c.URL()
+	req, err := http.NewRequest(http.MethodGet, s.URL(), nil)
+	assert.NoError(err)
+	req.Host = url
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(err)
+	resp.Body.Close()
+
+	assert.Eventually(func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return len(interactions) != 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	m.Lock()
+	defer m.Unlock()
+	// 主机名中交互域名之前的部分
+	assert.Equal(strings.TrimSuffix(url, ".localhost:"+port), interactions[0].FullId)
+}
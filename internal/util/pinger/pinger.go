@@ -0,0 +1,332 @@
+package pinger
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+	// interval 同一目标多次发送echo的间隔
+	interval = 100 * time.Millisecond
+)
+
+var (
+	ErrTimeout              = errors.New("ping timeout")
+	ErrPingerAlreadyStarted = errors.New("pinger was already started")
+	ErrPingerNotReady       = errors.New("pinger is not ready")
+	ErrUnsupportedFamily    = errors.New("unsupported address family")
+)
+
+// Reply echo响应
+type Reply struct {
+	Addr string
+	TTL  int
+	RTT  time.Duration
+}
+
+// Statistics 多次echo请求的统计
+type Statistics struct {
+	Addr     string
+	TTL      int // 首个响应的TTL(IPv6为hop limit)
+	Sent     int
+	Received int
+	MinRTT   time.Duration
+	AvgRTT   time.Duration
+}
+
+// Loss 丢包率(0-100)
+func (s *Statistics) Loss() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Sent-s.Received) / float64(s.Sent) * 100
+}
+
+func (s *Statistics) add(reply *Reply) {
+	if s.Received == 0 {
+		s.Addr, s.TTL = reply.Addr, reply.TTL
+		s.MinRTT = reply.RTT
+	}
+	s.MinRTT = min(s.MinRTT, reply.RTT)
+	s.AvgRTT = (s.AvgRTT*time.Duration(s.Received) + reply.RTT) / time.Duration(s.Received+1)
+	s.Received++
+}
+
+// pending 等待响应的echo请求
+type pending struct {
+	replies chan<- *Reply
+	sent    time.Time
+}
+
+// Pinger 单socket的ICMP echo收发引擎
+//
+// 所有目标共用同一个IPv4/IPv6 socket发送echo请求，接收循环按(地址, seq)匹配响应，
+// 可同时维持大量进行中的请求。非特权模式下(Linux ping socket)内核会改写id，因此仅使用seq匹配
+type Pinger struct {
+	privileged bool
+	id         int
+	seq        atomic.Uint32
+
+	m       sync.Mutex
+	pending map[string]*pending
+	conn4   *icmp.PacketConn
+	conn6   *icmp.PacketConn
+	started bool
+	isReady chan struct{}
+}
+
+// NewPinger 实例化，privileged为true时使用raw socket
+func NewPinger(privileged bool) *Pinger {
+	return &Pinger{
+		privileged: privileged,
+		id:         rand.Intn(0xffff),
+		pending:    make(map[string]*pending),
+		isReady:    make(chan struct{}),
+	}
+}
+
+// PingingLoop 打开socket并接收响应，直到ctx结束
+func (p *Pinger) PingingLoop(ctx context.Context) error {
+	if err := p.listen(); err != nil {
+		return err
+	}
+
+	wg := sync.WaitGroup{}
+	for _, conn := range []*icmp.PacketConn{p.conn4, p.conn6} {
+		if conn == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.receive(conn)
+		}()
+	}
+
+	close(p.isReady)
+
+	<-ctx.Done()
+	p.close()
+	wg.Wait()
+
+	return nil
+}
+
+// WaitReady 等待socket就绪
+func (p *Pinger) WaitReady() <-chan struct{} {
+	return p.isReady
+}
+
+func (p *Pinger) listen() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.started {
+		return ErrPingerAlreadyStarted
+	}
+
+	network4, network6 := "udp4", "udp6"
+	if p.privileged {
+		network4, network6 = "ip4:icmp", "ip6:ipv6-icmp"
+	}
+
+	conn4, err := icmp.ListenPacket(network4, "0.0.0.0")
+	if err != nil {
+		return err
+	}
+	conn4.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+	p.conn4 = conn4
+
+	// 不支持IPv6时仅使用IPv4
+	if conn6, err := icmp.ListenPacket(network6, "::"); err == nil {
+		conn6.IPv6PacketConn().SetControlMessage(ipv6.FlagHopLimit, true)
+		p.conn6 = conn6
+	}
+
+	p.started = true
+	return nil
+}
+
+func (p *Pinger) close() {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.conn4 != nil {
+		p.conn4.Close()
+	}
+	if p.conn6 != nil {
+		p.conn6.Close()
+	}
+}
+
+// Ping 向ip发送count个echo请求(间隔100ms)，收到全部响应或超时后返回统计，未收到任何响应返回ErrTimeout
+func (p *Pinger) Ping(ctx context.Context, ip net.IP, count int, timeout time.Duration) (*Statistics, error) {
+	select {
+	case <-p.isReady:
+	default:
+		return nil, ErrPingerNotReady
+	}
+
+	conn, typ, dst := p.conn4, icmp.Type(ipv4.ICMPTypeEcho), net.Addr(nil)
+	if ip.To4() == nil {
+		if p.conn6 == nil {
+			return nil, ErrUnsupportedFamily
+		}
+		conn, typ = p.conn6, ipv6.ICMPTypeEchoRequest
+	}
+	if p.privileged {
+		dst = &net.IPAddr{IP: ip}
+	} else {
+		dst = &net.UDPAddr{IP: ip}
+	}
+
+	count = max(count, 1)
+	replies := make(chan *Reply, count)
+	keys := make([]string, 0, count)
+	defer func() {
+		p.m.Lock()
+		for _, key := range keys {
+			delete(p.pending, key)
+		}
+		p.m.Unlock()
+	}()
+
+	send := func() error {
+		seq := int(uint16(p.seq.Add(1)))
+		key := pendingKey(ip, seq)
+		p.m.Lock()
+		p.pending[key] = &pending{replies: replies, sent: time.Now()}
+		p.m.Unlock()
+		keys = append(keys, key)
+
+		msg := icmp.Message{
+			Type: typ,
+			Body: &icmp.Echo{ID: p.id, Seq: seq, Data: []byte("eagleeye")},
+		}
+		b, err := msg.Marshal(nil)
+		if err != nil {
+			return err
+		}
+		_, err = conn.WriteTo(b, dst)
+		return err
+	}
+
+	if err := send(); err != nil {
+		return nil, err
+	}
+	stats := &Statistics{Sent: 1}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case reply := <-replies:
+			stats.add(reply)
+			if stats.Received == count {
+				return stats, nil
+			}
+		case <-timer.C:
+			if stats.Received == 0 {
+				return nil, ErrTimeout
+			}
+			return stats, nil
+		case <-ticker.C:
+			if stats.Sent < count {
+				if err := send(); err != nil {
+					return nil, err
+				}
+				stats.Sent++
+			}
+		}
+	}
+}
+
+// receive 接收响应直到socket关闭
+func (p *Pinger) receive(conn *icmp.PacketConn) {
+	buf := make([]byte, 1500)
+	for {
+		var (
+			n    int
+			ttl  int
+			src  net.Addr
+			err  error
+			prot int
+		)
+		if pc := conn.IPv4PacketConn(); pc != nil {
+			var cm *ipv4.ControlMessage
+			n, cm, src, err = pc.ReadFrom(buf)
+			if cm != nil {
+				ttl = cm.TTL
+			}
+			prot = protocolICMP
+		} else {
+			var cm *ipv6.ControlMessage
+			n, cm, src, err = conn.IPv6PacketConn().ReadFrom(buf)
+			if cm != nil {
+				ttl = cm.HopLimit
+			}
+			prot = protocolIPv6ICMP
+		}
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		msg, err := icmp.ParseMessage(prot, buf[:n])
+		if err != nil || (msg.Type != ipv4.ICMPTypeEchoReply && msg.Type != ipv6.ICMPTypeEchoReply) {
+			continue
+		}
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok || (p.privileged && echo.ID != p.id) {
+			continue
+		}
+
+		var ip net.IP
+		switch addr := src.(type) {
+		case *net.IPAddr:
+			ip = addr.IP
+		case *net.UDPAddr:
+			ip = addr.IP
+		default:
+			continue
+		}
+
+		// 匹配后移除，忽略重复响应
+		key := pendingKey(ip, echo.Seq)
+		p.m.Lock()
+		req, ok := p.pending[key]
+		delete(p.pending, key)
+		p.m.Unlock()
+		if !ok {
+			continue
+		}
+
+		select {
+		case req.replies <- &Reply{Addr: ip.String(), TTL: ttl, RTT: time.Since(req.sent)}:
+		default:
+		}
+	}
+}
+
+func pendingKey(ip net.IP, seq int) string {
+	return net.JoinHostPort(ip.String(), strconv.Itoa(seq))
+}
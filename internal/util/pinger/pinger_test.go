@@ -0,0 +1,49 @@
+package pinger
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/util/privileges"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinger(t *testing.T) {
+	assert := assert.New(t)
+
+	p := NewPinger(privileges.IsPrivileged)
+
+	_, err := p.Ping(context.Background(), net.ParseIP("127.0.0.1"), 1, time.Second)
+	assert.ErrorIs(err, ErrPingerNotReady)
+
+	c, cancel := context.WithCancel(context.Background())
+	loopErr := make(chan error, 1)
+	go func() {
+		loopErr <- p.PingingLoop(c)
+	}()
+
+	select {
+	case err := <-loopErr:
+		// 当前环境不允许创建ICMP socket
+		t.Skipf("pinging loop failed: %v", err)
+	case <-p.WaitReady():
+	}
+
+	stats, err := p.Ping(c, net.ParseIP("127.0.0.1"), 3, time.Second)
+	assert.NoError(err)
+	assert.Equal("127.0.0.1", stats.Addr)
+	assert.NotZero(stats.TTL)
+	assert.Equal(3, stats.Sent)
+	assert.Equal(3, stats.Received)
+	assert.Zero(stats.Loss())
+	assert.LessOrEqual(stats.MinRTT, stats.AvgRTT)
+
+	// TEST-NET-3 地址不可达
+	_, err = p.Ping(c, net.ParseIP("203.0.113.1"), 1, 300*time.Millisecond)
+	assert.Error(err)
+
+	cancel()
+	assert.NoError(<-loopErr)
+}
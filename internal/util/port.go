@@ -1,15 +1,184 @@
 package util
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// 端口协议
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
 )
 
+// Port 带协议的端口
+type Port struct {
+	Protocol string
+	Port     int
+}
+
 func ParsePortsList(data string) ([]int, error) {
 	return parsePortsSlice(strings.Split(data, ","))
 }
 
+// ErrUnknownPortName 端口列表中的名称既不是端口集合也不是已知服务
+var ErrUnknownPortName = errors.New("unknown port name")
+
+// PortSets 命名端口集合(名称 -> 端口列表)
+type PortSets map[string]string
+
+// servicePorts 服务名称对应的端口
+var servicePorts = map[string]int{
+	"ftp":           21,
+	"ssh":           22,
+	"telnet":        23,
+	"smtp":          25,
+	"dns":           53,
+	"tftp":          69,
+	"pop3":          110,
+	"ntp":           123,
+	"msrpc":         135,
+	"netbios-ns":    137,
+	"netbios-ssn":   139,
+	"imap":          143,
+	"snmp":          161,
+	"ldap":          389,
+	"https":         443,
+	"smb":           445,
+	"syslog":        514,
+	"rsync":         873,
+	"imaps":         993,
+	"pop3s":         995,
+	"mssql":         1433,
+	"oracle":        1521,
+	"pptp":          1723,
+	"ssdp":          1900,
+	"nfs":           2049,
+	"docker":        2375,
+	"mysql":         3306,
+	"rdp":           3389,
+	"postgresql":    5432,
+	"vnc":           5900,
+	"winrm":         5985,
+	"redis":         6379,
+	"kubernetes":    6443,
+	"elasticsearch": 9200,
+	"memcached":     11211,
+	"mongodb":       27017,
+}
+
+// LoadPortSets 从yaml文件加载命名端口集合，文件名为空时返回nil
+//
+// 文件格式为"名称: 端口列表"，端口列表可引用其他集合及服务名称
+func LoadPortSets(filename string) (PortSets, error) {
+	if filename == "" {
+		return nil, nil
+	}
+
+	fBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read port sets file failed: %w", err)
+	}
+
+	var sets PortSets
+	err = yaml.Unmarshal(fBytes, &sets)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal port sets file failed: %w", err)
+	}
+
+	return lo.MapKeys(sets, func(_ string, name string) string { return strings.ToLower(name) }), nil
+}
+
+// ParsePortSpec 解析nmap风格的端口列表，如"T:80,443,U:53,161-162"
+//
+// "T:"/"U:"前缀指定其后端口的协议，直到出现下一个前缀为止；无前缀默认为TCP。
+// 除端口及端口范围外还支持"-"(全部端口)、"-1024"/"60000-"(开放范围)、
+// sets中的命名集合(按当前协议展开，如"U:top100")以及服务名称(如"ssh,rdp")
+func ParsePortSpec(data string, sets ...PortSets) ([]Port, error) {
+	p := &portSpecParser{
+		sets:      lo.Assign(sets...),
+		seen:      make(map[Port]struct{}),
+		expanding: make(map[string]struct{}),
+	}
+	if err := p.parse(data, ProtocolTCP); err != nil {
+		return nil, err
+	}
+	return p.ports, nil
+}
+
+type portSpecParser struct {
+	sets      PortSets
+	ports     []Port
+	seen      map[Port]struct{}
+	expanding map[string]struct{} // 正在展开的集合(检测循环引用)
+}
+
+func (p *portSpecParser) parse(data, protocol string) error {
+	for _, r := range strings.Split(data, ",") {
+		r = strings.TrimSpace(r)
+		switch {
+		case strings.HasPrefix(r, "T:"), strings.HasPrefix(r, "t:"):
+			protocol, r = ProtocolTCP, r[2:]
+		case strings.HasPrefix(r, "U:"), strings.HasPrefix(r, "u:"):
+			protocol, r = ProtocolUDP, r[2:]
+		}
+
+		if r != "" && unicode.IsLetter(rune(r[0])) {
+			if err := p.parseName(strings.ToLower(r), protocol); err != nil {
+				return err
+			}
+			continue
+		}
+
+		numbers, err := parsePortsSlice([]string{r})
+		if err != nil {
+			return err
+		}
+		for _, number := range numbers {
+			p.add(Port{Protocol: protocol, Port: number})
+		}
+	}
+	return nil
+}
+
+// parseName 展开命名集合或服务名称
+func (p *portSpecParser) parseName(name, protocol string) error {
+	if spec, ok := p.sets[name]; ok {
+		if _, ok := p.expanding[name]; ok {
+			return fmt.Errorf("port set '%s' references itself", name)
+		}
+		p.expanding[name] = struct{}{}
+		defer delete(p.expanding, name)
+
+		if err := p.parse(spec, protocol); err != nil {
+			return fmt.Errorf("invalid port set '%s': %w", name, err)
+		}
+		return nil
+	}
+
+	if number, ok := servicePorts[name]; ok {
+		p.add(Port{Protocol: protocol, Port: number})
+		return nil
+	}
+
+	return fmt.Errorf("%w: '%s'", ErrUnknownPortName, name)
+}
+
+func (p *portSpecParser) add(port Port) {
+	if _, ok := p.seen[port]; ok {
+		return
+	}
+	p.seen[port] = struct{}{}
+	p.ports = append(p.ports, port)
+}
+
 func parsePortsSlice(ranges []string) ([]int, error) {
 	var ports []int
 	for _, r := range ranges {
@@ -21,14 +190,21 @@ func parsePortsSlice(ranges []string) ([]int, error) {
 				return nil, fmt.Errorf("invalid port selection segment: '%s'", r)
 			}
 
-			p1, err := strconv.Atoi(parts[0])
-			if err != nil {
-				return nil, fmt.Errorf("invalid port number: '%s'", parts[0])
+			// 省略起止端口表示从1开始或到65535为止，"-"表示全部端口
+			p1, p2 := 1, 65535
+			var err error
+			if parts[0] != "" {
+				p1, err = strconv.Atoi(parts[0])
+				if err != nil {
+					return nil, fmt.Errorf("invalid port number: '%s'", parts[0])
+				}
 			}
 
-			p2, err := strconv.Atoi(parts[1])
-			if err != nil {
-				return nil, fmt.Errorf("invalid port number: '%s'", parts[1])
+			if parts[1] != "" {
+				p2, err = strconv.Atoi(parts[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid port number: '%s'", parts[1])
+				}
 			}
 
 			if p1 > p2 || p2 > 65535 {
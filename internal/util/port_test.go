@@ -1,6 +1,7 @@
 package util
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,3 +33,86 @@ func TestParsePortsList(t *testing.T) {
 	assert.Equal(4, ports[3])
 	assert.Equal(5, ports[4])
 }
+
+func TestParsePortSpec(t *testing.T) {
+	assert := assert.New(t)
+
+	ports, err := ParsePortSpec("80,443")
+	assert.NoError(err)
+	assert.Equal([]Port{{ProtocolTCP, 80}, {ProtocolTCP, 443}}, ports)
+
+	ports, err = ParsePortSpec("T:80,443,U:53,161-162")
+	assert.NoError(err)
+	assert.Equal([]Port{
+		{ProtocolTCP, 80},
+		{ProtocolTCP, 443},
+		{ProtocolUDP, 53},
+		{ProtocolUDP, 161},
+		{ProtocolUDP, 162},
+	}, ports)
+
+	ports, err = ParsePortSpec("U:53,T:53,u:53")
+	assert.NoError(err)
+	assert.Equal([]Port{{ProtocolUDP, 53}, {ProtocolTCP, 53}}, ports)
+
+	_, err = ParsePortSpec("U:abc")
+	assert.ErrorIs(err, ErrUnknownPortName)
+
+	_, err = ParsePortSpec("80-")
+	assert.NoError(err)
+
+	ports, err = ParsePortSpec("-")
+	assert.NoError(err)
+	assert.Len(ports, 65535)
+
+	ports, err = ParsePortSpec("65530-,-2")
+	assert.NoError(err)
+	assert.Len(ports, 8)
+
+	ports, err = ParsePortSpec("ssh,RDP,U:snmp")
+	assert.NoError(err)
+	assert.Equal([]Port{{ProtocolTCP, 22}, {ProtocolTCP, 3389}, {ProtocolUDP, 161}}, ports)
+
+	sets := PortSets{"web": "80,443,8080-8081", "infra": "web,ssh,T:22,U:dns"}
+	ports, err = ParsePortSpec("U:infra,9000", sets)
+	assert.NoError(err)
+	assert.Equal([]Port{
+		{ProtocolUDP, 80},
+		{ProtocolUDP, 443},
+		{ProtocolUDP, 8080},
+		{ProtocolUDP, 8081},
+		{ProtocolUDP, 22},
+		{ProtocolTCP, 22},
+		{ProtocolUDP, 53},
+		{ProtocolUDP, 9000},
+	}, ports)
+
+	_, err = ParsePortSpec("a", PortSets{"a": "b", "b": "a"})
+	assert.Error(err)
+	assert.NotErrorIs(err, ErrUnknownPortName)
+}
+
+func TestLoadPortSets(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := os.CreateTemp("", "port_sets.yaml")
+	assert.NoError(err)
+	_, err = f.WriteString("Web: 80,443\ndb: mysql,redis,T:web\n")
+	assert.NoError(err)
+
+	filename := f.Name()
+	defer os.Remove(filename)
+	f.Close()
+
+	sets, err := LoadPortSets(filename)
+	assert.NoError(err)
+	assert.Equal(PortSets{"web": "80,443", "db": "mysql,redis,T:web"}, sets)
+
+	ports, err := ParsePortSpec("db", sets)
+	assert.NoError(err)
+	assert.Equal([]Port{{ProtocolTCP, 3306}, {ProtocolTCP, 6379}, {ProtocolTCP, 80}, {ProtocolTCP, 443}}, ports)
+
+	sets, err = LoadPortSets("")
+	assert.NoError(err)
+	assert.Nil(sets)
+}
@@ -9,6 +9,15 @@ import (
 )
 
 func NewProgressbar(name string, size int64, silent ...bool) *progressbar.ProgressBar {
+	return newProgressbar(name, size, "req", silent...)
+}
+
+// NewPercentProgressbar 创建按百分比(0-100)更新的进度条
+func NewPercentProgressbar(name string, silent ...bool) *progressbar.ProgressBar {
+	return newProgressbar(name, 100, "%", silent...)
+}
+
+func newProgressbar(name string, size int64, unit string, silent ...bool) *progressbar.ProgressBar {
 	var writer io.Writer = os.Stdout
 	if len(silent) > 0 && silent[0] {
 		writer = io.Discard
@@ -22,7 +31,7 @@ func NewProgressbar(name string, size int64, silent ...bool) *progressbar.Progre
 		progressbar.OptionSetRenderBlankState(true),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetDescription(fmt.Sprintf("[%s]", name)),
-		progressbar.OptionSetItsString("req"),
+		progressbar.OptionSetItsString(unit),
 		progressbar.OptionShowIts(),
 		progressbar.OptionFullWidth(),
 		progressbar.OptionSetTheme(progressbar.Theme{
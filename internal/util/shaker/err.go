@@ -14,3 +14,5 @@ func (e *timeoutError) Temporary() bool { return true }
 type connectError struct {
 	error
 }
+
+func (e *connectError) Unwrap() error { return e.error }
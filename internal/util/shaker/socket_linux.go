@@ -121,7 +121,14 @@ func parseSockAddr(addr string) (sAddr unix.Sockaddr, family int, err error) {
 	if ip := tAddr.IP.To16(); ip != nil {
 		var addr16 [net.IPv6len]byte
 		copy(addr16[:], ip)
-		sAddr = &unix.SockaddrInet6{Port: tAddr.Port, Addr: addr16}
+		sAddr6 := &unix.SockaddrInet6{Port: tAddr.Port, Addr: addr16}
+		// 链路本地地址需要指定网卡(fe80::1%eth0)
+		if tAddr.Zone != "" {
+			if iface, zErr := net.InterfaceByName(tAddr.Zone); zErr == nil {
+				sAddr6.ZoneId = uint32(iface.Index)
+			}
+		}
+		sAddr = sAddr6
 		family = unix.AF_INET6
 		return
 	}
@@ -0,0 +1,91 @@
+package synscan
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+)
+
+// TCP标志位
+const (
+	flagSYN = 0x02
+	flagRST = 0x04
+	flagACK = 0x10
+)
+
+const (
+	protocolTCP = 6
+	// synHeaderLen SYN报文头长度(20字节固定头+4字节MSS选项)
+	synHeaderLen = 24
+	synWindow    = 1024
+	synMSS       = 1460
+)
+
+var errShortSegment = errors.New("tcp segment too short")
+
+// segment 解析后的TCP报文头
+type segment struct {
+	srcPort uint16
+	dstPort uint16
+	seq     uint32
+	ack     uint32
+	flags   uint8
+}
+
+// buildSYN 构造SYN报文(不含IP头，由内核填充)，校验和基于src/dst计算伪首部
+func buildSYN(src, dst net.IP, srcPort, dstPort uint16, seq uint32) []byte {
+	b := make([]byte, synHeaderLen)
+	binary.BigEndian.PutUint16(b[0:2], srcPort)
+	binary.BigEndian.PutUint16(b[2:4], dstPort)
+	binary.BigEndian.PutUint32(b[4:8], seq)
+	// ack为0
+	b[12] = (synHeaderLen / 4) << 4
+	b[13] = flagSYN
+	binary.BigEndian.PutUint16(b[14:16], synWindow)
+	// 校验和与紧急指针稍后填充/为0
+
+	// MSS选项
+	b[20], b[21] = 2, 4
+	binary.BigEndian.PutUint16(b[22:24], synMSS)
+
+	binary.BigEndian.PutUint16(b[16:18], checksum(src, dst, b))
+	return b
+}
+
+// parseSegment 解析TCP报文头
+func parseSegment(b []byte) (*segment, error) {
+	if len(b) < 20 {
+		return nil, errShortSegment
+	}
+	return &segment{
+		srcPort: binary.BigEndian.Uint16(b[0:2]),
+		dstPort: binary.BigEndian.Uint16(b[2:4]),
+		seq:     binary.BigEndian.Uint32(b[4:8]),
+		ack:     binary.BigEndian.Uint32(b[8:12]),
+		flags:   b[13],
+	}, nil
+}
+
+// checksum 计算IPv4 TCP校验和(伪首部+报文，报文中的校验和字段需为0)
+func checksum(src, dst net.IP, tcp []byte) uint16 {
+	var sum uint32
+	add := func(b []byte) {
+		for i := 0; i+1 < len(b); i += 2 {
+			sum += uint32(b[i])<<8 | uint32(b[i+1])
+		}
+		if len(b)%2 == 1 {
+			sum += uint32(b[len(b)-1]) << 8
+		}
+	}
+
+	add(src.To4())
+	add(dst.To4())
+	sum += protocolTCP
+	sum += uint32(len(tcp))
+	add(tcp)
+
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
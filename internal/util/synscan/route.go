@@ -0,0 +1,141 @@
+package synscan
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// routeTable 主路由表路径
+const routeTable = "/proc/net/route"
+
+// rtfUp 路由可用标志
+const rtfUp = 0x1
+
+// route 路由表项(IPv4)
+type route struct {
+	dst    *net.IPNet
+	src    net.IP // 出口接口的本地地址
+	metric int
+}
+
+// loadRoutes 读取主路由表，按前缀长度(长者优先)及跃点数排序
+func loadRoutes() ([]route, error) {
+	f, err := os.Open(routeTable)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseRoutes(f, interfaceAddrs)
+}
+
+// parseRoutes 解析/proc/net/route格式的路由表，addrs返回接口的地址
+//
+// 出口地址按接口解析一次：优先选择与网关(直连路由为目标网段)同网段的地址，否则使用接口的第一个IPv4地址
+func parseRoutes(r io.Reader, addrs func(iface string) []net.Addr) ([]route, error) {
+	ifaceAddrs := make(map[string][]net.Addr)
+
+	var routes []route
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // 表头
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&rtfUp == 0 {
+			continue
+		}
+		dst, err1 := parseHexIP(fields[1])
+		gateway, err2 := parseHexIP(fields[2])
+		mask, err3 := parseHexIP(fields[7])
+		metric, err4 := strconv.Atoi(fields[6])
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+
+		iface := fields[0]
+		if _, ok := ifaceAddrs[iface]; !ok {
+			ifaceAddrs[iface] = addrs(iface)
+		}
+		next := gateway
+		if next.Equal(net.IPv4zero) {
+			next = dst
+		}
+
+		routes = append(routes, route{
+			dst:    &net.IPNet{IP: dst.Mask(net.IPMask(mask)), Mask: net.IPMask(mask)},
+			src:    sourceOf(ifaceAddrs[iface], next),
+			metric: metric,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(routes, func(a, b route) int {
+		ones1, _ := a.dst.Mask.Size()
+		ones2, _ := b.dst.Mask.Size()
+		if ones1 != ones2 {
+			return ones2 - ones1
+		}
+		return a.metric - b.metric
+	})
+	return routes, nil
+}
+
+// lookupRoute 按最长前缀匹配目标的出口地址
+func lookupRoute(routes []route, ip net.IP) (net.IP, bool) {
+	for _, r := range routes {
+		if r.dst.Contains(ip) {
+			return r.src, r.src != nil
+		}
+	}
+	return nil, false
+}
+
+// sourceOf 在接口地址中选择与next同网段的IPv4地址，没有时使用第一个IPv4地址
+func sourceOf(addrs []net.Addr, next net.IP) net.IP {
+	var first net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil {
+			continue
+		}
+		if ipNet.Contains(next) {
+			return ipNet.IP.To4()
+		}
+		if first == nil {
+			first = ipNet.IP.To4()
+		}
+	}
+	return first
+}
+
+func interfaceAddrs(name string) []net.Addr {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil
+	}
+	addrs, _ := iface.Addrs()
+	return addrs
+}
+
+// parseHexIP 解析路由表中的地址(小端序十六进制)
+func parseHexIP(s string) (net.IP, error) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.LittleEndian.PutUint32(ip, uint32(v))
+	return ip, nil
+}
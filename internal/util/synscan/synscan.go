@@ -0,0 +1,240 @@
+package synscan
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"net"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTimeout               = errors.New("syn scan timeout")
+	ErrPortClosed            = errors.New("port closed")
+	ErrScannerAlreadyStarted = errors.New("syn scanner was already started")
+	ErrScannerNotReady       = errors.New("syn scanner is not ready")
+	ErrUnsupportedFamily     = errors.New("unsupported address family")
+	ErrUnsupportedPlatform   = errors.New("syn scan is only supported on linux")
+)
+
+// pendingKey 等待响应的目标
+type pendingKey struct {
+	ip   [4]byte
+	port uint16
+}
+
+// Scanner 半开放(SYN)端口扫描引擎
+//
+// 所有探测共用同一个raw socket发送SYN，接收循环按(地址, 端口)匹配SYN/ACK或RST，
+// 并通过ack校验探测时生成的序列号，过滤无关报文。内核未建立对应连接，收到SYN/ACK后会自动回复RST，
+// 因此不占用文件描述符与本地端口。仅支持IPv4，需要CAP_NET_RAW权限
+type Scanner struct {
+	srcPort uint16
+	secret  [8]byte
+
+	m       sync.Mutex
+	pending map[pendingKey]chan<- error
+	conn    *net.IPConn
+	started bool
+	isReady chan struct{}
+
+	// 主路由表(启动时读取)，目标的本地地址按路由的出口接口选择
+	routes []route
+}
+
+// NewScanner 实例化
+func NewScanner() *Scanner {
+	s := &Scanner{
+		pending: make(map[pendingKey]chan<- error),
+		isReady: make(chan struct{}),
+	}
+	rand.Read(s.secret[:])
+
+	var b [2]byte
+	rand.Read(b[:])
+	// 使用高位端口作为源端口，避开常见的本地临时端口范围
+	s.srcPort = 61000 + binary.BigEndian.Uint16(b[:])%4000
+	return s
+}
+
+// ScanningLoop 打开raw socket并接收响应，直到ctx结束
+func (s *Scanner) ScanningLoop(ctx context.Context) error {
+	if err := s.listen(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.receive()
+	}()
+
+	close(s.isReady)
+
+	<-ctx.Done()
+	s.conn.Close()
+	<-done
+
+	return nil
+}
+
+// WaitReady 等待socket就绪
+func (s *Scanner) WaitReady() <-chan struct{} {
+	return s.isReady
+}
+
+func (s *Scanner) listen() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.started {
+		return ErrScannerAlreadyStarted
+	}
+	if runtime.GOOS != "linux" {
+		return ErrUnsupportedPlatform
+	}
+
+	// 接收所有入站TCP报文(不含IP头)，发送时由内核构造IP头
+	conn, err := net.ListenIP("ip4:tcp", &net.IPAddr{IP: net.IPv4zero})
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.started = true
+	// 读取失败时由内核逐个选择
+	s.routes, _ = loadRoutes()
+	return nil
+}
+
+// Check 向ip:port发送SYN，收到SYN/ACK返回nil，收到RST返回ErrPortClosed，超时返回ErrTimeout
+func (s *Scanner) Check(ctx context.Context, ip net.IP, port int, timeout time.Duration) error {
+	select {
+	case <-s.isReady:
+	default:
+		return ErrScannerNotReady
+	}
+
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return ErrUnsupportedFamily
+	}
+
+	src, err := s.source(ip4)
+	if err != nil {
+		return err
+	}
+
+	key := pendingKey{ip: [4]byte(ip4), port: uint16(port)}
+	result := make(chan error, 1)
+	s.m.Lock()
+	s.pending[key] = result
+	s.m.Unlock()
+	defer func() {
+		s.m.Lock()
+		if s.pending[key] == result {
+			delete(s.pending, key)
+		}
+		s.m.Unlock()
+	}()
+
+	syn := buildSYN(src, ip4, s.srcPort, uint16(port), s.cookie(key))
+	if _, err := s.conn.WriteTo(syn, &net.IPAddr{IP: ip4}); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-result:
+		return err
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
+
+// source 获取发往ip时使用的本地地址
+//
+// 按主路由表的出口接口选择；不在主路由表中(如本机地址)时通过UDP连接由内核选择路由(不发送数据)
+func (s *Scanner) source(ip net.IP) (net.IP, error) {
+	if ip.IsLoopback() {
+		return ip, nil
+	}
+	if src, ok := lookupRoute(s.routes, ip); ok {
+		return src, nil
+	}
+
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: ip, Port: 9})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP.To4(), nil
+}
+
+// cookie 根据目标生成SYN序列号，响应的ack需为cookie+1
+func (s *Scanner) cookie(key pendingKey) uint32 {
+	h := fnv.New32a()
+	h.Write(s.secret[:])
+	h.Write(key.ip[:])
+	h.Write([]byte{byte(key.port >> 8), byte(key.port)})
+	return h.Sum32()
+}
+
+// receive 接收响应直到socket关闭
+func (s *Scanner) receive() {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		seg, err := parseSegment(buf[:n])
+		if err != nil || seg.dstPort != s.srcPort {
+			continue
+		}
+		ip4 := addr.(*net.IPAddr).IP.To4()
+		if ip4 == nil {
+			continue
+		}
+
+		key := pendingKey{ip: [4]byte(ip4), port: seg.srcPort}
+		if seg.ack != s.cookie(key)+1 {
+			continue
+		}
+
+		var result error
+		switch {
+		case seg.flags&(flagSYN|flagACK) == flagSYN|flagACK:
+		case seg.flags&flagRST != 0:
+			result = ErrPortClosed
+		default:
+			continue
+		}
+
+		// 匹配后移除，忽略重复响应
+		s.m.Lock()
+		ch, ok := s.pending[key]
+		delete(s.pending, key)
+		s.m.Unlock()
+		if !ok {
+			continue
+		}
+
+		select {
+		case ch <- result:
+		default:
+		}
+	}
+}
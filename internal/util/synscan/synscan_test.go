@@ -0,0 +1,109 @@
+package synscan
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/internal/util/privileges"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSYN(t *testing.T) {
+	assert := assert.New(t)
+
+	src, dst := net.ParseIP("192.168.1.2"), net.ParseIP("192.168.1.1")
+	b := buildSYN(src, dst, 61000, 443, 0x01020304)
+	assert.Len(b, synHeaderLen)
+
+	seg, err := parseSegment(b)
+	assert.NoError(err)
+	assert.EqualValues(61000, seg.srcPort)
+	assert.EqualValues(443, seg.dstPort)
+	assert.EqualValues(0x01020304, seg.seq)
+	assert.EqualValues(flagSYN, seg.flags)
+
+	// 包含校验和重新计算结果为0
+	assert.Zero(checksum(src, dst, b))
+
+	_, err = parseSegment(b[:10])
+	assert.ErrorIs(err, errShortSegment)
+}
+
+func TestCheck(t *testing.T) {
+	if !privileges.IsPrivileged {
+		t.Skip("syn scan requires privileges")
+	}
+	assert := assert.New(t)
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.NoError(err)
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	s := NewScanner()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	loopErr := make(chan error, 1)
+	go func() { loopErr <- s.ScanningLoop(ctx) }()
+
+	select {
+	case err := <-loopErr:
+		t.Skipf("syn scanner unavailable: %v", err)
+	case <-s.WaitReady():
+	}
+
+	assert.NoError(s.Check(ctx, net.ParseIP("127.0.0.1"), port, time.Second))
+
+	ln.Close()
+	assert.ErrorIs(s.Check(ctx, net.ParseIP("127.0.0.1"), port, time.Second), ErrPortClosed)
+
+	assert.ErrorIs(s.Check(ctx, net.ParseIP("::1"), port, time.Second), ErrUnsupportedFamily)
+}
+
+func TestParseRoutes(t *testing.T) {
+	assert := assert.New(t)
+
+	table := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+eth0	0001A8C0	00000000	0001	0	0	100	00FFFFFF	0	0	0
+eth1	00000A0A	00000000	0001	0	0	0	0000FFFF	0	0	0
+eth2	00000B0A	00000000	0000	0	0	0	0000FFFF	0	0	0
+`
+	addrs := map[string][]net.Addr{
+		"eth0": {
+			&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+			&net.IPNet{IP: net.ParseIP("192.168.1.2").To4(), Mask: net.CIDRMask(24, 32)},
+		},
+		"eth1": {&net.IPNet{IP: net.ParseIP("10.10.0.2").To4(), Mask: net.CIDRMask(16, 32)}},
+	}
+	lookups := 0
+	routes, err := parseRoutes(strings.NewReader(table), func(iface string) []net.Addr {
+		lookups++
+		return addrs[iface]
+	})
+	assert.NoError(err)
+	// 未启用的路由忽略，接口地址只解析一次
+	assert.Len(routes, 3)
+	assert.Equal(2, lookups)
+
+	tests := []struct {
+		ip  string
+		src string
+	}{
+		{"192.168.1.100", "192.168.1.2"},
+		{"10.10.3.4", "10.10.0.2"},
+		{"8.8.8.8", "192.168.1.2"},
+		{"10.11.0.1", "192.168.1.2"},
+	}
+	for _, test := range tests {
+		src, ok := lookupRoute(routes, net.ParseIP(test.ip).To4())
+		assert.True(ok, test.ip)
+		assert.Equal(test.src, src.String(), test.ip)
+	}
+
+	_, ok := lookupRoute(nil, net.ParseIP("8.8.8.8").To4())
+	assert.False(ok)
+}
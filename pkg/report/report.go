@@ -112,6 +112,7 @@ func prepareConfig(o *options) *config {
 			Ports:   []*portScanningPorts{},
 			IPPorts: []*portScanningIPPorts{},
 		},
+		Certificate: &certificate{},
 		Vulnerability: &vulnerability{
 			Ports:   []*vulnerabilityPort{},
 			Assets:  []*vulnerabilityAsset{},
@@ -130,6 +131,16 @@ func prepareConfig(o *options) *config {
 		reportCfg.Discovery.CountPer = fmt.Sprintf("%.2f%%", float64(reportCfg.Discovery.Count)/float64(reportCfg.Discovery.TotalCount)*100)
 		reportCfg.Discovery.UnusedCount = reportCfg.Discovery.TotalCount - reportCfg.Discovery.Count
 		reportCfg.Discovery.UnusedCountPer = fmt.Sprintf("%.2f%%", float64(reportCfg.Discovery.UnusedCount)/float64(reportCfg.Discovery.TotalCount)*100)
+
+		// 仅ICMP探测存在延迟与丢包数据
+		rttItems := lo.Filter(o.result.HostDiscoveryResult.Items, func(item *types.PingResultItem, _ int) bool {
+			return item.Active && item.InitialTTL != 0
+		})
+		if len(rttItems) != 0 {
+			reportCfg.Discovery.RTTState = true
+			reportCfg.Discovery.AvgRTT = fmt.Sprintf("%.2fms", lo.SumBy(rttItems, func(item *types.PingResultItem) float64 { return item.AvgRTT })/float64(len(rttItems)))
+			reportCfg.Discovery.AvgLoss = fmt.Sprintf("%.2f%%", lo.SumBy(rttItems, func(item *types.PingResultItem) float64 { return item.Loss })/float64(len(rttItems)))
+		}
 	}
 
 	// 端口扫描
@@ -139,10 +150,8 @@ func prepareConfig(o *options) *config {
 		reportCfg.PortScanning.TotalCount = len(lo.UniqBy(o.result.PortScanningResult.Items, func(item *types.PortResultItem) string {
 			return item.IP
 		}))
-		portIPs := lo.ToPairs(lo.CountValuesBy(o.result.PortScanningResult.Items, func(item *types.PortResultItem) int {
-			return item.Port
-		}))
-		slices.SortFunc(portIPs, func(a, b lo.Entry[int, int]) int {
+		portIPs := lo.ToPairs(lo.CountValuesBy(o.result.PortScanningResult.Items, portLabel))
+		slices.SortFunc(portIPs, func(a, b lo.Entry[string, int]) int {
 			return b.Value - a.Value
 		})
 		for i, item := range portIPs {
@@ -150,7 +159,7 @@ func prepareConfig(o *options) *config {
 				break
 			}
 			reportCfg.PortScanning.Ports = append(reportCfg.PortScanning.Ports, &portScanningPorts{
-				Port:  strconv.Itoa(item.Key),
+				Port:  item.Key,
 				Count: item.Value,
 			})
 		}
@@ -158,7 +167,7 @@ func prepareConfig(o *options) *config {
 			return item.IP
 		}), func(items []*types.PortResultItem, _ string) []string {
 			return lo.Map(items, func(item *types.PortResultItem, _ int) string {
-				return cast.ToString(item.Port)
+				return portLabel(item)
 			})
 		}))
 		slices.SortFunc(ipPorts, func(a, b lo.Entry[string, []string]) int {
@@ -175,6 +184,22 @@ func prepareConfig(o *options) *config {
 		}
 	}
 
+	// 证书采集
+	if o.result.CertificateResult != nil && len(o.result.CertificateResult.Items) != 0 {
+		items := o.result.CertificateResult.Items
+		expiring := time.Now().AddDate(0, 0, 30)
+
+		reportCfg.Certificate.State = true
+		reportCfg.Certificate.TotalCount = len(items)
+		reportCfg.Certificate.ExpiredCount = lo.CountBy(items, func(item *types.CertResultItem) bool { return item.Expired })
+		reportCfg.Certificate.ExpiringCount = lo.CountBy(items, func(item *types.CertResultItem) bool {
+			return !item.Expired && item.NotAfter.Before(expiring)
+		})
+		reportCfg.Certificate.WeakSignatureCount = lo.CountBy(items, func(item *types.CertResultItem) bool { return item.WeakSignature })
+		reportCfg.Certificate.WeakKeyCount = lo.CountBy(items, func(item *types.CertResultItem) bool { return item.WeakKey })
+		reportCfg.Certificate.SelfSignedCount = lo.CountBy(items, func(item *types.CertResultItem) bool { return item.SelfSigned })
+	}
+
 	if len(o.jobIndexes) != 0 && lo.EveryBy(o.jobIndexes, func(item int) bool {
 		return item < len(o.result.JobResults)
 	}) {
@@ -537,3 +562,11 @@ func ticks(values []chart.Value) []chart.Tick {
 		return chart.Tick{Value: float64(v), Label: cast.ToString(v)}
 	})
 }
+
+// portLabel 端口展示名称(UDP端口附加协议后缀)
+func portLabel(item *types.PortResultItem) string {
+	if item.Protocol == "udp" {
+		return fmt.Sprintf("%d/udp", item.Port)
+	}
+	return strconv.Itoa(item.Port)
+}
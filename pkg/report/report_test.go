@@ -121,7 +121,7 @@ func TestGenerate(t *testing.T) {
 			HostDiscoveryResult: &types.PingResult{
 				EntryID: "123456",
 				Items: []*types.PingResultItem{
-					{IP: "192.168.1.2", OS: "Linux", TTL: 64, Active: true},
+					{IP: "192.168.1.2", OS: "Linux", TTL: 64, Active: true, Method: "icmp", InitialTTL: 64, MinRTT: 0.8, AvgRTT: 1.2, Loss: 0},
 					{IP: "192.168.1.3", OS: "Linux", TTL: 64, Active: false},
 					{IP: "192.168.1.4", OS: "Linux", TTL: 64, Active: true},
 					{IP: "192.168.1.5", OS: "Linux", TTL: 64, Active: false},
@@ -339,3 +339,52 @@ func TestGenerateWithSomeEmpty(t *testing.T) {
 
 	assert.Nil(err)
 }
+
+func TestPrepareDiscovery(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := prepareConfig(&options{
+		result: &types.EntryResult{
+			HostDiscoveryResult: &types.PingResult{
+				Items: []*types.PingResultItem{
+					{IP: "192.168.1.2", OS: "linux", TTL: 62, Active: true, Method: "icmp", InitialTTL: 64, AvgRTT: 1, Loss: 0},
+					{IP: "192.168.1.3", OS: "windows", TTL: 126, Active: true, Method: "icmp", InitialTTL: 128, AvgRTT: 3, Loss: 50},
+					{IP: "192.168.1.4", Active: true, Method: "tcp"},
+					{IP: "192.168.1.5", Active: false},
+				},
+			},
+		},
+	})
+
+	assert.True(cfg.Discovery.State)
+	assert.Equal(4, cfg.Discovery.TotalCount)
+	assert.Equal(3, cfg.Discovery.Count)
+	assert.True(cfg.Discovery.RTTState)
+	assert.Equal("2.00ms", cfg.Discovery.AvgRTT)
+	assert.Equal("25.00%", cfg.Discovery.AvgLoss)
+}
+
+func TestPrepareCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	cfg := prepareConfig(&options{
+		result: &types.EntryResult{
+			CertificateResult: &types.CertResult{
+				Items: []*types.CertResultItem{
+					{HostPort: "192.168.1.2:443", NotAfter: now.AddDate(1, 0, 0)},
+					{HostPort: "192.168.1.3:443", NotAfter: now.AddDate(0, 0, 10), WeakKey: true},
+					{HostPort: "192.168.1.4:443", NotAfter: now.AddDate(0, 0, -1), Expired: true, WeakSignature: true, SelfSigned: true},
+				},
+			},
+		},
+	})
+
+	assert.True(cfg.Certificate.State)
+	assert.Equal(3, cfg.Certificate.TotalCount)
+	assert.Equal(1, cfg.Certificate.ExpiredCount)
+	assert.Equal(1, cfg.Certificate.ExpiringCount)
+	assert.Equal(1, cfg.Certificate.WeakSignatureCount)
+	assert.Equal(1, cfg.Certificate.WeakKeyCount)
+	assert.Equal(1, cfg.Certificate.SelfSignedCount)
+}
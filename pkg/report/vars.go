@@ -14,6 +14,7 @@ type config struct {
 	Meta          *meta          //元数据
 	Discovery     *discovery     //探活数据
 	PortScanning  *portScanning  //端口扫描数据
+	Certificate   *certificate   //证书数据
 	Vulnerability *vulnerability //漏洞数据
 }
 
@@ -34,6 +35,9 @@ type discovery struct {
 	CountPer       string //存活率
 	UnusedCount    int    //未使用数
 	UnusedCountPer string //未使用率
+	AvgRTT         string //存活主机平均延迟
+	AvgLoss        string //存活主机平均丢包率
+	RTTState       bool   //是否存在延迟数据(ICMP探测)
 	State          bool
 }
 
@@ -54,6 +58,16 @@ type portScanningIPPorts struct {
 	Ports []string //端口
 }
 
+type certificate struct {
+	TotalCount         int //证书总数
+	ExpiredCount       int //已过期数
+	ExpiringCount      int //30天内过期数
+	WeakSignatureCount int //弱签名算法数
+	WeakKeyCount       int //弱密钥数
+	SelfSignedCount    int //自签名数
+	State              bool
+}
+
 type vulnerability struct {
 	TotalCount    int                    //漏洞资产数
 	TypeCount     int                    //漏洞类型数
@@ -10,10 +10,12 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/EscapeBearSecond/falcon/internal/checkpoint"
 	core "github.com/EscapeBearSecond/falcon/internal/engine"
 	"github.com/EscapeBearSecond/falcon/internal/global"
 	"github.com/EscapeBearSecond/falcon/internal/job"
 	"github.com/EscapeBearSecond/falcon/internal/mapper/vuln"
+	"github.com/EscapeBearSecond/falcon/internal/pause"
 	"github.com/EscapeBearSecond/falcon/internal/scanner"
 	"github.com/EscapeBearSecond/falcon/internal/stage"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
@@ -94,12 +96,18 @@ func (e *EagleeyeEngine) RemoveFiles(entryIDs ...string) {
 	}
 }
 
+// Resumable 条目目录下是否存在断点(可通过WithResume继续执行)
+func (e *EagleeyeEngine) Resumable(entryID string) bool {
+	_, err := os.Stat(filepath.Join(e.dir, entryID, checkpoint.Filename))
+	return err == nil
+}
+
 // Close 关闭引擎
 func (e *EagleeyeEngine) Close() {
 	e.m.Lock()
 	defer e.m.Unlock()
 	for _, entry := range e.entries {
-		if entry.state.Load() == running {
+		if state := entry.state.Load(); state == running || state == paused {
 			entry.stop()
 		}
 	}
@@ -111,6 +119,7 @@ const (
 	initial uint32 = 0
 	running uint32 = 1
 	stopped uint32 = 2
+	paused  uint32 = 3
 )
 
 // NewEntry 条目
@@ -124,6 +133,11 @@ type EagleeyeEntry struct {
 	result       *types.EntryResult
 	err          error
 	stageManager *stage.Manager
+	pauser       *pause.Controller
+	pm           sync.Mutex // 暂停与继续互斥，保证状态与暂停控制一致
+	checkpoint   *checkpoint.Checkpoint
+	stream       *stream                       // 实时结果(未开启时为nil)
+	replays      []func(context.Context) error // 回放由断点恢复的已完成阶段的结果
 }
 
 // NewEntry 创建条目
@@ -154,17 +168,46 @@ func (e *EagleeyeEngine) NewEntry(options *types.Options, extraOpts ...ExtraOpti
 	}
 
 	stageManager := stage.NewManager()
+	pauser := pause.New()
 
 	coreOptions := []core.Option{
 		core.WithTargets(o.Targets),
 		core.WithDisableBanner(true),
 		core.WithStageManager(stageManager),
+		core.WithSeed(o.Seed),
+		core.WithPipeline(o.Pipeline),
+		core.WithInteractsh(o.Interactsh),
 	}
 
 	if len(o.ExcludeTargets) > 0 {
 		coreOptions = append(coreOptions, core.WithExcludeTargets(o.ExcludeTargets))
 	}
 
+	// 开启断点续扫或继续执行中断的条目时记录执行进度
+	var cp *checkpoint.Checkpoint
+	cpFile := filepath.Join(e.dir, entryID, checkpoint.Filename)
+	if extras.resume {
+		cp, err = checkpoint.Load(cpFile)
+		if err != nil {
+			return nil, err
+		}
+	} else if o.Checkpoint.Use {
+		cp = checkpoint.New()
+	}
+	if cp != nil {
+		interval, err := time.ParseDuration(o.Checkpoint.Interval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("%w: invalid interval %q", types.ErrInvalidCheckpoint, o.Checkpoint.Interval)
+		}
+		coreOptions = append(coreOptions, core.WithCheckpoint(cp, cpFile, interval))
+	}
+	replays := make(map[string]func(context.Context) error)
+
+	var rs *stream
+	if extras.stream {
+		rs = newStream(extras.streamBuffer)
+	}
+
 	entryResult := &types.EntryResult{
 		EntryID:        entryID,
 		JobResults:     make([]*types.JobResult, 0, len(o.Jobs)),
@@ -173,24 +216,43 @@ func (e *EagleeyeEngine) NewEntry(options *types.Options, extraOpts ...ExtraOpti
 	}
 
 	if o.PortScanning.Use {
+		portCallback := func(ctx context.Context, pr *types.PortResult) error {
+			// 断点回放的结果不包含条目ID
+			pr.EntryID = entryID
+			if err := saveResult(cp, checkpoint.StagePortScanning, pr); err != nil {
+				return err
+			}
+			entryResult.PortScanningResult = pr
+			if o.PortScanning.ResultCallback != nil {
+				return o.PortScanning.ResultCallback(ctx, pr)
+			}
+			return nil
+		}
+		replays[checkpoint.StagePortScanning], err = replayResult(cp, checkpoint.StagePortScanning, portCallback)
+		if err != nil {
+			return nil, err
+		}
+
 		portScanner, err := scanner.NewPortScannerV3(&scanner.PortScannerConfig{
-			Ports:       o.PortScanning.Ports,
-			Timeout:     o.PortScanning.Timeout,
-			Count:       o.PortScanning.Count,
-			Format:      o.PortScanning.Format,
-			RateLimit:   o.PortScanning.RateLimit,
-			Concurrency: o.PortScanning.Concurrency,
-			ResultCallback: func(ctx context.Context, pr *types.PortResult) error {
-				entryResult.PortScanningResult = pr
-				if o.PortScanning.ResultCallback != nil {
-					return o.PortScanning.ResultCallback(ctx, pr)
-				}
-				return nil
-			},
-			EntryID:      entryID,
-			Silent:       true,
-			Directory:    e.dir,
-			StageManager: stageManager,
+			Ports:            o.PortScanning.Ports,
+			ExcludePorts:     o.PortScanning.ExcludePorts,
+			PortSets:         o.PortScanning.PortSets,
+			Mode:             o.PortScanning.Mode,
+			ServiceDetection: o.PortScanning.ServiceDetection,
+			Timeout:          o.PortScanning.Timeout,
+			Count:            o.PortScanning.Count,
+			Format:           o.PortScanning.Format,
+			RateLimit:        o.PortScanning.RateLimit,
+			HostRateLimit:    o.PortScanning.HostRateLimit,
+			Adaptive:         o.PortScanning.Adaptive,
+			Concurrency:      o.PortScanning.Concurrency,
+			ResultCallback:   portCallback,
+			ItemCallback:     rs.portItemCallback(entryID),
+			EntryID:          entryID,
+			Silent:           true,
+			Directory:        e.dir,
+			StageManager:     stageManager,
+			Pause:            pauser,
 		})
 		if err != nil {
 			return nil, err
@@ -198,24 +260,78 @@ func (e *EagleeyeEngine) NewEntry(options *types.Options, extraOpts ...ExtraOpti
 		coreOptions = append(coreOptions, core.WithPortScanner(portScanner))
 	}
 
+	if o.DNSResolution.Use {
+		dnsCallback := func(ctx context.Context, dr *types.DNSResult) error {
+			// 断点回放的结果不包含条目ID
+			dr.EntryID = entryID
+			if err := saveResult(cp, checkpoint.StageDNSResolution, dr); err != nil {
+				return err
+			}
+			entryResult.DNSResolutionResult = dr
+			if o.DNSResolution.ResultCallback != nil {
+				return o.DNSResolution.ResultCallback(ctx, dr)
+			}
+			return nil
+		}
+		replays[checkpoint.StageDNSResolution], err = replayResult(cp, checkpoint.StageDNSResolution, dnsCallback)
+		if err != nil {
+			return nil, err
+		}
+
+		dnsResolver, err := scanner.NewDNSResolver(&scanner.DNSResolverConfig{
+			Resolvers:      o.DNSResolution.Resolvers,
+			Timeout:        o.DNSResolution.Timeout,
+			Count:          o.DNSResolution.Count,
+			Format:         o.DNSResolution.Format,
+			RateLimit:      o.DNSResolution.RateLimit,
+			Concurrency:    o.DNSResolution.Concurrency,
+			ResultCallback: dnsCallback,
+			EntryID:        entryID,
+			Silent:         true,
+			Directory:      e.dir,
+			StageManager:   stageManager,
+			Pause:          pauser,
+		})
+		if err != nil {
+			return nil, err
+		}
+		coreOptions = append(coreOptions, core.WithDNSResolver(dnsResolver))
+	}
+
 	if o.HostDiscovery.Use {
+		pingCallback := func(ctx context.Context, pr *types.PingResult) error {
+			// 断点回放的结果不包含条目ID
+			pr.EntryID = entryID
+			if err := saveResult(cp, checkpoint.StageHostDiscovery, pr); err != nil {
+				return err
+			}
+			entryResult.HostDiscoveryResult = pr
+			if o.HostDiscovery.ResultCallback != nil {
+				return o.HostDiscovery.ResultCallback(ctx, pr)
+			}
+			return nil
+		}
+		replays[checkpoint.StageHostDiscovery], err = replayResult(cp, checkpoint.StageHostDiscovery, pingCallback)
+		if err != nil {
+			return nil, err
+		}
+
 		hostDiscovery, err := scanner.NewHostDiscoverer(&scanner.HostDiscovererConfig{
-			Timeout:     o.HostDiscovery.Timeout,
-			Count:       o.HostDiscovery.Count,
-			Format:      o.HostDiscovery.Format,
-			RateLimit:   o.HostDiscovery.RateLimit,
-			Concurrency: o.HostDiscovery.Concurrency,
-			ResultCallback: func(ctx context.Context, pr *types.PingResult) error {
-				entryResult.HostDiscoveryResult = pr
-				if o.HostDiscovery.ResultCallback != nil {
-					return o.HostDiscovery.ResultCallback(ctx, pr)
-				}
-				return nil
-			},
-			EntryID:      entryID,
-			Silent:       true,
-			Directory:    e.dir,
-			StageManager: stageManager,
+			Methods:        o.HostDiscovery.Methods,
+			TCPPorts:       o.HostDiscovery.TCPPorts,
+			UDPPorts:       o.HostDiscovery.UDPPorts,
+			Timeout:        o.HostDiscovery.Timeout,
+			Count:          o.HostDiscovery.Count,
+			Format:         o.HostDiscovery.Format,
+			RateLimit:      o.HostDiscovery.RateLimit,
+			Concurrency:    o.HostDiscovery.Concurrency,
+			ResultCallback: pingCallback,
+			ItemCallback:   rs.pingItemCallback(entryID),
+			EntryID:        entryID,
+			Silent:         true,
+			Directory:      e.dir,
+			StageManager:   stageManager,
+			Pause:          pauser,
 		})
 		if err != nil {
 			return nil, err
@@ -223,13 +339,90 @@ func (e *EagleeyeEngine) NewEntry(options *types.Options, extraOpts ...ExtraOpti
 		coreOptions = append(coreOptions, core.WithHostDiscoverer(hostDiscovery))
 	}
 
+	if o.Certificate.Use {
+		certCallback := func(ctx context.Context, cr *types.CertResult) error {
+			// 断点回放的结果不包含条目ID
+			cr.EntryID = entryID
+			if err := saveResult(cp, checkpoint.StageCertificate, cr); err != nil {
+				return err
+			}
+			entryResult.CertificateResult = cr
+			if o.Certificate.ResultCallback != nil {
+				return o.Certificate.ResultCallback(ctx, cr)
+			}
+			return nil
+		}
+		replays[checkpoint.StageCertificate], err = replayResult(cp, checkpoint.StageCertificate, certCallback)
+		if err != nil {
+			return nil, err
+		}
+
+		certCollector, err := scanner.NewCertCollector(&scanner.CertCollectorConfig{
+			Timeout:        o.Certificate.Timeout,
+			Count:          o.Certificate.Count,
+			Format:         o.Certificate.Format,
+			RateLimit:      o.Certificate.RateLimit,
+			Concurrency:    o.Certificate.Concurrency,
+			ResultCallback: certCallback,
+			EntryID:        entryID,
+			Silent:         true,
+			Directory:      e.dir,
+			StageManager:   stageManager,
+			Pause:          pauser,
+		})
+		if err != nil {
+			return nil, err
+		}
+		coreOptions = append(coreOptions, core.WithCertCollector(certCollector))
+	}
+
 	vm, err := vuln.New(o.Mapping.Vuln)
 	if err != nil {
 		return nil, err
 	}
 
+	if o.WebFingerprint.Use {
+		webCallback := func(ctx context.Context, wr *types.WebResult) error {
+			// 断点回放的结果不包含条目ID
+			wr.EntryID = entryID
+			if err := saveResult(cp, checkpoint.StageWebFingerprint, wr); err != nil {
+				return err
+			}
+			entryResult.WebFingerprintResult = wr
+			if o.WebFingerprint.ResultCallback != nil {
+				return o.WebFingerprint.ResultCallback(ctx, wr)
+			}
+			return nil
+		}
+		replays[checkpoint.StageWebFingerprint], err = replayResult(cp, checkpoint.StageWebFingerprint, webCallback)
+		if err != nil {
+			return nil, err
+		}
+
+		webFingerprinter, err := scanner.NewWebFingerprinter(&scanner.WebFingerprinterConfig{
+			Timeout:        o.WebFingerprint.Timeout,
+			Count:          o.WebFingerprint.Count,
+			Format:         o.WebFingerprint.Format,
+			RateLimit:      o.WebFingerprint.RateLimit,
+			Concurrency:    o.WebFingerprint.Concurrency,
+			Fingerprints:   o.WebFingerprint.Fingerprints,
+			VulnMapper:     vm,
+			ResultCallback: webCallback,
+			ItemCallback:   rs.findingCallback(entryID, types.StageWebFingerprint, ""),
+			EntryID:        entryID,
+			Silent:         true,
+			Directory:      e.dir,
+			StageManager:   stageManager,
+			Pause:          pauser,
+		})
+		if err != nil {
+			return nil, err
+		}
+		coreOptions = append(coreOptions, core.WithWebFingerprinter(webFingerprinter))
+	}
+
 	for i := range o.Jobs {
-		newJob, err := job.NewJob(
+		jobOptions := []job.Option{
 			job.WithIndex(i),
 			job.WithName(o.Jobs[i].Name),
 			job.WithKind(o.Jobs[i].Kind),
@@ -240,19 +433,29 @@ func (e *EagleeyeEngine) NewEntry(options *types.Options, extraOpts ...ExtraOpti
 			job.WithGetTemplates(o.Jobs[i].GetTemplates),
 			job.WithTimeout(o.Jobs[i].Timeout),
 			job.WithRetries(o.Jobs[i].Count),
-			job.WithCallback(func(ctx context.Context, jr *types.JobResult) error {
-				entryResult.JobResults = append(entryResult.JobResults, jr)
-				if o.Jobs[i].ResultCallback != nil {
-					return o.Jobs[i].ResultCallback(ctx, jr)
-				}
-				return nil
-			}),
+			job.WithItemCallback(rs.findingCallback(entryID, types.StageJob, o.Jobs[i].Name)),
 			job.WithEntryID(entryID),
 			job.WithSilent(true),
 			job.WithVulnMapper(vm),
+			job.WithInput(o.Jobs[i].Input),
+			job.WithChain(o.Jobs[i].Chain),
+			job.WithFilter(o.Jobs[i].Filter),
 			job.WithDirectory(e.dir),
 			job.WithStageManager(stageManager),
-		)
+			job.WithPause(pauser),
+		}
+		// 开启实时结果且未配置任务结果回调时不再累积命中结果(条目结果中不包含该任务)
+		if rs == nil || o.Jobs[i].ResultCallback != nil {
+			jobOptions = append(jobOptions, job.WithCallback(func(ctx context.Context, jr *types.JobResult) error {
+				entryResult.JobResults = append(entryResult.JobResults, jr)
+				if o.Jobs[i].ResultCallback != nil {
+					return o.Jobs[i].ResultCallback(ctx, jr)
+				}
+				return nil
+			}))
+		}
+
+		newJob, err := job.NewJob(jobOptions...)
 		if err != nil {
 			return nil, err
 		}
@@ -265,6 +468,7 @@ func (e *EagleeyeEngine) NewEntry(options *types.Options, extraOpts ...ExtraOpti
 	if err != nil {
 		return nil, err
 	}
+	entryResult.Seed = engine.Seed()
 
 	c, cancel := context.WithCancel(context.Background())
 	entry := &EagleeyeEntry{
@@ -276,6 +480,22 @@ func (e *EagleeyeEngine) NewEntry(options *types.Options, extraOpts ...ExtraOpti
 		state:        new(atomic.Uint32),
 		result:       entryResult,
 		stageManager: stageManager,
+		pauser:       pauser,
+		checkpoint:   cp,
+		stream:       rs,
+	}
+
+	// 按扫描阶段的执行顺序回放
+	for _, name := range []string{
+		checkpoint.StageDNSResolution,
+		checkpoint.StageHostDiscovery,
+		checkpoint.StagePortScanning,
+		checkpoint.StageCertificate,
+		checkpoint.StageWebFingerprint,
+	} {
+		if replay := replays[name]; replay != nil {
+			entry.replays = append(entry.replays, replay)
+		}
 	}
 
 	return entry, nil
@@ -285,6 +505,18 @@ func (entry *EagleeyeEntry) Stage() types.Stage {
 	return entry.stageManager.Get()
 }
 
+// Subscribe 订阅条目执行事件(阶段开始与完成、进度、警告、执行失败及命中结果)，buffer为通道缓冲大小
+//
+// 返回的函数取消订阅；条目执行结束后通道关闭。缓冲已满时丢弃事件，不影响扫描
+func (entry *EagleeyeEntry) Subscribe(buffer int) (<-chan types.Event, func()) {
+	return entry.stageManager.Subscribe(buffer)
+}
+
+// SubscribeBlocking 同Subscribe，但除进度与执行失败事件外，缓冲已满时扫描阻塞直到被读取，需持续读取
+func (entry *EagleeyeEntry) SubscribeBlocking(buffer int) (<-chan types.Event, func()) {
+	return entry.stageManager.SubscribeBlocking(buffer)
+}
+
 // Run 运行条目
 func (entry *EagleeyeEntry) Run(c context.Context) error {
 	// 如果状态交换失败，说明已经运行了
@@ -296,8 +528,18 @@ func (entry *EagleeyeEntry) Run(c context.Context) error {
 	runE := make(chan error)
 	go func() {
 		entry.result.StartTime = time.Now()
-		runE <- entry.core.ExecuteWithContext(entry.c)
+		entry.stageManager.Publish(types.Event{Type: types.EventEntryStarted})
+		err := entry.execute()
+		// 各阶段已结束，不再产生实时结果与事件
+		entry.stream.close()
+		finished := types.Event{Type: types.EventEntryFinished}
+		if err != nil {
+			finished.Message = err.Error()
+		}
+		entry.stageManager.Publish(finished)
+		entry.stageManager.Close()
 		entry.result.EndTime = time.Now()
+		runE <- err
 	}()
 
 	// 当entry运行后，添加entry到引擎上下文
@@ -326,8 +568,8 @@ func (entry *EagleeyeEntry) Run(c context.Context) error {
 		err = types.ErrHasBeenStopped
 	}
 
-	// 如果存在错误，则删除entry对应目录
-	if err != nil {
+	// 如果存在错误，则删除entry对应目录(开启断点续扫时保留，用于继续执行)
+	if err != nil && entry.checkpoint == nil {
 		defer entry.e.RemoveFiles(entry.EntryID)
 	}
 
@@ -335,6 +577,24 @@ func (entry *EagleeyeEntry) Run(c context.Context) error {
 	return err
 }
 
+// execute 回放已完成阶段的结果后执行
+func (entry *EagleeyeEntry) execute() error {
+	for _, replay := range entry.replays {
+		if err := replay(entry.c); err != nil {
+			return err
+		}
+	}
+	return entry.core.ExecuteWithContext(entry.c)
+}
+
+// Stream 获取实时结果通道(需以WithStream创建条目，否则为nil)，条目执行结束后关闭
+func (entry *EagleeyeEntry) Stream() <-chan *types.StreamItem {
+	if entry.stream == nil {
+		return nil
+	}
+	return entry.stream.ch
+}
+
 // Result 获取条目结果
 func (entry *EagleeyeEntry) Result() *types.EntryResult {
 	if entry.err != nil {
@@ -343,6 +603,11 @@ func (entry *EagleeyeEntry) Result() *types.EntryResult {
 	return entry.result
 }
 
+// Seed 获取扫描顺序随机种子
+func (entry *EagleeyeEntry) Seed() int64 {
+	return entry.core.Seed()
+}
+
 // Stop 外部停止
 func (entry *EagleeyeEntry) Stop() error {
 	if !entry.stop() {
@@ -352,16 +617,81 @@ func (entry *EagleeyeEntry) Stop() error {
 	return nil
 }
 
-// stop 内部停止
+// Pause 暂停运行中的条目，各阶段在提交下一个任务前等待，已提交的任务继续执行完成
+func (entry *EagleeyeEntry) Pause() error {
+	entry.pm.Lock()
+	defer entry.pm.Unlock()
+
+	if !entry.state.CompareAndSwap(running, paused) {
+		return types.ErrNotRunning
+	}
+	entry.pauser.Pause()
+	return nil
+}
+
+// Resume 继续已暂停的条目
+func (entry *EagleeyeEntry) Resume() error {
+	entry.pm.Lock()
+	defer entry.pm.Unlock()
+
+	if !entry.state.CompareAndSwap(paused, running) {
+		return types.ErrNotPaused
+	}
+	entry.pauser.Resume()
+	return nil
+}
+
+// Paused 条目是否已暂停
+func (entry *EagleeyeEntry) Paused() bool {
+	return entry.state.Load() == paused
+}
+
+// Adjust 调整运行中(含暂停)条目指定阶段的速率(每秒)与并发数，小于等于0的值不调整
+//
+// 阶段为types.StageJob时按任务序号(job)区分，流水线模式下同时执行的其他阶段不受影响
+func (entry *EagleeyeEntry) Adjust(stage types.StageName, job, rate, concurrency int) error {
+	if state := entry.state.Load(); state != running && state != paused {
+		return types.ErrStoppedOrNotRunning
+	}
+	return entry.core.Adjust(stage, job, rate, concurrency)
+}
+
+// stop 内部停止(暂停的条目直接停止，等待中的阶段随上下文取消返回)
 func (entry *EagleeyeEntry) stop() bool {
-	if !entry.state.CompareAndSwap(running, stopped) {
+	if !entry.state.CompareAndSwap(running, stopped) && !entry.state.CompareAndSwap(paused, stopped) {
 		return false
 	}
 
 	entry.cancel()
+	// 订阅者未读取时不阻塞停止
+	entry.stageManager.Stop()
 	return true
 }
 
 func NewID() string {
 	return xid.New().String()
 }
+
+// saveResult 记录阶段结果，用于继续执行时回放
+func saveResult(cp *checkpoint.Checkpoint, name string, result any) error {
+	if cp == nil {
+		return nil
+	}
+	return cp.SetResult(name, result)
+}
+
+// replayResult 由断点获取已完成阶段的结果，返回回放结果的函数(阶段未完成时为nil)
+func replayResult[T any](cp *checkpoint.Checkpoint, name string, callback func(context.Context, *T) error) (func(context.Context) error, error) {
+	if cp == nil || cp.Stage(name) == nil {
+		return nil, nil
+	}
+
+	result := new(T)
+	ok, err := cp.Result(name, result)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return func(c context.Context) error {
+		return callback(c, result)
+	}, nil
+}
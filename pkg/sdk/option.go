@@ -21,7 +21,11 @@ func WithDirectory(dir string) Option {
 }
 
 type extraOptions struct {
-	id string
+	id     string
+	resume bool
+
+	stream       bool
+	streamBuffer int
 }
 
 type ExtraOption interface {
@@ -39,3 +43,20 @@ func WithID(id string) ExtraOption {
 		e.id = id
 	})
 }
+
+// WithResume 由条目目录下的断点继续执行中断的条目(使用相同的条目ID，需与之前的目标一致)
+func WithResume(id string) ExtraOption {
+	return extraFn(func(e *extraOptions) {
+		e.id = id
+		e.resume = true
+	})
+}
+
+// WithStream 开启实时结果(存活主机、开放端口及命中结果)，通过EagleeyeEntry.Stream读取，
+// buffer为通道缓冲大小(小于等于0时使用默认值)，缓冲已满时扫描阻塞直到被读取
+func WithStream(buffer int) ExtraOption {
+	return extraFn(func(e *extraOptions) {
+		e.stream = true
+		e.streamBuffer = buffer
+	})
+}
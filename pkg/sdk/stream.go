@@ -0,0 +1,84 @@
+package eagleeye
+
+import (
+	"context"
+	"sync"
+
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+)
+
+// defaultStreamBuffer 实时结果通道默认缓冲大小
+const defaultStreamBuffer = 1024
+
+// stream 实时结果通道，缓冲已满时阻塞发送方(背压)，直到被读取或条目停止
+type stream struct {
+	m      sync.RWMutex
+	ch     chan *types.StreamItem
+	closed bool
+}
+
+func newStream(buffer int) *stream {
+	if buffer <= 0 {
+		buffer = defaultStreamBuffer
+	}
+	return &stream{ch: make(chan *types.StreamItem, buffer)}
+}
+
+// send 发送实时结果，上下文取消或通道已关闭时丢弃
+func (s *stream) send(c context.Context, item *types.StreamItem) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case <-c.Done():
+	case s.ch <- item:
+	}
+}
+
+// pingItemCallback 存活主机实时回调，未开启实时结果时为nil
+func (s *stream) pingItemCallback(entryID string) types.PingItemCallback {
+	if s == nil {
+		return nil
+	}
+	return func(c context.Context, item *types.PingResultItem) {
+		s.send(c, &types.StreamItem{EntryID: entryID, Kind: types.StreamHost, Stage: types.StageHostDiscovery, Host: item})
+	}
+}
+
+// portItemCallback 开放端口实时回调，未开启实时结果时为nil
+func (s *stream) portItemCallback(entryID string) types.PortItemCallback {
+	if s == nil {
+		return nil
+	}
+	return func(c context.Context, item *types.PortResultItem) {
+		s.send(c, &types.StreamItem{EntryID: entryID, Kind: types.StreamPort, Stage: types.StagePortScanning, Port: item})
+	}
+}
+
+// findingCallback Web指纹识别或任务命中结果实时回调，未开启实时结果时为nil
+func (s *stream) findingCallback(entryID string, stage types.StageName, job string) types.JobItemCallback {
+	if s == nil {
+		return nil
+	}
+	return func(c context.Context, item *types.JobResultItem) {
+		s.send(c, &types.StreamItem{EntryID: entryID, Kind: types.StreamFinding, Stage: stage, Job: job, Finding: item})
+	}
+}
+
+// close 关闭通道(条目执行结束时调用)
+func (s *stream) close() {
+	if s == nil {
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
@@ -0,0 +1,44 @@
+package eagleeye
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/EscapeBearSecond/falcon/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	assert := assert.New(t)
+
+	// 未开启时回调为nil
+	var rs *stream
+	assert.Nil(rs.pingItemCallback("entry"))
+	assert.Nil(rs.portItemCallback("entry"))
+	assert.Nil(rs.findingCallback("entry", types.StageJob, "job"))
+	rs.close()
+
+	rs = newStream(1)
+	rs.portItemCallback("entry")(context.Background(), &types.PortResultItem{HostPort: "127.0.0.1:80"})
+
+	// 缓冲已满时阻塞，直到上下文取消
+	c, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	rs.findingCallback("entry", types.StageJob, "job")(c, &types.JobResultItem{TemplateID: "t1"})
+
+	rs.close()
+	// 关闭后丢弃
+	rs.pingItemCallback("entry")(context.Background(), &types.PingResultItem{IP: "127.0.0.1"})
+
+	var items []*types.StreamItem
+	for item := range rs.ch {
+		items = append(items, item)
+	}
+	if assert.Len(items, 1) {
+		assert.Equal(types.StreamPort, items[0].Kind)
+		assert.Equal(types.StagePortScanning, items[0].Stage)
+		assert.Equal("entry", items[0].EntryID)
+		assert.Equal("127.0.0.1:80", items[0].Port.HostPort)
+	}
+}
@@ -3,14 +3,23 @@ package types
 import "errors"
 
 var (
-	ErrInvalidTargets   = errors.New("invalid or empty targets")
-	ErrInvalidTemplates = errors.New("invalid or empty templates")
-	ErrNoActiveHost     = errors.New("could not discovered active host")
-	ErrNoExistPort      = errors.New("could not scanned exist port")
+	ErrInvalidTargets     = errors.New("invalid or empty targets")
+	ErrInvalidTemplates   = errors.New("invalid or empty templates")
+	ErrNoResolvedHost     = errors.New("could not resolved any host")
+	ErrNoActiveHost       = errors.New("could not discovered active host")
+	ErrNoExistPort        = errors.New("could not scanned exist port")
+	ErrInvalidJobInput    = errors.New("invalid job input")
+	ErrInvalidInteractsh  = errors.New("invalid interactsh options")
+	ErrInvalidCheckpoint  = errors.New("invalid checkpoint options")
+	ErrCheckpointMismatch = errors.New("checkpoint does not match targets")
+	ErrInvalidAdjustment  = errors.New("invalid rate limit or concurrency")
 )
 
 var (
 	ErrHasBeenStopped          = errors.New("entry has been stopped")
 	ErrAlreadyRunningOrStopped = errors.New("entry already running or stopped")
 	ErrStoppedOrNotRunning     = errors.New("entry stopped or not running")
+	ErrNotRunning              = errors.New("entry not running")
+	ErrNotPaused               = errors.New("entry not paused")
+	ErrNoRunningStage          = errors.New("entry has no running stage")
 )
@@ -19,16 +19,49 @@ func DefaultOptions(jobSize ...int) *Options {
 			Count:       1,
 			Format:      "csv",
 			Ports:       "http",
+			Mode:        "connect",
 			RateLimit:   150,
 			Concurrency: 150,
 		},
 		HostDiscovery: HostDiscoveryOptions{
+			TCPPorts:    "21,22,23,25,80,135,139,443,445,3389,8080",
+			UDPPorts:    "53,123,137,161",
 			Timeout:     "1s",
 			Count:       1,
 			Format:      "csv",
 			RateLimit:   150,
 			Concurrency: 150,
 		},
+		DNSResolution: DNSResolutionOptions{
+			Timeout:     "3s",
+			Count:       1,
+			Format:      "csv",
+			RateLimit:   150,
+			Concurrency: 150,
+		},
+		Certificate: CertificateOptions{
+			Timeout:     "3s",
+			Count:       1,
+			Format:      "csv",
+			RateLimit:   150,
+			Concurrency: 150,
+		},
+		WebFingerprint: WebFingerprintOptions{
+			Timeout:     "5s",
+			Count:       1,
+			Format:      "csv",
+			RateLimit:   150,
+			Concurrency: 150,
+		},
+		Interactsh: InteractshOptions{
+			PollInterval: "5s",
+			Cooldown:     "5s",
+			Eviction:     "60s",
+			Listen:       ":8085",
+		},
+		Checkpoint: CheckpointOptions{
+			Interval: "10s",
+		},
 	}
 	if len(jobSize) != 0 && jobSize[0] != 0 {
 		for range jobSize[0] {
@@ -46,14 +79,21 @@ func DefaultOptions(jobSize ...int) *Options {
 
 // Options 选项
 type Options struct {
-	Targets        []string             `yaml:"targets" json:"targets"`                 //目标
-	ExcludeTargets []string             `yaml:"exclude_targets" json:"exclude_targets"` //排除目标
-	OutLog         bool                 `yaml:"out_log" json:"-"`                       //输出运行日志
-	Monitor        MonitorOptions       `yaml:"monitor" json:"-"`                       //监控
-	Mapping        Mapping              `yaml:"mapping" json:"mapping"`                 //映射
-	PortScanning   PortScanningOptions  `yaml:"port_scanning" json:"port_scanning"`     //端口扫描
-	HostDiscovery  HostDiscoveryOptions `yaml:"host_discovery" json:"host_discovery"`   //在线检测
-	Jobs           []JobOptions         `yaml:"jobs" json:"jobs"`                       //任务
+	Targets        []string              `yaml:"targets" json:"targets"`                 //目标
+	ExcludeTargets []string              `yaml:"exclude_targets" json:"exclude_targets"` //排除目标
+	Seed           int64                 `yaml:"seed" json:"seed"`                       //扫描顺序随机种子(0则随机生成)
+	Pipeline       bool                  `yaml:"pipeline" json:"pipeline"`               //流水线模式(在线检测、端口扫描与任务同时执行)
+	OutLog         bool                  `yaml:"out_log" json:"-"`                       //输出运行日志
+	Monitor        MonitorOptions        `yaml:"monitor" json:"-"`                       //监控
+	Mapping        Mapping               `yaml:"mapping" json:"mapping"`                 //映射
+	PortScanning   PortScanningOptions   `yaml:"port_scanning" json:"port_scanning"`     //端口扫描
+	DNSResolution  DNSResolutionOptions  `yaml:"dns_resolution" json:"dns_resolution"`   //域名解析
+	HostDiscovery  HostDiscoveryOptions  `yaml:"host_discovery" json:"host_discovery"`   //在线检测
+	Certificate    CertificateOptions    `yaml:"certificate" json:"certificate"`         //TLS证书采集
+	WebFingerprint WebFingerprintOptions `yaml:"web_fingerprint" json:"web_fingerprint"` //Web指纹识别
+	Interactsh     InteractshOptions     `yaml:"interactsh" json:"interactsh"`           //带外交互(OOB)
+	Checkpoint     CheckpointOptions     `yaml:"checkpoint" json:"checkpoint"`           //断点续扫
+	Jobs           []JobOptions          `yaml:"jobs" json:"jobs"`                       //任务
 }
 
 type Mapping struct {
@@ -62,17 +102,88 @@ type Mapping struct {
 
 // JobOptions 任务选项
 type JobOptions struct {
-	Name           string            `yaml:"name" json:"name"`               //任务名称
-	Kind           string            `yaml:"kind" json:"kind"`               //任务类型(由于name可能是中文，kind只作为英文比较字段)
-	Template       string            `yaml:"template" json:"template"`       //模板，支持单文件和目录
-	GetTemplates   GetTemplates      `yaml:"-" json:"-"`                     //获取模板
-	Format         string            `yaml:"format" json:"format"`           //输出格式
-	Count          int               `yaml:"count" json:"count"`             //循环次数
-	Timeout        string            `yaml:"timeout" json:"timeout"`         //超时时间
-	RateLimit      int               `yaml:"rate_limit" json:"rate_limit"`   //限流
-	Concurrency    int               `yaml:"concurrency" json:"concurrency"` //并发数
-	Headless       bool              `yaml:"headless" json:"headless"`       //是否启用浏览器
-	ResultCallback JobResultCallback `yaml:"-" json:"-"`                     //结果回调
+	Name           string                `yaml:"name" json:"name"`               //任务名称
+	Kind           string                `yaml:"kind" json:"kind"`               //任务类型(由于name可能是中文，kind只作为英文比较字段)
+	Template       string                `yaml:"template" json:"template"`       //模板，支持单文件和目录
+	GetTemplates   GetTemplates          `yaml:"-" json:"-"`                     //获取模板
+	Format         string                `yaml:"format" json:"format"`           //输出格式
+	Count          int                   `yaml:"count" json:"count"`             //循环次数
+	Timeout        string                `yaml:"timeout" json:"timeout"`         //超时时间
+	RateLimit      int                   `yaml:"rate_limit" json:"rate_limit"`   //限流
+	Concurrency    int                   `yaml:"concurrency" json:"concurrency"` //并发数
+	Headless       bool                  `yaml:"headless" json:"headless"`       //是否启用浏览器
+	Input          JobInputOptions       `yaml:"input" json:"input"`             //任务输入
+	Chain          JobChainOptions       `yaml:"chain" json:"chain"`             //任务链(按指纹任务的结果选择模板)
+	Filter         TemplateFilterOptions `yaml:"filter" json:"filter"`           //模板过滤
+	ResultCallback JobResultCallback     `yaml:"-" json:"-"`                     //结果回调
+}
+
+// 任务输入来源
+const (
+	JobInputTargets = "targets" // 原始目标(域名解析后)
+	JobInputHosts   = "hosts"   // 在线检测的存活主机
+	JobInputPorts   = "ports"   // 端口扫描的开放端口
+	JobInputJob     = "job"     // 其他任务命中的目标
+)
+
+// TemplateFilterOptions 模板过滤选项(加载模板时生效)
+//
+// 各包含条件为空表示不限制，非空时需命中其一；排除条件优先于包含条件
+type TemplateFilterOptions struct {
+	Tags              []string `yaml:"tags" json:"tags"`                             //包含标签
+	ExcludeTags       []string `yaml:"exclude_tags" json:"exclude_tags"`             //排除标签
+	Severities        []string `yaml:"severities" json:"severities"`                 //包含严重程度(info,low,medium,high,critical,unknown)
+	ExcludeSeverities []string `yaml:"exclude_severities" json:"exclude_severities"` //排除严重程度
+	IDs               []string `yaml:"ids" json:"ids"`                               //包含模板ID
+	ExcludeIDs        []string `yaml:"exclude_ids" json:"exclude_ids"`               //排除模板ID
+	Authors           []string `yaml:"authors" json:"authors"`                       //包含作者
+	ExcludeAuthors    []string `yaml:"exclude_authors" json:"exclude_authors"`       //排除作者
+	Protocols         []string `yaml:"protocols" json:"protocols"`                   //包含协议类型(http,tcp,dns,ssl,javascript等)
+	ExcludeProtocols  []string `yaml:"exclude_protocols" json:"exclude_protocols"`   //排除协议类型
+	Paths             []string `yaml:"paths" json:"paths"`                           //包含路径(相对模板目录的glob，匹配文件或其所在目录)
+	ExcludePaths      []string `yaml:"exclude_paths" json:"exclude_paths"`           //排除路径
+}
+
+// JobChainOptions 任务链选项
+//
+// 按指纹任务在每个主机上识别出的产品(模板标签及产品名称)，仅执行标签与之匹配的模板
+type JobChainOptions struct {
+	Job      string     `yaml:"job" json:"job"`           //指纹任务名称(同一计划中先执行)
+	Result   *JobResult `yaml:"-" json:"-"`               //指纹任务结果(sdk模式，如之前执行的结果)
+	Fallback bool       `yaml:"fallback" json:"fallback"` //未识别出指纹的主机执行全部模板
+}
+
+// JobInputOptions 任务输入选项
+type JobInputOptions struct {
+	From      string   `yaml:"from" json:"from"`           //输入来源(targets,hosts,ports,job)，为空时使用最后一个扫描阶段的结果
+	Job       string   `yaml:"job" json:"job"`             //来源任务名称(from为job时)
+	Tags      []string `yaml:"tags" json:"tags"`           //按模板标签过滤来源任务命中的目标(命中任一)
+	Templates []string `yaml:"templates" json:"templates"` //按模板ID过滤来源任务命中的目标(命中任一)
+}
+
+// InteractshOptions 带外交互(OOB)选项，用于检测盲SSRF/RCE等需要目标回连的漏洞
+//
+// 仅使用自建的交互服务(兼容interactsh协议)，未指定服务地址时启动内置服务；
+// 未开启时跳过包含{{interactsh-url}}的模板
+type InteractshOptions struct {
+	Use          bool   `yaml:"use" json:"use"`                     //开启带外交互
+	Server       string `yaml:"server" json:"server"`               //交互服务地址(http(s)://host[:port]，多个以逗号分隔)，为空时启动内置服务
+	Token        string `yaml:"token" json:"token"`                 //交互服务认证令牌
+	PollInterval string `yaml:"poll_interval" json:"poll_interval"` //轮询间隔(5s)
+	Cooldown     string `yaml:"cooldown" json:"cooldown"`           //任务结束后等待交互的时间(5s)
+	Eviction     string `yaml:"eviction" json:"eviction"`           //请求等待交互的最长时间(60s)
+	Listen       string `yaml:"listen" json:"listen"`               //内置服务HTTP监听地址
+	DNSListen    string `yaml:"dns_listen" json:"dns_listen"`       //内置服务DNS监听地址(UDP)，为空不启动
+	Domain       string `yaml:"domain" json:"domain"`               //内置服务交互域名(子域名需泛解析到本服务，未带端口时使用HTTP监听端口，如oob.example.com或oob.example.com:8085)
+	IP           string `yaml:"ip" json:"ip"`                       //内置服务DNS应答地址
+}
+
+// CheckpointOptions 断点续扫选项
+//
+// 定期将执行进度保存至条目目录下的断点文件，进程中断后可由相同ID的条目继续执行
+type CheckpointOptions struct {
+	Use      bool   `yaml:"use" json:"use"`           //开启断点续扫
+	Interval string `yaml:"interval" json:"interval"` //保存间隔(10s)
 }
 
 // MonitorOptions 监控选项(sdk模式不生效)
@@ -84,25 +195,69 @@ type MonitorOptions struct {
 
 // PortScanningOptions 端口扫描选项
 type PortScanningOptions struct {
-	Use            bool               `yaml:"use" json:"use"`                 //开启端口扫描
+	Use              bool               `yaml:"use" json:"use"`                             //开启端口扫描
+	Timeout          string             `yaml:"timeout" json:"timeout"`                     //超时时间(0.5s, 1m)
+	Count            int                `yaml:"count" json:"count"`                         //轮次
+	Format           string             `yaml:"format" json:"format"`                       //导出结果格式(csv,excel)
+	Ports            string             `yaml:"ports" json:"ports"`                         //扫描端口(命名集合、服务名称、nmap风格端口列表)
+	ExcludePorts     string             `yaml:"exclude_ports" json:"exclude_ports"`         //排除端口(格式同ports)
+	PortSets         string             `yaml:"port_sets" json:"port_sets"`                 //自定义命名端口集合文件
+	Mode             string             `yaml:"mode" json:"mode"`                           //扫描方式(connect,syn)，syn需要root/CAP_NET_RAW，不满足时回退connect
+	ServiceDetection bool               `yaml:"service_detection" json:"service_detection"` //开放端口服务识别(banner/TLS/HTTP)
+	RateLimit        int                `yaml:"rate_limit" json:"rate_limit"`               //限流
+	HostRateLimit    int                `yaml:"host_rate_limit" json:"host_rate_limit"`     //单主机最大速率(包/秒)，0为不限制
+	Adaptive         bool               `yaml:"adaptive" json:"adaptive"`                   //按主机RTT与丢包自适应调整速率与超时
+	Concurrency      int                `yaml:"concurrency" json:"concurrency"`             //并发数
+	ResultCallback   PortResultCallback `yaml:"-" json:"-"`                                 //结果回调
+}
+
+// HostDiscoveryOptions 在线检测选项
+type HostDiscoveryOptions struct {
+	Use            bool               `yaml:"use" json:"use"`                 //开启设备发现(探活)
+	Methods        []string           `yaml:"methods" json:"methods"`         //探测方式(icmp,tcp,syn,arp,udp)，按顺序尝试，为空时使用icmp
+	TCPPorts       string             `yaml:"tcp_ports" json:"tcp_ports"`     //TCP探测端口
+	UDPPorts       string             `yaml:"udp_ports" json:"udp_ports"`     //UDP探测端口
 	Timeout        string             `yaml:"timeout" json:"timeout"`         //超时时间(0.5s, 1m)
 	Count          int                `yaml:"count" json:"count"`             //轮次
 	Format         string             `yaml:"format" json:"format"`           //导出结果格式(csv,excel)
-	Ports          string             `yaml:"ports" json:"ports"`             //扫描端口
 	RateLimit      int                `yaml:"rate_limit" json:"rate_limit"`   //限流
 	Concurrency    int                `yaml:"concurrency" json:"concurrency"` //并发数
-	ResultCallback PortResultCallback `yaml:"-" json:"-"`                     //结果回调
+	ResultCallback PingResultCallback `yaml:"-" json:"-"`                     //结果回调
 }
 
-// HostDiscoveryOptions 在线检测选项
-type HostDiscoveryOptions struct {
-	Use            bool               `yaml:"use" json:"use"`                 //开启设备发现(探活)
+// DNSResolutionOptions 域名解析选项
+type DNSResolutionOptions struct {
+	Use            bool              `yaml:"use" json:"use"`                 //开启域名解析
+	Resolvers      []string          `yaml:"resolvers" json:"resolvers"`     //DNS服务器(ip[:port])，为空时使用系统配置
+	Timeout        string            `yaml:"timeout" json:"timeout"`         //超时时间(0.5s, 1m)
+	Count          int               `yaml:"count" json:"count"`             //轮次
+	Format         string            `yaml:"format" json:"format"`           //导出结果格式(csv,excel)
+	RateLimit      int               `yaml:"rate_limit" json:"rate_limit"`   //限流
+	Concurrency    int               `yaml:"concurrency" json:"concurrency"` //并发数
+	ResultCallback DNSResultCallback `yaml:"-" json:"-"`                     //结果回调
+}
+
+// CertificateOptions TLS证书采集选项
+type CertificateOptions struct {
+	Use            bool               `yaml:"use" json:"use"`                 //开启证书采集
 	Timeout        string             `yaml:"timeout" json:"timeout"`         //超时时间(0.5s, 1m)
 	Count          int                `yaml:"count" json:"count"`             //轮次
 	Format         string             `yaml:"format" json:"format"`           //导出结果格式(csv,excel)
 	RateLimit      int                `yaml:"rate_limit" json:"rate_limit"`   //限流
 	Concurrency    int                `yaml:"concurrency" json:"concurrency"` //并发数
-	ResultCallback PingResultCallback `yaml:"-" json:"-"`                     //结果回调
+	ResultCallback CertResultCallback `yaml:"-" json:"-"`                     //结果回调
+}
+
+// WebFingerprintOptions Web指纹识别选项
+type WebFingerprintOptions struct {
+	Use            bool              `yaml:"use" json:"use"`                   //开启Web指纹识别
+	Fingerprints   string            `yaml:"fingerprints" json:"fingerprints"` //指纹库文件
+	Timeout        string            `yaml:"timeout" json:"timeout"`           //超时时间(0.5s, 1m)
+	Count          int               `yaml:"count" json:"count"`               //轮次
+	Format         string            `yaml:"format" json:"format"`             //导出结果格式(csv,excel)
+	RateLimit      int               `yaml:"rate_limit" json:"rate_limit"`     //限流
+	Concurrency    int               `yaml:"concurrency" json:"concurrency"`   //并发数
+	ResultCallback WebResultCallback `yaml:"-" json:"-"`                       //结果回调
 }
 
 // Parse 解析配置
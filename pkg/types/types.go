@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"io"
+	"net"
 	"strings"
 	"time"
 
@@ -50,7 +51,7 @@ func (jr *JobResultItem) Fill(event *output.ResultEvent) *JobResultItem {
 	jr.TemplateName = event.Info.Name
 	jr.Type = event.Type
 	jr.Severity = event.Info.SeverityHolder.Severity.String()
-	jr.Host = lo.If(event.IP != "", event.IP).Else(strings.Split(event.Host, ":")[0])
+	jr.Host = lo.If(event.IP != "", event.IP).Else(hostOf(event.Host))
 	jr.Port = event.Port
 	jr.Scheme = event.Scheme
 	jr.URL = event.URL
@@ -65,6 +66,14 @@ func (jr *JobResultItem) Fill(event *output.ResultEvent) *JobResultItem {
 	return jr
 }
 
+// hostOf 获取host:port或[ipv6]:port中的host
+func hostOf(hostPort string) string {
+	if host, _, err := net.SplitHostPort(hostPort); err == nil {
+		return host
+	}
+	return strings.Trim(hostPort, "[]")
+}
+
 type PortResult struct {
 	EntryID string            `json:"-"`
 	Items   []*PortResultItem `json:"items"`
@@ -75,6 +84,12 @@ type PortResultItem struct {
 	IP       string `json:"ip"`
 	Port     int    `json:"port"`
 	HostPort string `json:"host_port"`
+	Protocol string `json:"protocol"` //tcp/udp
+	Service  string `json:"service"`  //服务(http,ssh,ssl等，开启服务识别时)
+	Product  string `json:"product"`  //产品
+	Version  string `json:"version"`  //版本
+	TLS      bool   `json:"tls"`      //是否为TLS
+	Banner   string `json:"banner"`   //banner/响应首行
 }
 
 type PingResult struct {
@@ -83,33 +98,141 @@ type PingResult struct {
 }
 
 type PingResultItem struct {
-	EntryID string `json:"-"`
-	IP      string `json:"ip"`
-	OS      string `json:"os"`
-	TTL     int    `json:"ttl"`
-	Active  bool   `json:"active"`
+	EntryID    string  `json:"-"`
+	IP         string  `json:"ip"`
+	OS         string  `json:"os"`
+	TTL        int     `json:"ttl"`
+	Active     bool    `json:"active"`
+	Method     string  `json:"method"`      //判定存活的探测方式(icmp,tcp,syn,arp,udp)
+	InitialTTL int     `json:"initial_ttl"` //估算的初始TTL(32,64,128,255)
+	MinRTT     float64 `json:"min_rtt"`     //最小往返时延(ms)
+	AvgRTT     float64 `json:"avg_rtt"`     //平均往返时延(ms)
+	Loss       float64 `json:"loss"`        //丢包率(%)
+}
+
+type DNSResult struct {
+	EntryID string           `json:"-"`
+	Items   []*DNSResultItem `json:"items"`
+}
+
+type DNSResultItem struct {
+	EntryID  string   `json:"-"`
+	Domain   string   `json:"domain"`
+	IPs      []string `json:"ips"`
+	Resolved bool     `json:"resolved"`
+}
+
+type CertResult struct {
+	EntryID string            `json:"-"`
+	Items   []*CertResultItem `json:"items"`
+}
+
+type CertResultItem struct {
+	EntryID            string    `json:"-"`
+	IP                 string    `json:"ip"`
+	Port               int       `json:"port"`
+	HostPort           string    `json:"host_port"`
+	ServerName         string    `json:"server_name"`         //握手使用的SNI
+	Subject            string    `json:"subject"`             //主题
+	SANs               []string  `json:"sans"`                //备用名称(DNS/IP)
+	Issuer             string    `json:"issuer"`              //颁发者
+	NotBefore          time.Time `json:"not_before"`          //生效时间
+	NotAfter           time.Time `json:"not_after"`           //过期时间
+	Expired            bool      `json:"expired"`             //是否已过期
+	KeyAlgorithm       string    `json:"key_algorithm"`       //公钥算法
+	KeySize            int       `json:"key_size"`            //公钥长度
+	WeakKey            bool      `json:"weak_key"`            //弱密钥(RSA<2048，ECDSA<224)
+	SignatureAlgorithm string    `json:"signature_algorithm"` //签名算法
+	WeakSignature      bool      `json:"weak_signature"`      //弱签名算法(MD2/MD5/SHA1)
+	SelfSigned         bool      `json:"self_signed"`         //自签名
+	TLSVersion         string    `json:"tls_version"`         //协商的TLS版本
+	Fingerprint        string    `json:"fingerprint"`         //SHA256指纹
+}
+
+type WebResult struct {
+	EntryID  string           `json:"-"`
+	Items    []*WebResultItem `json:"items"`
+	Findings []*JobResultItem `json:"findings"` //命中的指纹及按版本映射的漏洞
+}
+
+type WebResultItem struct {
+	EntryID         string   `json:"-"`
+	IP              string   `json:"ip"`
+	Port            int      `json:"port"`
+	HostPort        string   `json:"host_port"`
+	URL             string   `json:"url"`             //请求地址
+	StatusCode      int      `json:"status_code"`     //最终响应状态码
+	Title           string   `json:"title"`           //标题
+	Server          string   `json:"server"`          //Server头
+	Redirects       []string `json:"redirects"`       //跳转链
+	FaviconHash     *int32   `json:"favicon_hash"`    //favicon mmh3 hash(未获取到为nil)
+	Fingerprints    []string `json:"fingerprints"`    //命中的指纹(产品/版本)
+	Vulnerabilities []string `json:"vulnerabilities"` //按版本映射的漏洞
 }
 
 type EntryResult struct {
-	EntryID             string       `json:"-"`
-	HostDiscoveryResult *PingResult  `json:"host_discovery_result"`
-	PortScanningResult  *PortResult  `json:"plan_scanning_result"`
-	JobResults          []*JobResult `json:"job_results"`
-	Targets             []string     `json:"-"`
-	ExcludeTargets      []string     `json:"-"`
-	StartTime           time.Time    `json:"-"`
-	EndTime             time.Time    `json:"-"`
+	EntryID              string       `json:"-"`
+	DNSResolutionResult  *DNSResult   `json:"dns_resolution_result"`
+	HostDiscoveryResult  *PingResult  `json:"host_discovery_result"`
+	PortScanningResult   *PortResult  `json:"plan_scanning_result"`
+	CertificateResult    *CertResult  `json:"certificate_result"`
+	WebFingerprintResult *WebResult   `json:"web_fingerprint_result"`
+	JobResults           []*JobResult `json:"job_results"`
+	Seed                 int64        `json:"seed"` //扫描顺序随机种子(用于复现)
+	Targets              []string     `json:"-"`
+	ExcludeTargets       []string     `json:"-"`
+	StartTime            time.Time    `json:"-"`
+	EndTime              time.Time    `json:"-"`
 }
 
+// DNSResultCallback 域名解析结果回调
+type DNSResultCallback func(context.Context, *DNSResult) error
+
 // PingResultCallback ping结果回调
 type PingResultCallback func(context.Context, *PingResult) error
 
 // PortResultCallback port结果回调
 type PortResultCallback func(context.Context, *PortResult) error
 
+// CertResultCallback 证书采集结果回调
+type CertResultCallback func(context.Context, *CertResult) error
+
+// WebResultCallback Web指纹识别结果回调
+type WebResultCallback func(context.Context, *WebResult) error
+
 // JobResultCallback job结果回调
 type JobResultCallback func(context.Context, *JobResult) error
 
+// PingItemCallback 存活主机实时回调
+type PingItemCallback func(context.Context, *PingResultItem)
+
+// PortItemCallback 开放端口实时回调
+type PortItemCallback func(context.Context, *PortResultItem)
+
+// JobItemCallback 命中结果实时回调
+type JobItemCallback func(context.Context, *JobResultItem)
+
+// StreamKind 实时结果类型
+type StreamKind string
+
+const (
+	StreamHost    StreamKind = "host"    // 存活主机
+	StreamPort    StreamKind = "port"    // 开放端口
+	StreamFinding StreamKind = "finding" // 任务或Web指纹命中的结果
+)
+
+// StreamItem 实时结果，按类型对应Host、Port或Finding之一
+type StreamItem struct {
+	EntryID string
+	Kind    StreamKind
+	Stage   StageName
+	Job     string // 任务名称(任务命中的结果)
+
+	Host    *PingResultItem
+	Port    *PortResultItem
+	Finding *JobResultItem
+}
+
 // RawTemplate 原始模板
 type RawTemplate struct {
 	ID       string // 模板ID
@@ -129,18 +252,47 @@ type StageName string
 type StageEntryName string
 
 const (
-	StagePreExecute    StageName = "PreExecute"
-	StageHostDiscovery StageName = "HostDiscovery"
-	StagePortScanning  StageName = "PortScanning"
-	StageJob           StageName = "Job"
-	StagePostExecute   StageName = "PostExecute"
+	StagePreExecute     StageName = "PreExecute"
+	StageDNSResolution  StageName = "DNSResolution"
+	StageHostDiscovery  StageName = "HostDiscovery"
+	StagePortScanning   StageName = "PortScanning"
+	StageCertificate    StageName = "Certificate"
+	StageWebFingerprint StageName = "WebFingerprint"
+	StageJob            StageName = "Job"
+	StagePostExecute    StageName = "PostExecute"
 )
 
 const (
 	StageEntryJobKind  StageEntryName = "Kind"
 	StageEntryJobIndex StageEntryName = "Index"
+	StageEntryJobName  StageEntryName = "Name"
+)
+
+// EventType 事件类型
+type EventType string
+
+const (
+	EventEntryStarted  EventType = "entry_started"  // 条目开始执行
+	EventEntryFinished EventType = "entry_finished" // 条目执行结束(Message为错误信息)
+	EventStageStarted  EventType = "stage_started"  // 阶段开始
+	EventStageFinished EventType = "stage_finished" // 阶段完成(中断的阶段不发布)
+	EventProgress      EventType = "progress"       // 阶段进度(每增加1%发布一次，阻塞订阅缓冲已满时同样丢弃)
+	EventWarning       EventType = "warning"        // 警告(如跳过headless模板)
+	EventError         EventType = "error"          // 任务执行失败(阻塞订阅缓冲已满时同样丢弃)
+	EventFinding       EventType = "finding"        // 命中结果
 )
 
+// Event 条目执行事件
+type Event struct {
+	Type    EventType              `json:"type"`
+	Stage   StageName              `json:"stage"`
+	Percent float64                `json:"percent"`
+	Entries map[StageEntryName]any `json:"entries,omitempty"` //阶段信息(任务类型、序号)
+	Message string                 `json:"message,omitempty"`
+	Finding *JobResultItem         `json:"finding,omitempty"`
+	Time    time.Time              `json:"time"`
+}
+
 type ResultReader struct {
 	Format string
 	Stage  StageName
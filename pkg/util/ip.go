@@ -10,6 +10,10 @@ func IsIPv4(str string) bool {
 	return util.IsIPv4(str)
 }
 
+func IsIPv6(str string) bool {
+	return util.IsIPv6(str)
+}
+
 func IsPort(str string) bool {
 	return util.IsPort(str)
 }
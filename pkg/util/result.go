@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/EscapeBearSecond/falcon/internal/util"
 	"github.com/EscapeBearSecond/falcon/pkg/types"
@@ -33,6 +34,12 @@ func ReloadResult(readers ...*types.ResultReader) (*types.EntryResult, error) {
 		}
 
 		switch reader.Stage {
+		case types.StageDNSResolution:
+			dr, err := reloadDNSResolution(reader)
+			if err != nil {
+				return nil, fmt.Errorf("reload dns resolution result failed: %w", err)
+			}
+			result.DNSResolutionResult = dr
 		case types.StageHostDiscovery:
 			pr, err := reloadHostDiscovery(reader)
 			if err != nil {
@@ -45,6 +52,12 @@ func ReloadResult(readers ...*types.ResultReader) (*types.EntryResult, error) {
 				return nil, fmt.Errorf("reload port scanning result failed: %w", err)
 			}
 			result.PortScanningResult = pr
+		case types.StageCertificate:
+			cr, err := reloadCertificate(reader)
+			if err != nil {
+				return nil, fmt.Errorf("reload certificate result failed: %w", err)
+			}
+			result.CertificateResult = cr
 		case types.StageJob:
 			jr, err := reloadJob(reader)
 			if err != nil {
@@ -57,6 +70,55 @@ func ReloadResult(readers ...*types.ResultReader) (*types.EntryResult, error) {
 	return result, nil
 }
 
+func reloadDNSResolution(reader *types.ResultReader) (*types.DNSResult, error) {
+	var contents [][]string
+	var err error
+
+	switch reader.Format {
+	case "csv":
+		csvReader := csv.NewReader(reader.Reader)
+		contents, err = csvReader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("read csv failed: %w", err)
+		}
+	case "excel":
+		excelReader, err := excelize.OpenReader(reader.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("open excel reader failed: %w", err)
+		}
+		defer excelReader.Close()
+
+		contents, err = util.ReadXlsxAll(excelReader)
+		if err != nil {
+			return nil, fmt.Errorf("read excel failed: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", reader.Format)
+	}
+
+	dr := &types.DNSResult{
+		Items: make([]*types.DNSResultItem, 0, len(contents)-1),
+	}
+	items := make(map[string]*types.DNSResultItem)
+	for i, line := range contents {
+		if i == 0 {
+			continue
+		}
+		item, ok := items[line[0]]
+		if !ok {
+			item = &types.DNSResultItem{Domain: line[0], IPs: []string{}}
+			items[line[0]] = item
+			dr.Items = append(dr.Items, item)
+		}
+		if len(line) > 1 && line[1] != "" {
+			item.IPs = append(item.IPs, line[1])
+			item.Resolved = true
+		}
+	}
+
+	return dr, nil
+}
+
 func reloadHostDiscovery(reader *types.ResultReader) (*types.PingResult, error) {
 	var contents [][]string
 	var err error
@@ -90,11 +152,23 @@ func reloadHostDiscovery(reader *types.ResultReader) (*types.PingResult, error)
 		if i == 0 {
 			continue
 		}
+		// 兼容旧版本导出的列数
+		column := func(i int) string {
+			if line[1] != "是" || len(line) <= i {
+				return ""
+			}
+			return line[i]
+		}
 		pr.Items = append(pr.Items, &types.PingResultItem{
-			IP:     line[0],
-			Active: line[1] == "是",
-			OS:     lo.IfF(line[1] == "是", func() string { return line[2] }).Else(""),
-			TTL:    lo.IfF(line[1] == "是", func() int { return cast.ToInt(line[3]) }).Else(0),
+			IP:         line[0],
+			Active:     line[1] == "是",
+			OS:         column(2),
+			TTL:        cast.ToInt(column(3)),
+			Method:     column(4),
+			InitialTTL: cast.ToInt(column(5)),
+			MinRTT:     cast.ToFloat64(column(6)),
+			AvgRTT:     cast.ToFloat64(column(7)),
+			Loss:       cast.ToFloat64(column(8)),
 		})
 	}
 
@@ -135,16 +209,100 @@ func reloadPortScanning(reader *types.ResultReader) (*types.PortResult, error) {
 		if i == 0 {
 			continue
 		}
+		// 兼容旧版本导出的列数
+		column := func(i int) string {
+			if len(line) <= i {
+				return ""
+			}
+			return line[i]
+		}
+		// 旧版本导出不含协议列，均为TCP
+		protocol := column(2)
+		if protocol == "" {
+			protocol = "tcp"
+		}
 		pr.Items = append(pr.Items, &types.PortResultItem{
 			IP:       line[0],
 			Port:     cast.ToInt(line[1]),
 			HostPort: net.JoinHostPort(line[0], line[1]),
+			Protocol: protocol,
+			Service:  column(3),
+			Product:  column(4),
+			Version:  column(5),
+			TLS:      column(6) == "是",
+			Banner:   column(7),
 		})
 	}
 
 	return pr, nil
 }
 
+func reloadCertificate(reader *types.ResultReader) (*types.CertResult, error) {
+	var contents [][]string
+	var err error
+
+	switch reader.Format {
+	case "csv":
+		csvReader := csv.NewReader(reader.Reader)
+		contents, err = csvReader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("read csv failed: %w", err)
+		}
+	case "excel":
+		excelReader, err := excelize.OpenReader(reader.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("open excel reader failed: %w", err)
+		}
+		defer excelReader.Close()
+
+		contents, err = util.ReadXlsxAll(excelReader)
+		if err != nil {
+			return nil, fmt.Errorf("read excel failed: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", reader.Format)
+	}
+
+	cr := &types.CertResult{
+		Items: make([]*types.CertResultItem, 0, len(contents)-1),
+	}
+	for i, line := range contents {
+		if i == 0 {
+			continue
+		}
+		column := func(i int) string {
+			if len(line) <= i {
+				return ""
+			}
+			return line[i]
+		}
+		notBefore, _ := time.ParseInLocation(time.DateTime, column(6), time.Local)
+		notAfter, _ := time.ParseInLocation(time.DateTime, column(7), time.Local)
+		cr.Items = append(cr.Items, &types.CertResultItem{
+			IP:                 line[0],
+			Port:               cast.ToInt(column(1)),
+			HostPort:           net.JoinHostPort(line[0], column(1)),
+			ServerName:         column(2),
+			Subject:            column(3),
+			SANs:               lo.Compact(strings.Split(column(4), ",")),
+			Issuer:             column(5),
+			NotBefore:          notBefore,
+			NotAfter:           notAfter,
+			Expired:            column(8) == "是",
+			KeyAlgorithm:       column(9),
+			KeySize:            cast.ToInt(column(10)),
+			WeakKey:            column(11) == "是",
+			SignatureAlgorithm: column(12),
+			WeakSignature:      column(13) == "是",
+			SelfSigned:         column(14) == "是",
+			TLSVersion:         column(15),
+			Fingerprint:        column(16),
+		})
+	}
+
+	return cr, nil
+}
+
 func reloadJob(reader *types.ResultReader) (*types.JobResult, error) {
 
 	var contents [][]string
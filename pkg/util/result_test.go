@@ -28,6 +28,8 @@ func TestReloadResultFromReader(t *testing.T) {
 	f2.SetSheetRow("Sheet1", "A1", &[]string{"主机", "端口"})
 	f2.SetSheetRow("Sheet1", "A2", &[]string{"1.1.1.1", "22"})
 	f2.SetSheetRow("Sheet1", "A3", &[]string{"1.1.1.1", "23"})
+	f2.SetSheetRow("Sheet1", "A4", &[]string{"1.1.1.1", "161", "udp"})
+	f2.SetSheetRow("Sheet1", "A5", &[]string{"1.1.1.1", "8443", "tcp", "http", "nginx", "1.18.0", "是", "HTTP/1.1 200 OK"})
 	f2.SaveAs("./端口扫描.xlsx")
 	f2.Close()
 
@@ -51,5 +53,118 @@ func TestReloadResultFromReader(t *testing.T) {
 	assert.NoError(err)
 
 	assert.Len(r.HostDiscoveryResult.Items, 1)
-	assert.Len(r.PortScanningResult.Items, 2)
+	assert.Len(r.PortScanningResult.Items, 4)
+	assert.Equal("tcp", r.PortScanningResult.Items[0].Protocol)
+	assert.Equal("udp", r.PortScanningResult.Items[2].Protocol)
+	assert.Equal(161, r.PortScanningResult.Items[2].Port)
+	assert.Equal("http", r.PortScanningResult.Items[3].Service)
+	assert.Equal("nginx", r.PortScanningResult.Items[3].Product)
+	assert.Equal("1.18.0", r.PortScanningResult.Items[3].Version)
+	assert.True(r.PortScanningResult.Items[3].TLS)
+}
+
+func TestReloadDNSResolution(t *testing.T) {
+	defer os.Remove("./域名解析.csv")
+
+	assert := assert.New(t)
+
+	csvFile, err := os.Create("./域名解析.csv")
+	assert.NoError(err)
+	w := csv.NewWriter(csvFile)
+	w.Write([]string{"域名", "IP"})
+	w.Write([]string{"example.com", "1.1.1.1"})
+	w.Write([]string{"example.com", "2001:db8::1"})
+	w.Write([]string{"unknown.example.com", ""})
+	w.Flush()
+	csvFile.Close()
+
+	dr, err := os.Open("./域名解析.csv")
+	assert.NoError(err)
+	defer dr.Close()
+
+	r, err := ReloadResult(&types.ResultReader{
+		Format: "csv",
+		Stage:  types.StageDNSResolution,
+		Reader: dr,
+	})
+	assert.NoError(err)
+
+	assert.Len(r.DNSResolutionResult.Items, 2)
+	assert.Equal([]string{"1.1.1.1", "2001:db8::1"}, r.DNSResolutionResult.Items[0].IPs)
+	assert.True(r.DNSResolutionResult.Items[0].Resolved)
+	assert.False(r.DNSResolutionResult.Items[1].Resolved)
+}
+
+func TestReloadHostDiscoveryMethod(t *testing.T) {
+	defer os.Remove("./在线检测.csv")
+
+	assert := assert.New(t)
+
+	csvFile, err := os.Create("./在线检测.csv")
+	assert.NoError(err)
+	f := csv.NewWriter(csvFile)
+	f.Write([]string{"主机", "存活", "系统", "TTL", "方式", "初始TTL", "最小延迟(ms)", "平均延迟(ms)", "丢包率(%)"})
+	f.Write([]string{"1.1.1.1", "是", "linux", "62", "icmp", "64", "1.20", "1.50", "50.00"})
+	f.Write([]string{"1.1.1.2", "是", "", "", "tcp", "", "", "", ""})
+	f.Write([]string{"1.1.1.3", "否", "", "", "", "", "", "", ""})
+	f.Flush()
+	csvFile.Close()
+
+	hd, err := os.Open("./在线检测.csv")
+	assert.NoError(err)
+	defer hd.Close()
+
+	r, err := ReloadResult(&types.ResultReader{
+		Format: "csv",
+		Stage:  types.StageHostDiscovery,
+		Reader: hd,
+	})
+	assert.NoError(err)
+
+	assert.Len(r.HostDiscoveryResult.Items, 3)
+	assert.Equal("icmp", r.HostDiscoveryResult.Items[0].Method)
+	assert.Equal(62, r.HostDiscoveryResult.Items[0].TTL)
+	assert.Equal(64, r.HostDiscoveryResult.Items[0].InitialTTL)
+	assert.Equal(1.2, r.HostDiscoveryResult.Items[0].MinRTT)
+	assert.Equal(1.5, r.HostDiscoveryResult.Items[0].AvgRTT)
+	assert.Equal(50.0, r.HostDiscoveryResult.Items[0].Loss)
+	assert.Equal("tcp", r.HostDiscoveryResult.Items[1].Method)
+	assert.True(r.HostDiscoveryResult.Items[1].Active)
+	assert.False(r.HostDiscoveryResult.Items[2].Active)
+}
+
+func TestReloadCertificate(t *testing.T) {
+	defer os.Remove("./证书采集.csv")
+
+	assert := assert.New(t)
+
+	csvFile, err := os.Create("./证书采集.csv")
+	assert.NoError(err)
+	w := csv.NewWriter(csvFile)
+	w.Write([]string{"主机", "端口", "SNI", "主题", "备用名称", "颁发者", "生效时间", "过期时间", "已过期", "密钥算法", "密钥长度", "弱密钥", "签名算法", "弱签名", "自签名", "TLS版本", "SHA256指纹"})
+	w.Write([]string{"1.1.1.1", "443", "example.com", "CN=example.com", "example.com,www.example.com", "CN=R3,O=Let's Encrypt,C=US", "2024-01-01 00:00:00", "2024-04-01 00:00:00", "是", "RSA", "1024", "是", "SHA1-RSA", "是", "否", "TLS 1.2", "abcd"})
+	w.Flush()
+	csvFile.Close()
+
+	f, err := os.Open("./证书采集.csv")
+	assert.NoError(err)
+	defer f.Close()
+
+	r, err := ReloadResult(&types.ResultReader{
+		Format: "csv",
+		Stage:  types.StageCertificate,
+		Reader: f,
+	})
+	assert.NoError(err)
+
+	assert.Len(r.CertificateResult.Items, 1)
+	item := r.CertificateResult.Items[0]
+	assert.Equal("1.1.1.1:443", item.HostPort)
+	assert.Equal([]string{"example.com", "www.example.com"}, item.SANs)
+	assert.Equal(2024, item.NotAfter.Year())
+	assert.True(item.Expired)
+	assert.Equal(1024, item.KeySize)
+	assert.True(item.WeakKey)
+	assert.True(item.WeakSignature)
+	assert.False(item.SelfSigned)
 }